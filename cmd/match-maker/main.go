@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"log"
 	"net/http"
+	"os"
 	"os/signal"
 	"syscall"
 	"time"
@@ -13,13 +14,60 @@ import (
 	"github.com/TanyEm/match-maker/v2/internal/apiserver"
 	"github.com/TanyEm/match-maker/v2/internal/lobby"
 	"github.com/TanyEm/match-maker/v2/internal/match"
+	"github.com/TanyEm/match-maker/v2/internal/rating"
+	"github.com/TanyEm/match-maker/v2/internal/region"
 	"github.com/caarlos0/env/v10"
 )
 
+// ServiceConfig is parsed from the environment once at startup. A reload (SIGHUP or POST /admin/reload)
+// reads only QueuesFile and RulesFile again, as the environment of a running process does not change,
+// so the other settings, e.g. the defaults of the queues, need a restart.
 type ServiceConfig struct {
-	Port             int           `env:"PORT" envDefault:"8080"`
+	Port int `env:"PORT" envDefault:"8080"`
+	// ShutdownDuration is the longest time the service drains the lobbies and the requests before it exits
 	ShutdownDuration time.Duration `env:"SHUTDOWN_DURATION" envDefault:"3s"`
 	MatchMakingTime  time.Duration `env:"MATCH_MAKING_TIME" envDefault:"30s"`
+	MinMatchSize     int           `env:"MIN_MATCH_SIZE" envDefault:"2"`
+	MaxMatchSize     int           `env:"MAX_MATCH_SIZE" envDefault:"10"`
+	// TeamCount is the number of balanced teams of MaxMatchSize / TeamCount players, 0 for no teams
+	TeamCount int `env:"TEAM_COUNT" envDefault:"0"`
+	// PartyLevel is "average" or "max" level of the party members a party is matched by
+	PartyLevel lobby.PartyLevelMode `env:"PARTY_LEVEL" envDefault:"average"`
+	// DuplicateJoin is "reject" or "replace" the ticket of a player who joins the lobby again while waiting
+	DuplicateJoin lobby.DuplicatePolicy `env:"DUPLICATE_JOIN" envDefault:"reject"`
+	// TicketTTL is how long a resolved lobby ticket is kept for GET /match and GET /lobby/{join_id}, 0 keeps it forever
+	TicketTTL time.Duration `env:"TICKET_TTL" envDefault:"10m"`
+	// MaxWait is the longest time a player of a too thin match is carried over into the next rounds, 0 for one round
+	MaxWait time.Duration `env:"MAX_WAIT" envDefault:"0s"`
+	// ReadyCheck is the time the players of a full match have to accept it, 0 to start matches right away
+	ReadyCheck time.Duration `env:"READY_CHECK" envDefault:"0s"`
+	// DeclinePenalty is the time a player who has declined or missed a ready check can not join the lobby
+	DeclinePenalty time.Duration `env:"DECLINE_PENALTY" envDefault:"0s"`
+	// LevelWidening is a comma separated list of <duration>:<band> steps, e.g. "10s:3,20s:any"
+	LevelWidening []lobby.WideningStep `env:"LEVEL_WIDENING"`
+	// RegionFallback is one of "country", "neighbour", "subregion", "continent" or "global"
+	RegionFallback region.Proximity `env:"REGION_FALLBACK" envDefault:"country"`
+	// CountryNeighbours is a comma separated list of <country>:<country>|<country> entries, e.g. "FIN:SWE|EST"
+	CountryNeighbours region.Neighbours `env:"COUNTRY_NEIGHBOURS"`
+	// RatingSystem is one of "off", "elo" or "glicko2"
+	RatingSystem    string  `env:"RATING_SYSTEM" envDefault:"off"`
+	RatingBandWidth float64 `env:"RATING_BAND_WIDTH" envDefault:"100"`
+	EloK            float64 `env:"ELO_K" envDefault:"32"`
+	Glicko2Tau      float64 `env:"GLICKO2_TAU" envDefault:"0.5"`
+	// QueuesFile is a JSON file with the settings of the queues by their names, see QueueConfig.
+	// A single DefaultQueue configured by the environment is served if it is empty.
+	QueuesFile   string `env:"QUEUES_FILE"`
+	DefaultQueue string `env:"DEFAULT_QUEUE" envDefault:"default"`
+	// RulesFile is a YAML or JSON file with the matchmaking rules of the queues, see rules.Rules.
+	// The rules replace the strategy, the level widening and the region fallback of the environment.
+	RulesFile string `env:"RULES_FILE"`
+	// AdminToken is the bearer token of the admin endpoints, e.g. POST /admin/reload. No admin endpoints if it is empty.
+	AdminToken string `env:"ADMIN_TOKEN"`
+	// GameServerToken is the bearer token the game servers report the scores and the results, backfill and end the matches with.
+	// No score and result reporting, no backfills and no match ending if it is empty.
+	GameServerToken string `env:"GAME_SERVER_TOKEN"`
+	// PendingFile is a JSON file the tickets still waiting when the service exits are written to
+	PendingFile string `env:"PENDING_FILE"`
 }
 
 func main() {
@@ -48,12 +96,51 @@ func run(ctx context.Context, cfg *ServiceConfig) error {
 
 	matchStorage := match.NewStorage()
 
-	lobby := lobby.NewLobby(cfg.MatchMakingTime, matchStorage)
+	ratings, err := newRatings(cfg)
+	if err != nil {
+		return err
+	}
+
+	queues, err := newQueues(cfg, matchStorage, ratings != nil)
+	if err != nil {
+		return err
+	}
+
 	go func() {
-		lobby.Run()
+		queues.Run()
 	}()
 
-	apiServer := apiserver.NewAPIServer(lobby, matchStorage)
+	reloadConfig := func() error {
+		return reloadQueues(queues, cfg, matchStorage, ratings != nil)
+	}
+
+	hupCh := make(chan os.Signal, 1)
+	signal.Notify(hupCh, syscall.SIGHUP)
+	defer signal.Stop(hupCh)
+
+	go func() {
+		for {
+			select {
+			case <-hupCh:
+				log.Printf("SIGHUP received, reloading the config...")
+				if err := reloadConfig(); err != nil {
+					log.Printf("Failed to reload the config: %v", err)
+					continue
+				}
+				log.Printf("Config is reloaded")
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	apiServer := apiserver.NewAPIServer(queues, matchStorage, ratings)
+	if cfg.AdminToken != "" {
+		apiServer.AddAdmin(cfg.AdminToken, reloadConfig)
+	}
+	if cfg.GameServerToken != "" {
+		apiServer.AddGameServer(cfg.GameServerToken)
+	}
 
 	srv := &http.Server{
 		Addr:         fmt.Sprintf(":%d", cfg.Port),
@@ -82,13 +169,52 @@ func run(ctx context.Context, cfg *ServiceConfig) error {
 		log.Printf("Shutting down server...")
 	}
 
-	// Graceful shutdown to ensure that all other goroutines have time to finish their work
-	log.Printf("Shutting down server in %s", cfg.ShutdownDuration.String())
-	time.Sleep(cfg.ShutdownDuration)
+	// Graceful shutdown: the lobbies stop accepting players and resolve the waiting matches,
+	// so the clients waiting for their matches get the results before the service exits
+	log.Printf("Draining the service within %s", cfg.ShutdownDuration.String())
+	shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), cfg.ShutdownDuration)
+	defer cancelShutdown()
 
-	// Shutting down the match-maker and then lobby
-	srv.Shutdown(ctx)
-	lobby.Stop()
+	queues.Drain()
+
+	// The tickets in the ready checks are resolved once their players answer
+	for len(queues.Pending()) > 0 && shutdownCtx.Err() == nil {
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	// Shutting down the match-maker once the long-polling clients have got their results, and then lobby
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		log.Printf("Failed to finish the requests in time: %v", err)
+	}
+
+	if pending := queues.Pending(); len(pending) > 0 {
+		log.Printf("%d ticket(s) are still waiting", len(pending))
+
+		if cfg.PendingFile != "" {
+			if err := savePending(cfg.PendingFile, pending); err != nil {
+				log.Printf("Failed to save the waiting tickets: %v", err)
+			}
+		}
+	}
+
+	queues.Stop()
 
 	return nil
 }
+
+// newRatings creates the rating storage, it is nil when the rating system is off
+func newRatings(cfg *ServiceConfig) (rating.Keeper, error) {
+	var system rating.System
+	switch cfg.RatingSystem {
+	case "off":
+		return nil, nil
+	case "elo":
+		system = rating.NewElo(cfg.EloK)
+	case "glicko2":
+		system = rating.NewGlicko2(cfg.Glicko2Tau)
+	default:
+		return nil, fmt.Errorf("unknown rating system %q, expected one of off, elo, glicko2", cfg.RatingSystem)
+	}
+
+	return rating.NewStorage(system), nil
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/TanyEm/match-maker/v2/internal/lobby"
+)
+
+// PendingTicket is a ticket still waiting for a match when the service exits, see PENDING_FILE
+type PendingTicket struct {
+	JoinID   string            `json:"join_id"`
+	PlayerID string            `json:"player_id"`
+	Queue    string            `json:"queue,omitempty"`
+	State    lobby.TicketState `json:"status"`
+	JoinedAt time.Time         `json:"joined_at"`
+}
+
+// savePending writes the waiting tickets to the file, so that the players can be queued again
+func savePending(path string, tickets []lobby.Ticket) error {
+	pending := make([]PendingTicket, 0, len(tickets))
+	for _, ticket := range tickets {
+		pending = append(pending, PendingTicket{
+			JoinID:   ticket.JoinID,
+			PlayerID: ticket.PlayerID,
+			Queue:    ticket.Queue,
+			State:    ticket.State,
+			JoinedAt: ticket.JoinedAt,
+		})
+	}
+
+	data, err := json.MarshalIndent(pending, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write pending file: %w", err)
+	}
+
+	return nil
+}
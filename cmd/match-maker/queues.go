@@ -0,0 +1,194 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/TanyEm/match-maker/v2/internal/lobby"
+	"github.com/TanyEm/match-maker/v2/internal/match"
+	"github.com/TanyEm/match-maker/v2/internal/region"
+	"github.com/TanyEm/match-maker/v2/internal/rules"
+)
+
+// QueueConfig is the settings of a queue (game mode) in the QUEUES_FILE.
+// The settings missing in the file are taken from the environment.
+type QueueConfig struct {
+	MatchMakingTime Duration              `json:"match_making_time"`
+	MinMatchSize    int                   `json:"min_match_size"`
+	MaxMatchSize    int                   `json:"max_match_size"`
+	TeamCount       int                   `json:"team_count"`
+	PartyLevel      lobby.PartyLevelMode  `json:"party_level"`
+	LevelWidening   []lobby.WideningStep  `json:"level_widening"`
+	RegionFallback  region.Proximity      `json:"region_fallback"`
+	DuplicateJoin   lobby.DuplicatePolicy `json:"duplicate_join"`
+	TicketTTL       Duration              `json:"ticket_ttl"`
+	MaxWait         Duration              `json:"max_wait"`
+	ReadyCheck      Duration              `json:"ready_check"`
+	DeclinePenalty  Duration              `json:"decline_penalty"`
+	// Strategy is "level" or "rating", players are matched by rating by default when the rating system is on
+	Strategy        string  `json:"strategy"`
+	RatingBandWidth float64 `json:"rating_band_width"`
+	// RulesFile is the matchmaking rules of the queue, it replaces the strategy, the level widening and the region fallback
+	RulesFile string `json:"rules_file"`
+}
+
+// Duration is a time.Duration written as a string, e.g. "30s"
+type Duration time.Duration
+
+func (d *Duration) UnmarshalText(text []byte) error {
+	parsed, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+
+	*d = Duration(parsed)
+
+	return nil
+}
+
+// newQueues creates a lobby for every queue of the QUEUES_FILE,
+// or a single default queue configured by the environment if there is no file
+func newQueues(cfg *ServiceConfig, matchKeeper match.Keeper, ratingsOn bool) (*lobby.Queues, error) {
+	lobbies, err := newLobbies(cfg, matchKeeper, ratingsOn)
+	if err != nil {
+		return nil, err
+	}
+
+	return lobby.NewQueues(cfg.DefaultQueue, lobbies)
+}
+
+// reloadQueues reads the queues and rules files again and applies the configs of the queues
+// to the running queues, the queued tickets are kept. The settings of the environment are the ones
+// the service started with, see ServiceConfig.
+func reloadQueues(queues *lobby.Queues, cfg *ServiceConfig, matchKeeper match.Keeper, ratingsOn bool) error {
+	lobbies, err := newLobbies(cfg, matchKeeper, ratingsOn)
+	if err != nil {
+		return err
+	}
+
+	return queues.Reconfigure(cfg.DefaultQueue, lobbies)
+}
+
+// newLobbies creates the lobbies of the queues by their names
+func newLobbies(cfg *ServiceConfig, matchKeeper match.Keeper, ratingsOn bool) (map[string]*lobby.Lobby, error) {
+	defaults := QueueConfig{
+		MatchMakingTime: Duration(cfg.MatchMakingTime),
+		MinMatchSize:    cfg.MinMatchSize,
+		MaxMatchSize:    cfg.MaxMatchSize,
+		TeamCount:       cfg.TeamCount,
+		PartyLevel:      cfg.PartyLevel,
+		LevelWidening:   cfg.LevelWidening,
+		RegionFallback:  cfg.RegionFallback,
+		DuplicateJoin:   cfg.DuplicateJoin,
+		TicketTTL:       Duration(cfg.TicketTTL),
+		MaxWait:         Duration(cfg.MaxWait),
+		ReadyCheck:      Duration(cfg.ReadyCheck),
+		DeclinePenalty:  Duration(cfg.DeclinePenalty),
+		Strategy:        "level",
+		RatingBandWidth: cfg.RatingBandWidth,
+		RulesFile:       cfg.RulesFile,
+	}
+	if ratingsOn {
+		defaults.Strategy = "rating"
+	}
+
+	queueConfigs := map[string]QueueConfig{cfg.DefaultQueue: defaults}
+	if cfg.QueuesFile != "" {
+		data, err := os.ReadFile(cfg.QueuesFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read queues file: %w", err)
+		}
+
+		raw := map[string]json.RawMessage{}
+		if err := json.Unmarshal(data, &raw); err != nil {
+			return nil, fmt.Errorf("failed to parse queues file %s: %w", cfg.QueuesFile, err)
+		}
+
+		queueConfigs = make(map[string]QueueConfig, len(raw))
+		for name, settings := range raw {
+			queueConfig := defaults
+			if err := json.Unmarshal(settings, &queueConfig); err != nil {
+				return nil, fmt.Errorf("failed to parse queue %q: %w", name, err)
+			}
+			queueConfigs[name] = queueConfig
+		}
+	}
+
+	regions := region.NewMap(cfg.CountryNeighbours)
+	lobbies := make(map[string]*lobby.Lobby, len(queueConfigs))
+	for name, queueConfig := range queueConfigs {
+		lobbyConfig := lobby.Config{
+			Queue:          name,
+			WaitingTime:    time.Duration(queueConfig.MatchMakingTime),
+			MinMatchSize:   queueConfig.MinMatchSize,
+			MaxMatchSize:   queueConfig.MaxMatchSize,
+			LevelWidening:  queueConfig.LevelWidening,
+			RegionFallback: queueConfig.RegionFallback,
+			Regions:        regions,
+			TeamCount:      queueConfig.TeamCount,
+			PartyLevel:     queueConfig.PartyLevel,
+			Duplicates:     queueConfig.DuplicateJoin,
+			TicketTTL:      time.Duration(queueConfig.TicketTTL),
+			MaxWait:        time.Duration(queueConfig.MaxWait),
+			ReadyCheck:     time.Duration(queueConfig.ReadyCheck),
+			DeclinePenalty: time.Duration(queueConfig.DeclinePenalty),
+		}
+
+		var strategy lobby.MatchmakingStrategy
+		var err error
+		if queueConfig.RulesFile != "" {
+			strategy, err = applyRules(queueConfig.RulesFile, &lobbyConfig, ratingsOn)
+		} else {
+			strategy, err = newStrategy(queueConfig, ratingsOn)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid config of queue %q: %w", name, err)
+		}
+
+		if err := lobbyConfig.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid config of queue %q: %w", name, err)
+		}
+
+		lobbies[name] = lobby.NewLobby(lobbyConfig, matchKeeper, strategy)
+	}
+
+	return lobbies, nil
+}
+
+// applyRules loads the matchmaking rules file into the lobby config and returns the strategy of the rules
+func applyRules(path string, lobbyConfig *lobby.Config, ratingsOn bool) (lobby.MatchmakingStrategy, error) {
+	r, err := rules.Load(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.Attribute == lobby.AttributeRating && !ratingsOn {
+		return nil, fmt.Errorf("rules file %s: rating attribute requires the rating system, RATING_SYSTEM is off", path)
+	}
+
+	r.Apply(lobbyConfig)
+
+	return r.Strategy(), nil
+}
+
+// newStrategy creates the matchmaking strategy of a queue
+func newStrategy(queueConfig QueueConfig, ratingsOn bool) (lobby.MatchmakingStrategy, error) {
+	switch queueConfig.Strategy {
+	case "level":
+		return lobby.NewDefaultStrategy(), nil
+	case "rating":
+		if !ratingsOn {
+			return nil, fmt.Errorf("rating strategy requires the rating system, RATING_SYSTEM is off")
+		}
+
+		if queueConfig.RatingBandWidth <= 0 {
+			return nil, fmt.Errorf("rating band width must be positive, got %v", queueConfig.RatingBandWidth)
+		}
+
+		return lobby.NewRatingStrategy(queueConfig.RatingBandWidth), nil
+	default:
+		return nil, fmt.Errorf("unknown strategy %q, expected one of level, rating", queueConfig.Strategy)
+	}
+}
@@ -0,0 +1,218 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/TanyEm/match-maker/v2/internal/lobby"
+	"github.com/TanyEm/match-maker/v2/internal/match"
+	"github.com/caarlos0/env/v10"
+	"github.com/stretchr/testify/assert"
+)
+
+// defaultServiceConfig returns the config of an empty environment, so the tests do not depend on the environment they run in
+func defaultServiceConfig(t *testing.T) ServiceConfig {
+	cfg := ServiceConfig{}
+	assert.NoError(t, env.ParseWithOptions(&cfg, env.Options{Environment: map[string]string{}}))
+
+	return cfg
+}
+
+// writeFile writes the content into a file of the test directory and returns its path
+func writeFile(t *testing.T, name, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	assert.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+
+	return path
+}
+
+func TestNewLobbies(t *testing.T) {
+	type expectedQueue struct {
+		waitingTime  time.Duration
+		maxMatchSize int
+		strategy     *lobby.BucketStrategy
+	}
+
+	tests := []struct {
+		name           string
+		queues         string
+		rules          string
+		ratingsOn      bool
+		expectedError  string
+		expectedQueues map[string]expectedQueue
+	}{
+		{
+			name:           "default queue of the environment",
+			expectedQueues: map[string]expectedQueue{"default": {30 * time.Second, 10, lobby.NewDefaultStrategy()}},
+		},
+		{
+			name:           "default queue matched by rating",
+			ratingsOn:      true,
+			expectedQueues: map[string]expectedQueue{"default": {30 * time.Second, 10, lobby.NewRatingStrategy(100)}},
+		},
+		{
+			name:   "queues file",
+			queues: `{"casual": {"match_making_time": "10s"}, "ranked": {"max_match_size": 2, "strategy": "rating", "rating_band_width": 50}}`,
+			expectedQueues: map[string]expectedQueue{
+				"casual": {10 * time.Second, 10, lobby.NewRatingStrategy(100)},
+				"ranked": {30 * time.Second, 2, lobby.NewRatingStrategy(50)},
+			},
+			ratingsOn: true,
+		},
+		{
+			name:           "level strategy with the rating system on",
+			queues:         `{"casual": {"strategy": "level"}}`,
+			ratingsOn:      true,
+			expectedQueues: map[string]expectedQueue{"casual": {30 * time.Second, 10, lobby.NewDefaultStrategy()}},
+		},
+		{
+			name:          "invalid json",
+			queues:        `{"casual": `,
+			expectedError: "failed to parse queues file",
+		},
+		{
+			name:          "invalid duration",
+			queues:        `{"casual": {"match_making_time": "soon"}}`,
+			expectedError: `failed to parse queue "casual": time: invalid duration "soon"`,
+		},
+		{
+			name:          "unknown strategy",
+			queues:        `{"casual": {"strategy": "random"}}`,
+			expectedError: `invalid config of queue "casual": unknown strategy "random", expected one of level, rating`,
+		},
+		{
+			name:          "rating strategy with the rating system off",
+			queues:        `{"ranked": {"strategy": "rating"}}`,
+			expectedError: `invalid config of queue "ranked": rating strategy requires the rating system, RATING_SYSTEM is off`,
+		},
+		{
+			name:          "zero rating band width",
+			queues:        `{"ranked": {"strategy": "rating", "rating_band_width": 0}}`,
+			ratingsOn:     true,
+			expectedError: `invalid config of queue "ranked": rating band width must be positive, got 0`,
+		},
+		{
+			name:          "unknown party level",
+			queues:        `{"casual": {"party_level": "min"}}`,
+			expectedError: `invalid config of queue "casual": unknown party level "min", expected "average" or "max"`,
+		},
+		{
+			name:          "unknown duplicate policy",
+			queues:        `{"casual": {"duplicate_join": "merge"}}`,
+			expectedError: `invalid config of queue "casual": unknown duplicate policy "merge", expected "reject" or "replace"`,
+		},
+		{
+			name:          "unknown region fallback",
+			queues:        `{"casual": {"region_fallback": "planet"}}`,
+			expectedError: `failed to parse queue "casual": unknown proximity "planet", expected one of country, neighbour, subregion, continent, global`,
+		},
+		{
+			name:          "min match size above max match size",
+			queues:        `{"casual": {"min_match_size": 4, "max_match_size": 2}}`,
+			expectedError: `invalid config of queue "casual": max match size 2 is less than min match size 4`,
+		},
+		{
+			name:          "rating rules with the rating system off",
+			rules:         "attribute: rating",
+			expectedError: "rating attribute requires the rating system, RATING_SYSTEM is off",
+		},
+		{
+			name:           "rules file",
+			rules:          "attribute: rating\nbucket_width: 25\nfloor: null",
+			ratingsOn:      true,
+			expectedQueues: map[string]expectedQueue{"default": {30 * time.Second, 10, lobby.NewRatingStrategy(25)}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := defaultServiceConfig(t)
+			if tt.queues != "" {
+				cfg.QueuesFile = writeFile(t, "queues.json", tt.queues)
+			}
+			if tt.rules != "" {
+				cfg.RulesFile = writeFile(t, "rules.yaml", tt.rules)
+			}
+
+			lobbies, err := newLobbies(&cfg, match.NewStorage(), tt.ratingsOn)
+			if tt.expectedError != "" {
+				assert.ErrorContains(t, err, tt.expectedError)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Len(t, lobbies, len(tt.expectedQueues))
+			for name, expected := range tt.expectedQueues {
+				l, ok := lobbies[name]
+				if !assert.True(t, ok, "Expected queue %q", name) {
+					continue
+				}
+
+				assert.Equal(t, name, l.Config.Queue)
+				assert.Equal(t, expected.waitingTime, l.Config.WaitingTime)
+				assert.Equal(t, expected.maxMatchSize, l.Config.MaxMatchSize)
+				assert.Equal(t, expected.strategy, l.Strategy)
+			}
+		})
+	}
+}
+
+func TestNewLobbies_MissingFiles(t *testing.T) {
+	cfg := defaultServiceConfig(t)
+	cfg.QueuesFile = filepath.Join(t.TempDir(), "missing.json")
+	_, err := newLobbies(&cfg, match.NewStorage(), false)
+	assert.ErrorContains(t, err, "failed to read queues file")
+
+	cfg = defaultServiceConfig(t)
+	cfg.RulesFile = filepath.Join(t.TempDir(), "missing.yaml")
+	_, err = newLobbies(&cfg, match.NewStorage(), false)
+	assert.ErrorContains(t, err, `invalid config of queue "default": failed to read rules file`)
+}
+
+func TestNewQueues_DefaultQueue(t *testing.T) {
+	cfg := defaultServiceConfig(t)
+	cfg.QueuesFile = writeFile(t, "queues.json", `{"casual": {}, "ranked": {"max_match_size": 2}}`)
+
+	// The default queue must be one of the queues of the file
+	_, err := newQueues(&cfg, match.NewStorage(), false)
+	assert.ErrorIs(t, err, lobby.ErrUnknownQueue)
+
+	cfg.DefaultQueue = "ranked"
+	queues, err := newQueues(&cfg, match.NewStorage(), false)
+	assert.NoError(t, err)
+
+	l, err := queues.Lobby("")
+	assert.NoError(t, err)
+	assert.Equal(t, "ranked", l.Config.Queue)
+	assert.Equal(t, 2, l.Config.MaxMatchSize)
+}
+
+func TestNewRatings(t *testing.T) {
+	tests := []struct {
+		ratingSystem  string
+		expectedNil   bool
+		expectedError string
+	}{
+		{ratingSystem: "off", expectedNil: true},
+		{ratingSystem: "elo"},
+		{ratingSystem: "glicko2"},
+		{ratingSystem: "trueskill", expectedNil: true, expectedError: `unknown rating system "trueskill", expected one of off, elo, glicko2`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ratingSystem, func(t *testing.T) {
+			cfg := defaultServiceConfig(t)
+			cfg.RatingSystem = tt.ratingSystem
+
+			ratings, err := newRatings(&cfg)
+			if tt.expectedError != "" {
+				assert.EqualError(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.expectedNil, ratings == nil)
+		})
+	}
+}
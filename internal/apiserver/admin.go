@@ -0,0 +1,23 @@
+package apiserver
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AddAdmin registers the admin endpoints behind the bearer token.
+// Reload reloads the configuration of the running service.
+func (s *APIServer) AddAdmin(token string, reload func() error) {
+	admin := s.GinEngine.Group("/admin", bearerAuth(token))
+	admin.POST("/reload", func(ctx *gin.Context) {
+		if err := reload(); err != nil {
+			log.Printf("Failed to reload the config: %v", err)
+			ctx.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, gin.H{"message": "the config is reloaded"})
+	})
+}
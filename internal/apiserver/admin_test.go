@@ -0,0 +1,102 @@
+package apiserver
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/TanyEm/match-maker/v2/internal/lobby"
+	"github.com/TanyEm/match-maker/v2/internal/match"
+	"github.com/TanyEm/match-maker/v2/internal/rating"
+	"go.uber.org/mock/gomock"
+)
+
+func TestReload(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	srv := NewAPIServer(lobby.NewMockLobbier(ctrl), match.NewMockKeeper(ctrl), rating.NewMockKeeper(ctrl))
+
+	var reloadErr error
+	reloads := 0
+	srv.AddAdmin("secret", func() error {
+		reloads++
+		return reloadErr
+	})
+
+	tests := []struct {
+		name                string
+		authorization       string
+		reloadErr           error
+		expectedCode        int
+		expectedContentType string
+		expectedBody        string
+		expectedReloads     int
+	}{
+		{
+			name:                "reloaded",
+			authorization:       "Bearer secret",
+			expectedCode:        200,
+			expectedContentType: "application/json; charset=utf-8",
+			expectedBody:        `{"message":"the config is reloaded"}`,
+			expectedReloads:     1,
+		},
+		{
+			name:                "invalid config",
+			authorization:       "Bearer secret",
+			reloadErr:           errors.New(`queue "default": waiting time must be positive, got 0s`),
+			expectedCode:        422,
+			expectedContentType: "application/json; charset=utf-8",
+			expectedBody:        `{"error":"queue \"default\": waiting time must be positive, got 0s"}`,
+			expectedReloads:     1,
+		},
+		{
+			name:                "wrong token",
+			authorization:       "Bearer wrong",
+			expectedCode:        401,
+			expectedContentType: "application/json; charset=utf-8",
+			expectedBody:        `{"error":"the bearer token is missing or wrong"}`,
+		},
+		{
+			name:                "no token",
+			expectedCode:        401,
+			expectedContentType: "application/json; charset=utf-8",
+			expectedBody:        `{"error":"the bearer token is missing or wrong"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reloadErr, reloads = tt.reloadErr, 0
+			recorder := httptest.NewRecorder()
+
+			req, err := http.NewRequest(http.MethodPost, "/admin/reload", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+
+			srv.GinEngine.ServeHTTP(recorder, req)
+
+			if recorder.Code != tt.expectedCode {
+				t.Errorf("expected code %d, got %d", tt.expectedCode, recorder.Code)
+			}
+
+			if recorder.Header().Get("Content-Type") != tt.expectedContentType {
+				t.Errorf("expected content type %s, got %s", tt.expectedContentType, recorder.Header().Get("Content-Type"))
+			}
+
+			if recorder.Body.String() != tt.expectedBody {
+				t.Errorf("expected body '%s', got '%s'", tt.expectedBody, recorder.Body.String())
+			}
+
+			if reloads != tt.expectedReloads {
+				t.Errorf("expected %d reloads, got %d", tt.expectedReloads, reloads)
+			}
+		})
+	}
+}
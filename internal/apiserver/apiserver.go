@@ -5,6 +5,7 @@ import (
 
 	"github.com/TanyEm/match-maker/v2/internal/lobby"
 	"github.com/TanyEm/match-maker/v2/internal/match"
+	"github.com/TanyEm/match-maker/v2/internal/rating"
 	"github.com/gin-gonic/gin"
 	"github.com/gin-gonic/gin/binding"
 	"github.com/go-playground/validator/v10"
@@ -14,12 +15,15 @@ type APIServer struct {
 	GinEngine   *gin.Engine
 	Lobby       lobby.Lobbier
 	MatchKeeper match.Keeper
+	// Ratings is nil when the rating system is turned off
+	Ratings rating.Keeper
 }
 
-func NewAPIServer(lobby lobby.Lobbier, matchKeeper match.Keeper) *APIServer {
+func NewAPIServer(lobby lobby.Lobbier, matchKeeper match.Keeper, ratings rating.Keeper) *APIServer {
 	apiServer := &APIServer{
 		Lobby:       lobby,
 		MatchKeeper: matchKeeper,
+		Ratings:     ratings,
 	}
 
 	r := gin.Default()
@@ -35,10 +39,40 @@ func NewAPIServer(lobby lobby.Lobbier, matchKeeper match.Keeper) *APIServer {
 		})
 	})
 	r.POST("/lobby", apiServer.JoinLobby)
+	r.POST("/lobby/party", apiServer.JoinParty)
+	r.GET("/lobby/:join_id", apiServer.GetTicket)
+	r.DELETE("/lobby/:join_id", apiServer.LeaveLobby)
+	r.POST("/lobby/:join_id/ready", apiServer.Ready)
 	r.GET("/match", apiServer.JoinMatch)
 	r.GET("/leaderboard", apiServer.GetLeaderBoard)
+	r.GET("/leaderboards/global", apiServer.GetGlobalLeaderBoard)
+	r.GET("/matches/:match_id", apiServer.GetMatch)
+	r.GET("/players/:player_id/matches", apiServer.GetPlayerMatches)
+	r.GET("/debug/vars", apiServer.GetMetrics)
+
+	if ratings != nil {
+		r.GET("/rating", apiServer.GetRating)
+	}
 
 	apiServer.GinEngine = r
 
 	return apiServer
 }
+
+// AddGameServer registers all the endpoints the game servers change the matches with
+// behind the bearer token: the scores, the ends and the backfills of the matches,
+// and the match results when the rating system is on
+func (s *APIServer) AddGameServer(token string) {
+	gameServer := s.GinEngine.Group("/", bearerAuth(token))
+
+	matches := gameServer.Group("/match/:match_id")
+	matches.POST("/scores", s.ReportScores)
+	matches.POST("/finish", s.FinishMatch)
+	matches.POST("/abandon", s.AbandonMatch)
+	matches.POST("/backfill", s.OpenBackfill)
+	matches.DELETE("/backfill", s.CloseBackfill)
+
+	if s.Ratings != nil {
+		gameServer.POST("/results", s.ReportResult)
+	}
+}
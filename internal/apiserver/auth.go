@@ -0,0 +1,22 @@
+package apiserver
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// bearerAuth rejects the requests without the "Authorization: Bearer <token>" header
+func bearerAuth(token string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		got, found := strings.CutPrefix(ctx.GetHeader("Authorization"), "Bearer ")
+		if !found || subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "the bearer token is missing or wrong"})
+			return
+		}
+
+		ctx.Next()
+	}
+}
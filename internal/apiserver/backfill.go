@@ -0,0 +1,101 @@
+package apiserver
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/TanyEm/match-maker/v2/internal/lobby"
+	"github.com/TanyEm/match-maker/v2/internal/match"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// BackfillRequest is a request of a game server for players to replace the players who dropped from a started match.
+// The players must come from one of the countries and have a level between min_level and max_level if they are set.
+type BackfillRequest struct {
+	Slots     int      `json:"slots" binding:"required,min=1"`
+	Countries []string `json:"countries" binding:"omitempty,dive,isocountry"`
+	MinLevel  int      `json:"min_level" binding:"omitempty,min=1,max=99"`
+	MaxLevel  int      `json:"max_level" binding:"omitempty,min=1,max=99,gtefield=MinLevel"`
+	// Team is the team the players join, 0 if the match has no teams
+	Team int `json:"team" binding:"min=0"`
+}
+
+type BackfillResponse struct {
+	MatchID   string `json:"match_id"`
+	OpenSlots int    `json:"open_slots"`
+}
+
+// OpenBackfill opens the slots of a started match to be filled from the lobby
+func (s *APIServer) OpenBackfill(ctx *gin.Context) {
+	matchID := ctx.Param("match_id")
+	if _, err := uuid.Parse(matchID); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "match_id is not valid UUID"})
+		return
+	}
+
+	var req BackfillRequest
+	if err := ctx.BindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	leaderBoard := s.MatchKeeper.GetLeaderBoard(matchID)
+	if leaderBoard == nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "match not found"})
+		return
+	}
+
+	if req.Team > len(leaderBoard.Teams) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("match has %d team(s), got team %d", len(leaderBoard.Teams), req.Team)})
+		return
+	}
+
+	openSlots, err := s.Lobby.AddBackfill(lobby.Backfill{
+		MatchID:   matchID,
+		Queue:     leaderBoard.Queue,
+		Slots:     req.Slots,
+		Countries: req.Countries,
+		MinLevel:  req.MinLevel,
+		MaxLevel:  req.MaxLevel,
+		Team:      req.Team,
+	})
+	if errors.Is(err, match.ErrMatchNotFound) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "match not found"})
+		return
+	}
+
+	if errors.Is(err, match.ErrMatchEnded) {
+		ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+
+	if errors.Is(err, lobby.ErrDraining) {
+		ctx.JSON(http.StatusServiceUnavailable, gin.H{"error": "the lobby is shutting down, try again later"})
+		return
+	}
+
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, BackfillResponse{MatchID: matchID, OpenSlots: openSlots})
+}
+
+// CloseBackfill closes the open slots of a match
+func (s *APIServer) CloseBackfill(ctx *gin.Context) {
+	matchID := ctx.Param("match_id")
+	if _, err := uuid.Parse(matchID); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "match_id is not valid UUID"})
+		return
+	}
+
+	if err := s.Lobby.RemoveBackfill(matchID); err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "the match has no open slots"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, BackfillResponse{MatchID: matchID, OpenSlots: 0})
+}
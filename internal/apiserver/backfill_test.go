@@ -0,0 +1,253 @@
+package apiserver
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/TanyEm/match-maker/v2/internal/lobby"
+	"github.com/TanyEm/match-maker/v2/internal/match"
+	"github.com/TanyEm/match-maker/v2/internal/rating"
+	"github.com/google/go-cmp/cmp"
+	"go.uber.org/mock/gomock"
+)
+
+func TestBackfill(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	srv := NewAPIServer(lobby.NewMockLobbier(ctrl), match.NewMockKeeper(ctrl), rating.NewMockKeeper(ctrl))
+	srv.AddGameServer("secret")
+
+	const matchID = "72b33e85-e8cd-45e6-89f4-25bfdac584d8"
+	leaderBoard := &match.LeaderBoard{
+		MatchID: matchID,
+		Queue:   "ranked",
+		Teams:   []match.Team{{Team: 1}, {Team: 2}},
+	}
+
+	tests := []struct {
+		name                string
+		method              string
+		reqURL              string
+		req                 []byte
+		authorization       string
+		expectedError       bool
+		expectedCode        int
+		expectedContentType string
+		expectedBody        string
+		expectedMockCalls   func()
+	}{
+		{
+			name:                "valid request",
+			method:              http.MethodPost,
+			reqURL:              "/match/" + matchID + "/backfill",
+			req:                 []byte(`{"slots": 2, "countries": ["FIN", "SWE"], "min_level": 5, "max_level": 10, "team": 2}`),
+			authorization:       "Bearer secret",
+			expectedError:       false,
+			expectedCode:        200,
+			expectedContentType: "application/json; charset=utf-8",
+			expectedBody:        `{"match_id":"72b33e85-e8cd-45e6-89f4-25bfdac584d8","open_slots":1}`,
+			expectedMockCalls: func() {
+				srv.MatchKeeper.(*match.MockKeeper).EXPECT().GetLeaderBoard(matchID).Times(1).Return(leaderBoard)
+				srv.Lobby.(*lobby.MockLobbier).EXPECT().
+					AddBackfill(lobby.Backfill{
+						MatchID:   matchID,
+						Queue:     "ranked",
+						Slots:     2,
+						Countries: []string{"FIN", "SWE"},
+						MinLevel:  5,
+						MaxLevel:  10,
+						Team:      2,
+					}).
+					Times(1).
+					Return(1, nil)
+			},
+		},
+		{
+			name:                "match not found",
+			method:              http.MethodPost,
+			reqURL:              "/match/" + matchID + "/backfill",
+			req:                 []byte(`{"slots": 2}`),
+			authorization:       "Bearer secret",
+			expectedError:       true,
+			expectedCode:        404,
+			expectedContentType: "application/json; charset=utf-8",
+			expectedBody:        `{"error":"match not found"}`,
+			expectedMockCalls: func() {
+				srv.MatchKeeper.(*match.MockKeeper).EXPECT().GetLeaderBoard(matchID).Times(1).Return(nil)
+			},
+		},
+		{
+			name:                "match is already ended",
+			method:              http.MethodPost,
+			reqURL:              "/match/" + matchID + "/backfill",
+			req:                 []byte(`{"slots": 2}`),
+			authorization:       "Bearer secret",
+			expectedError:       true,
+			expectedCode:        409,
+			expectedContentType: "application/json; charset=utf-8",
+			expectedBody:        `{"error":"match is already ended"}`,
+			expectedMockCalls: func() {
+				srv.MatchKeeper.(*match.MockKeeper).EXPECT().GetLeaderBoard(matchID).Times(1).Return(leaderBoard)
+				srv.Lobby.(*lobby.MockLobbier).EXPECT().
+					AddBackfill(gomock.Any()).
+					Times(1).
+					Return(0, match.ErrMatchEnded)
+			},
+		},
+		{
+			name:                "lobby is draining",
+			method:              http.MethodPost,
+			reqURL:              "/match/" + matchID + "/backfill",
+			req:                 []byte(`{"slots": 2}`),
+			authorization:       "Bearer secret",
+			expectedError:       true,
+			expectedCode:        503,
+			expectedContentType: "application/json; charset=utf-8",
+			expectedBody:        `{"error":"the lobby is shutting down, try again later"}`,
+			expectedMockCalls: func() {
+				srv.MatchKeeper.(*match.MockKeeper).EXPECT().GetLeaderBoard(matchID).Times(1).Return(leaderBoard)
+				srv.Lobby.(*lobby.MockLobbier).EXPECT().
+					AddBackfill(gomock.Any()).
+					Times(1).
+					Return(0, lobby.ErrDraining)
+			},
+		},
+		{
+			name:                "not valid request: unknown team",
+			method:              http.MethodPost,
+			reqURL:              "/match/" + matchID + "/backfill",
+			req:                 []byte(`{"slots": 2, "team": 3}`),
+			authorization:       "Bearer secret",
+			expectedError:       true,
+			expectedCode:        400,
+			expectedContentType: "application/json; charset=utf-8",
+			expectedBody:        `{"error":"match has 2 team(s), got team 3"}`,
+			expectedMockCalls: func() {
+				srv.MatchKeeper.(*match.MockKeeper).EXPECT().GetLeaderBoard(matchID).Times(1).Return(leaderBoard)
+			},
+		},
+		{
+			name:                "not valid request: no slots",
+			method:              http.MethodPost,
+			reqURL:              "/match/" + matchID + "/backfill",
+			req:                 []byte(`{"slots": 0}`),
+			authorization:       "Bearer secret",
+			expectedError:       true,
+			expectedCode:        400,
+			expectedContentType: "application/json; charset=utf-8",
+			expectedBody:        `{"error":"Key: 'BackfillRequest.Slots' Error:Field validation for 'Slots' failed on the 'required' tag"}`,
+			expectedMockCalls:   func() {},
+		},
+		{
+			name:                "not valid request: max_level is less than min_level",
+			method:              http.MethodPost,
+			reqURL:              "/match/" + matchID + "/backfill",
+			req:                 []byte(`{"slots": 1, "min_level": 10, "max_level": 5}`),
+			authorization:       "Bearer secret",
+			expectedError:       true,
+			expectedCode:        400,
+			expectedContentType: "application/json; charset=utf-8",
+			expectedBody:        `{"error":"Key: 'BackfillRequest.MaxLevel' Error:Field validation for 'MaxLevel' failed on the 'gtefield' tag"}`,
+			expectedMockCalls:   func() {},
+		},
+		{
+			name:                "not valid request: match_id is not valid UUID",
+			method:              http.MethodPost,
+			reqURL:              "/match/not-valid-uuid/backfill",
+			req:                 []byte(`{"slots": 1}`),
+			authorization:       "Bearer secret",
+			expectedError:       true,
+			expectedCode:        400,
+			expectedContentType: "application/json; charset=utf-8",
+			expectedBody:        `{"error":"match_id is not valid UUID"}`,
+			expectedMockCalls:   func() {},
+		},
+		{
+			name:                "close backfill",
+			method:              http.MethodDelete,
+			reqURL:              "/match/" + matchID + "/backfill",
+			authorization:       "Bearer secret",
+			expectedError:       false,
+			expectedCode:        200,
+			expectedContentType: "application/json; charset=utf-8",
+			expectedBody:        `{"match_id":"72b33e85-e8cd-45e6-89f4-25bfdac584d8","open_slots":0}`,
+			expectedMockCalls: func() {
+				srv.Lobby.(*lobby.MockLobbier).EXPECT().RemoveBackfill(matchID).Times(1).Return(nil)
+			},
+		},
+		{
+			name:                "close backfill: no open slots",
+			method:              http.MethodDelete,
+			reqURL:              "/match/" + matchID + "/backfill",
+			authorization:       "Bearer secret",
+			expectedError:       true,
+			expectedCode:        404,
+			expectedContentType: "application/json; charset=utf-8",
+			expectedBody:        `{"error":"the match has no open slots"}`,
+			expectedMockCalls: func() {
+				srv.Lobby.(*lobby.MockLobbier).EXPECT().RemoveBackfill(matchID).Times(1).Return(lobby.ErrBackfillNotFound)
+			},
+		},
+		{
+			name:                "no token",
+			method:              http.MethodDelete,
+			reqURL:              "/match/" + matchID + "/backfill",
+			expectedError:       true,
+			expectedCode:        401,
+			expectedContentType: "application/json; charset=utf-8",
+			expectedBody:        `{"error":"the bearer token is missing or wrong"}`,
+			expectedMockCalls:   func() {},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.expectedMockCalls()
+			recorder := httptest.NewRecorder()
+
+			req, err := http.NewRequest(tt.method, tt.reqURL, bytes.NewReader(tt.req))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			req.Header.Set("Content-Type", "application/json")
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+
+			srv.GinEngine.ServeHTTP(recorder, req)
+
+			if recorder.Code != tt.expectedCode {
+				t.Errorf("expected code %d, got %d", tt.expectedCode, recorder.Code)
+			}
+
+			if recorder.Header().Get("Content-Type") != tt.expectedContentType {
+				t.Errorf("expected content type %s, got %s", tt.expectedContentType, recorder.Header().Get("Content-Type"))
+			}
+
+			if !tt.expectedError {
+				var gotResponse BackfillResponse
+				if err := json.Unmarshal(recorder.Body.Bytes(), &gotResponse); err != nil {
+					t.Fatal(err)
+				}
+
+				var expectedResponse BackfillResponse
+				if err := json.Unmarshal([]byte(tt.expectedBody), &expectedResponse); err != nil {
+					t.Fatal(err)
+				}
+
+				if !cmp.Equal(gotResponse, expectedResponse) {
+					t.Errorf("expected response '%v', got '%v'", expectedResponse, gotResponse)
+				}
+			} else {
+				if recorder.Body.String() != tt.expectedBody {
+					t.Errorf("expected body '%s', got '%s'", tt.expectedBody, recorder.Body.String())
+				}
+			}
+		})
+	}
+}
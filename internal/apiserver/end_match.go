@@ -0,0 +1,50 @@
+package apiserver
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/TanyEm/match-maker/v2/internal/match"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// FinishMatch marks the match played to the end
+func (s *APIServer) FinishMatch(ctx *gin.Context) {
+	s.endMatch(ctx, match.StatusFinished)
+}
+
+// AbandonMatch marks the match stopped before the end
+func (s *APIServer) AbandonMatch(ctx *gin.Context) {
+	s.endMatch(ctx, match.StatusAbandoned)
+}
+
+// endMatch moves the match into the final status and closes its open backfill slots
+func (s *APIServer) endMatch(ctx *gin.Context, status match.Status) {
+	matchID := ctx.Param("match_id")
+	if _, err := uuid.Parse(matchID); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "match_id is not valid UUID"})
+		return
+	}
+
+	leaderBoard, err := s.MatchKeeper.EndMatch(matchID, status)
+	if errors.Is(err, match.ErrMatchNotFound) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "match not found"})
+		return
+	}
+
+	if errors.Is(err, match.ErrMatchEnded) {
+		ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	// The players can not join the ended match anymore, the match may have no open slots
+	s.Lobby.RemoveBackfill(matchID)
+
+	ctx.JSON(http.StatusOK, GetLeaderBoardResponse{LeaderBoard: leaderBoard.Ranked()})
+}
@@ -0,0 +1,199 @@
+package apiserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/TanyEm/match-maker/v2/internal/lobby"
+	"github.com/TanyEm/match-maker/v2/internal/match"
+	"github.com/TanyEm/match-maker/v2/internal/rating"
+	"github.com/google/go-cmp/cmp"
+	"go.uber.org/mock/gomock"
+)
+
+func TestEndMatch(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	srv := NewAPIServer(lobby.NewMockLobbier(ctrl), match.NewMockKeeper(ctrl), rating.NewMockKeeper(ctrl))
+	srv.AddGameServer("secret")
+
+	const matchID = "72b33e85-e8cd-45e6-89f4-25bfdac584d8"
+	startedAt := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	endedAt := startedAt.Add(20 * time.Minute)
+
+	tests := []struct {
+		name                string
+		reqURL              string
+		authorization       string
+		expectedError       bool
+		expectedCode        int
+		expectedContentType string
+		expectedBody        string
+		expectedMockCalls   func()
+	}{
+		{
+			name:                "finished",
+			reqURL:              "/match/" + matchID + "/finish",
+			authorization:       "Bearer secret",
+			expectedError:       false,
+			expectedCode:        200,
+			expectedContentType: "application/json; charset=utf-8",
+			expectedBody: `{
+								"match_id":"72b33e85-e8cd-45e6-89f4-25bfdac584d8",
+								"players":[{"player_id":"player1","level":1,"country":"USA","score":10,"rank":1}],
+								"status":"finished",
+								"created_at":"2024-01-01T11:59:30Z",
+								"started_at":"2024-01-01T12:00:00Z",
+								"ended_at":"2024-01-01T12:20:00Z"
+							}`,
+			expectedMockCalls: func() {
+				srv.MatchKeeper.(*match.MockKeeper).EXPECT().
+					EndMatch(matchID, match.StatusFinished).
+					Times(1).
+					Return(&match.LeaderBoard{
+						MatchID:   matchID,
+						Players:   []match.PlayerInfo{{PlayerID: "player1", Level: 1, Country: "USA", Score: 10}},
+						Status:    match.StatusFinished,
+						CreatedAt: startedAt.Add(-30 * time.Second),
+						StartedAt: startedAt,
+						EndedAt:   &endedAt,
+					}, nil)
+				srv.Lobby.(*lobby.MockLobbier).EXPECT().
+					RemoveBackfill(matchID).
+					Times(1).
+					Return(lobby.ErrBackfillNotFound)
+			},
+		},
+		{
+			name:                "abandoned",
+			reqURL:              "/match/" + matchID + "/abandon",
+			authorization:       "Bearer secret",
+			expectedError:       false,
+			expectedCode:        200,
+			expectedContentType: "application/json; charset=utf-8",
+			expectedBody: `{
+								"match_id":"72b33e85-e8cd-45e6-89f4-25bfdac584d8",
+								"players":[{"player_id":"player1","level":1,"country":"USA","score":0,"rank":1}],
+								"status":"abandoned",
+								"created_at":"2024-01-01T11:59:30Z",
+								"started_at":"2024-01-01T12:00:00Z",
+								"ended_at":"2024-01-01T12:20:00Z"
+							}`,
+			expectedMockCalls: func() {
+				srv.MatchKeeper.(*match.MockKeeper).EXPECT().
+					EndMatch(matchID, match.StatusAbandoned).
+					Times(1).
+					Return(&match.LeaderBoard{
+						MatchID:   matchID,
+						Players:   []match.PlayerInfo{{PlayerID: "player1", Level: 1, Country: "USA"}},
+						Status:    match.StatusAbandoned,
+						CreatedAt: startedAt.Add(-30 * time.Second),
+						StartedAt: startedAt,
+						EndedAt:   &endedAt,
+					}, nil)
+				srv.Lobby.(*lobby.MockLobbier).EXPECT().
+					RemoveBackfill(matchID).
+					Times(1)
+			},
+		},
+		{
+			name:                "match is already ended",
+			reqURL:              "/match/" + matchID + "/finish",
+			authorization:       "Bearer secret",
+			expectedError:       true,
+			expectedCode:        409,
+			expectedContentType: "application/json; charset=utf-8",
+			expectedBody:        `{"error":"match is already ended"}`,
+			expectedMockCalls: func() {
+				srv.MatchKeeper.(*match.MockKeeper).EXPECT().
+					EndMatch(matchID, match.StatusFinished).
+					Times(1).
+					Return(nil, match.ErrMatchEnded)
+			},
+		},
+		{
+			name:                "match not found",
+			reqURL:              "/match/" + matchID + "/abandon",
+			authorization:       "Bearer secret",
+			expectedError:       true,
+			expectedCode:        404,
+			expectedContentType: "application/json; charset=utf-8",
+			expectedBody:        `{"error":"match not found"}`,
+			expectedMockCalls: func() {
+				srv.MatchKeeper.(*match.MockKeeper).EXPECT().
+					EndMatch(matchID, match.StatusAbandoned).
+					Times(1).
+					Return(nil, match.ErrMatchNotFound)
+			},
+		},
+		{
+			name:                "not valid request: match_id is not valid UUID",
+			reqURL:              "/match/not-valid-uuid/finish",
+			authorization:       "Bearer secret",
+			expectedError:       true,
+			expectedCode:        400,
+			expectedContentType: "application/json; charset=utf-8",
+			expectedBody:        `{"error":"match_id is not valid UUID"}`,
+			expectedMockCalls:   func() {},
+		},
+		{
+			name:                "no token",
+			reqURL:              "/match/" + matchID + "/finish",
+			expectedError:       true,
+			expectedCode:        401,
+			expectedContentType: "application/json; charset=utf-8",
+			expectedBody:        `{"error":"the bearer token is missing or wrong"}`,
+			expectedMockCalls:   func() {},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.expectedMockCalls()
+			recorder := httptest.NewRecorder()
+
+			req, err := http.NewRequest(http.MethodPost, tt.reqURL, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+
+			srv.GinEngine.ServeHTTP(recorder, req)
+
+			if recorder.Code != tt.expectedCode {
+				t.Errorf("expected code %d, got %d", tt.expectedCode, recorder.Code)
+			}
+
+			if recorder.Header().Get("Content-Type") != tt.expectedContentType {
+				t.Errorf("expected content type %s, got %s", tt.expectedContentType, recorder.Header().Get("Content-Type"))
+			}
+
+			if !tt.expectedError {
+				var gotResponse GetLeaderBoardResponse
+				if err := json.Unmarshal(recorder.Body.Bytes(), &gotResponse); err != nil {
+					t.Fatal(err)
+				}
+
+				var expectedResponse GetLeaderBoardResponse
+				if err := json.Unmarshal([]byte(tt.expectedBody), &expectedResponse); err != nil {
+					t.Fatal(err)
+				}
+
+				if !cmp.Equal(gotResponse, expectedResponse) {
+					t.Errorf("expected response '%v', got '%v'", expectedResponse, gotResponse)
+				}
+			} else {
+				if recorder.Body.String() != tt.expectedBody {
+					t.Errorf("expected body '%s', got '%s'", tt.expectedBody, recorder.Body.String())
+				}
+			}
+		})
+	}
+}
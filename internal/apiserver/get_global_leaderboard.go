@@ -0,0 +1,76 @@
+package apiserver
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/TanyEm/match-maker/v2/internal/match"
+	"github.com/gin-gonic/gin"
+)
+
+type GetGlobalLeaderBoardRequest struct {
+	Country string `form:"country" binding:"omitempty,isocountry"`
+	// Level selects the level band of the level, e.g. 15 for the band 10-19
+	Level int         `form:"level" binding:"omitempty,min=1,max=99"`
+	By    match.Order `form:"by" binding:"omitempty,oneof=score rating"`
+	// Limit is the page size, 50 by default
+	Limit  int `form:"limit" binding:"omitempty,min=1,max=100"`
+	Offset int `form:"offset" binding:"omitempty,min=0"`
+}
+
+type GetGlobalLeaderBoardResponse struct {
+	Country   string              `json:"country,omitempty"`
+	LevelBand string              `json:"level_band,omitempty"`
+	By        match.Order         `json:"by"`
+	Total     int                 `json:"total"`
+	Limit     int                 `json:"limit"`
+	Offset    int                 `json:"offset"`
+	Players   []match.PlayerTotal `json:"players"`
+}
+
+// GetGlobalLeaderBoard returns the page of the players ranked across all the matches
+// by their total scores or their ratings
+func (s *APIServer) GetGlobalLeaderBoard(ctx *gin.Context) {
+	var req GetGlobalLeaderBoardRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if req.By == "" {
+		req.By = match.OrderScore
+	}
+
+	if req.By == match.OrderRating && s.Ratings == nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "the rating system is off"})
+		return
+	}
+
+	if req.Limit == 0 {
+		req.Limit = 50
+	}
+
+	players, total := s.MatchKeeper.GlobalLeaderBoard(match.BoardFilter{
+		Country: req.Country,
+		Level:   req.Level,
+		Order:   req.By,
+		Offset:  req.Offset,
+		Limit:   req.Limit,
+	})
+
+	response := GetGlobalLeaderBoardResponse{
+		Country: req.Country,
+		By:      req.By,
+		Total:   total,
+		Limit:   req.Limit,
+		Offset:  req.Offset,
+		Players: players,
+	}
+
+	if req.Level > 0 {
+		from, to := match.LevelBand(req.Level)
+		response.LevelBand = fmt.Sprintf("%d-%d", from, to)
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
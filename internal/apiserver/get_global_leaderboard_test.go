@@ -0,0 +1,155 @@
+package apiserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/TanyEm/match-maker/v2/internal/lobby"
+	"github.com/TanyEm/match-maker/v2/internal/match"
+	"github.com/TanyEm/match-maker/v2/internal/rating"
+	"github.com/google/go-cmp/cmp"
+	"go.uber.org/mock/gomock"
+)
+
+func TestGetGlobalLeaderBoard(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	srv := NewAPIServer(lobby.NewMockLobbier(ctrl), match.NewMockKeeper(ctrl), rating.NewMockKeeper(ctrl))
+
+	tests := []struct {
+		name                string
+		reqURL              string
+		expectedError       bool
+		expectedCode        int
+		expectedContentType string
+		expectedBody        string
+		expectedMockCalls   func()
+	}{
+		{
+			name:                "valid request",
+			reqURL:              "/leaderboards/global",
+			expectedError:       false,
+			expectedCode:        200,
+			expectedContentType: "application/json; charset=utf-8",
+			expectedBody: `{
+								"by":"score",
+								"total":3,
+								"limit":50,
+								"offset":0,
+								"players":[
+									{"player_id":"player2","country":"FIN","level":25,"score":30,"matches":2,"rank":1},
+									{"player_id":"player1","country":"FIN","level":12,"score":25,"matches":1,"rank":2},
+									{"player_id":"player3","country":"SWE","level":15,"score":25,"matches":1,"rank":2}
+								]
+							}`,
+			expectedMockCalls: func() {
+				srv.MatchKeeper.(*match.MockKeeper).EXPECT().
+					GlobalLeaderBoard(match.BoardFilter{Order: match.OrderScore, Limit: 50}).
+					Times(1).
+					Return([]match.PlayerTotal{
+						{PlayerID: "player2", Country: "FIN", Level: 25, Score: 30, Matches: 2, Rank: 1},
+						{PlayerID: "player1", Country: "FIN", Level: 12, Score: 25, Matches: 1, Rank: 2},
+						{PlayerID: "player3", Country: "SWE", Level: 15, Score: 25, Matches: 1, Rank: 2},
+					}, 3)
+			},
+		},
+		{
+			name:                "valid request by rating of a country and a level band",
+			reqURL:              "/leaderboards/global?country=FIN&level=15&by=rating&limit=10&offset=20",
+			expectedError:       false,
+			expectedCode:        200,
+			expectedContentType: "application/json; charset=utf-8",
+			expectedBody: `{
+								"country":"FIN",
+								"level_band":"10-19",
+								"by":"rating",
+								"total":21,
+								"limit":10,
+								"offset":20,
+								"players":[
+									{"player_id":"player1","country":"FIN","level":12,"score":25,"matches":1,"rating":1480,"rank":21}
+								]
+							}`,
+			expectedMockCalls: func() {
+				srv.MatchKeeper.(*match.MockKeeper).EXPECT().
+					GlobalLeaderBoard(match.BoardFilter{Country: "FIN", Level: 15, Order: match.OrderRating, Offset: 20, Limit: 10}).
+					Times(1).
+					Return([]match.PlayerTotal{
+						{PlayerID: "player1", Country: "FIN", Level: 12, Score: 25, Matches: 1, Rating: 1480, Rank: 21},
+					}, 21)
+			},
+		},
+		{
+			name:                "not valid request: unknown country",
+			reqURL:              "/leaderboards/global?country=XXX",
+			expectedError:       true,
+			expectedCode:        400,
+			expectedContentType: "application/json; charset=utf-8",
+			expectedBody:        `{"error":"Key: 'GetGlobalLeaderBoardRequest.Country' Error:Field validation for 'Country' failed on the 'isocountry' tag"}`,
+			expectedMockCalls:   func() {},
+		},
+		{
+			name:                "not valid request: unknown order",
+			reqURL:              "/leaderboards/global?by=wins",
+			expectedError:       true,
+			expectedCode:        400,
+			expectedContentType: "application/json; charset=utf-8",
+			expectedBody:        `{"error":"Key: 'GetGlobalLeaderBoardRequest.By' Error:Field validation for 'By' failed on the 'oneof' tag"}`,
+			expectedMockCalls:   func() {},
+		},
+		{
+			name:                "not valid request: level is out of range",
+			reqURL:              "/leaderboards/global?level=100",
+			expectedError:       true,
+			expectedCode:        400,
+			expectedContentType: "application/json; charset=utf-8",
+			expectedBody:        `{"error":"Key: 'GetGlobalLeaderBoardRequest.Level' Error:Field validation for 'Level' failed on the 'max' tag"}`,
+			expectedMockCalls:   func() {},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.expectedMockCalls()
+			recorder := httptest.NewRecorder()
+
+			req, err := http.NewRequest(http.MethodGet, tt.reqURL, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			srv.GinEngine.ServeHTTP(recorder, req)
+
+			if recorder.Code != tt.expectedCode {
+				t.Errorf("expected code %d, got %d", tt.expectedCode, recorder.Code)
+			}
+
+			if recorder.Header().Get("Content-Type") != tt.expectedContentType {
+				t.Errorf("expected content type %s, got %s", tt.expectedContentType, recorder.Header().Get("Content-Type"))
+			}
+
+			if !tt.expectedError {
+				var gotResponse map[string]any
+				if err := json.Unmarshal(recorder.Body.Bytes(), &gotResponse); err != nil {
+					t.Fatal(err)
+				}
+
+				var expectedResponse map[string]any
+				if err := json.Unmarshal([]byte(tt.expectedBody), &expectedResponse); err != nil {
+					t.Fatal(err)
+				}
+
+				if !cmp.Equal(gotResponse, expectedResponse) {
+					t.Errorf("expected response '%v', got '%v'", expectedResponse, gotResponse)
+				}
+			} else {
+				if recorder.Body.String() != tt.expectedBody {
+					t.Errorf("expected body '%s', got '%s'", tt.expectedBody, recorder.Body.String())
+				}
+			}
+		})
+	}
+}
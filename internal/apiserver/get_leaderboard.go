@@ -30,7 +30,8 @@ func (s *APIServer) GetLeaderBoard(ctx *gin.Context) {
 		return
 	}
 
-	leaderBoardResponse := GetLeaderBoardResponse{LeaderBoard: *leaderBoard}
+	// The players are sorted by their scores with their ranks
+	leaderBoardResponse := GetLeaderBoardResponse{LeaderBoard: leaderBoard.Ranked()}
 
 	ctx.JSON(http.StatusOK, leaderBoardResponse)
 }
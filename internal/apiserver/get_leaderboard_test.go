@@ -8,6 +8,7 @@ import (
 
 	"github.com/TanyEm/match-maker/v2/internal/lobby"
 	"github.com/TanyEm/match-maker/v2/internal/match"
+	"github.com/TanyEm/match-maker/v2/internal/rating"
 	"github.com/google/go-cmp/cmp"
 	"go.uber.org/mock/gomock"
 )
@@ -16,7 +17,7 @@ func TestGetLeaderBoard(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
-	srv := NewAPIServer(lobby.NewMockLobbier(ctrl), match.NewMockKeeper(ctrl))
+	srv := NewAPIServer(lobby.NewMockLobbier(ctrl), match.NewMockKeeper(ctrl), rating.NewMockKeeper(ctrl))
 
 	tests := []struct {
 		name                string
@@ -36,8 +37,8 @@ func TestGetLeaderBoard(t *testing.T) {
 			expectedBody: `{
 								"match_id":"110dc29f-dcd7-4bee-abea-2f7b24e47777", 
 								"players":[
-									{"player_id":"player2","level":2,"country":"USA","score":200},
-									{"player_id":"player1","level":1,"country":"USA","score":100}
+									{"player_id":"player2","level":2,"country":"USA","score":200,"rank":1},
+									{"player_id":"player1","level":1,"country":"USA","score":100,"rank":2}
 								]
 							}`,
 			expectedMockCalls: func() {
@@ -47,8 +48,8 @@ func TestGetLeaderBoard(t *testing.T) {
 					Return(&match.LeaderBoard{
 						MatchID: "110dc29f-dcd7-4bee-abea-2f7b24e47777",
 						Players: []match.PlayerInfo{
-							{PlayerID: "player2", Level: 2, Country: "USA", Score: 200},
 							{PlayerID: "player1", Level: 1, Country: "USA", Score: 100},
+							{PlayerID: "player2", Level: 2, Country: "USA", Score: 200},
 						},
 					})
 			},
@@ -0,0 +1,36 @@
+package apiserver
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/TanyEm/match-maker/v2/internal/match"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type GetMatchResponse struct {
+	match.Details
+}
+
+// GetMatch returns the full record of the match
+func (s *APIServer) GetMatch(ctx *gin.Context) {
+	matchID := ctx.Param("match_id")
+	if _, err := uuid.Parse(matchID); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "match_id is not valid UUID"})
+		return
+	}
+
+	details, err := s.MatchKeeper.GetMatch(matchID)
+	if errors.Is(err, match.ErrMatchNotFound) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "match not found"})
+		return
+	}
+
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, GetMatchResponse{Details: details})
+}
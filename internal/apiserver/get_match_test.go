@@ -0,0 +1,155 @@
+package apiserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/TanyEm/match-maker/v2/internal/lobby"
+	"github.com/TanyEm/match-maker/v2/internal/match"
+	"github.com/TanyEm/match-maker/v2/internal/rating"
+	"github.com/google/go-cmp/cmp"
+	"go.uber.org/mock/gomock"
+)
+
+func TestGetMatch(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	srv := NewAPIServer(lobby.NewMockLobbier(ctrl), match.NewMockKeeper(ctrl), rating.NewMockKeeper(ctrl))
+
+	const matchID = "72b33e85-e8cd-45e6-89f4-25bfdac584d8"
+	startedAt := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name                string
+		reqURL              string
+		expectedError       bool
+		expectedCode        int
+		expectedContentType string
+		expectedBody        string
+		expectedMockCalls   func()
+	}{
+		{
+			name:                "valid request",
+			reqURL:              "/matches/" + matchID,
+			expectedError:       false,
+			expectedCode:        200,
+			expectedContentType: "application/json; charset=utf-8",
+			expectedBody: `{
+								"match_id":"72b33e85-e8cd-45e6-89f4-25bfdac584d8",
+								"queue":"ranked",
+								"country":"FIN",
+								"level":4,
+								"status":"started",
+								"created_at":"2024-01-01T11:59:30Z",
+								"started_at":"2024-01-01T12:00:00Z",
+								"players":[
+									{"player_id":"player1","level":4,"country":"FIN","score":0,"rank":1,"team":1,"join_id":"b1a7f6b2-3f4e-4a8e-9d4c-1e2f3a4b5c6d","queue_time":12.5},
+									{"player_id":"player2","level":5,"country":"SWE","score":0,"rank":1,"team":2,"join_id":"c2b8a7c3-4a5f-4b9f-8e5d-2f3a4b5c6d7e","queue_time":30}
+								],
+								"teams":[
+									{"team":1,"player_ids":["player1"],"average_level":4,"average_rating":1040},
+									{"team":2,"player_ids":["player2"],"average_level":5,"average_rating":1050}
+								]
+							}`,
+			expectedMockCalls: func() {
+				srv.MatchKeeper.(*match.MockKeeper).EXPECT().
+					GetMatch(matchID).
+					Times(1).
+					Return(match.Details{
+						MatchID:   matchID,
+						Queue:     "ranked",
+						Country:   "FIN",
+						Level:     4,
+						Status:    match.StatusStarted,
+						CreatedAt: startedAt.Add(-30 * time.Second),
+						StartedAt: startedAt,
+						Players: []match.RosterEntry{
+							{
+								PlayerInfo: match.PlayerInfo{PlayerID: "player1", Level: 4, Country: "FIN", Rank: 1, Team: 1},
+								JoinID:     "b1a7f6b2-3f4e-4a8e-9d4c-1e2f3a4b5c6d",
+								QueueTime:  12.5,
+							},
+							{
+								PlayerInfo: match.PlayerInfo{PlayerID: "player2", Level: 5, Country: "SWE", Rank: 1, Team: 2},
+								JoinID:     "c2b8a7c3-4a5f-4b9f-8e5d-2f3a4b5c6d7e",
+								QueueTime:  30,
+							},
+						},
+						Teams: []match.Team{
+							{Team: 1, PlayerIDs: []string{"player1"}, AverageLevel: 4, AverageRating: 1040},
+							{Team: 2, PlayerIDs: []string{"player2"}, AverageLevel: 5, AverageRating: 1050},
+						},
+					}, nil)
+			},
+		},
+		{
+			name:                "match not found",
+			reqURL:              "/matches/" + matchID,
+			expectedError:       true,
+			expectedCode:        404,
+			expectedContentType: "application/json; charset=utf-8",
+			expectedBody:        `{"error":"match not found"}`,
+			expectedMockCalls: func() {
+				srv.MatchKeeper.(*match.MockKeeper).EXPECT().
+					GetMatch(matchID).
+					Times(1).
+					Return(match.Details{}, match.ErrMatchNotFound)
+			},
+		},
+		{
+			name:                "not valid request: match_id is not valid UUID",
+			reqURL:              "/matches/not-valid-uuid",
+			expectedError:       true,
+			expectedCode:        400,
+			expectedContentType: "application/json; charset=utf-8",
+			expectedBody:        `{"error":"match_id is not valid UUID"}`,
+			expectedMockCalls:   func() {},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.expectedMockCalls()
+			recorder := httptest.NewRecorder()
+
+			req, err := http.NewRequest(http.MethodGet, tt.reqURL, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			srv.GinEngine.ServeHTTP(recorder, req)
+
+			if recorder.Code != tt.expectedCode {
+				t.Errorf("expected code %d, got %d", tt.expectedCode, recorder.Code)
+			}
+
+			if recorder.Header().Get("Content-Type") != tt.expectedContentType {
+				t.Errorf("expected content type %s, got %s", tt.expectedContentType, recorder.Header().Get("Content-Type"))
+			}
+
+			if !tt.expectedError {
+				var gotResponse map[string]any
+				if err := json.Unmarshal(recorder.Body.Bytes(), &gotResponse); err != nil {
+					t.Fatal(err)
+				}
+
+				var expectedResponse map[string]any
+				if err := json.Unmarshal([]byte(tt.expectedBody), &expectedResponse); err != nil {
+					t.Fatal(err)
+				}
+
+				if !cmp.Equal(gotResponse, expectedResponse) {
+					t.Errorf("expected response '%v', got '%v'", expectedResponse, gotResponse)
+				}
+			} else {
+				if recorder.Body.String() != tt.expectedBody {
+					t.Errorf("expected body '%s', got '%s'", tt.expectedBody, recorder.Body.String())
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package apiserver
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/TanyEm/match-maker/v2/internal/match"
+	"github.com/gin-gonic/gin"
+)
+
+type GetPlayerMatchesRequest struct {
+	// Limit is the page size, 20 by default
+	Limit  int          `form:"limit" binding:"omitempty,min=1,max=100"`
+	Offset int          `form:"offset" binding:"omitempty,min=0"`
+	Status match.Status `form:"status" binding:"omitempty,oneof=created started finished abandoned"`
+	// From and To limit the start times of the matches in RFC 3339
+	From time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To   time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+}
+
+type GetPlayerMatchesResponse struct {
+	PlayerID string               `json:"player_id"`
+	Total    int                  `json:"total"`
+	Limit    int                  `json:"limit"`
+	Offset   int                  `json:"offset"`
+	Matches  []match.HistoryEntry `json:"matches"`
+}
+
+// GetPlayerMatches returns the page of the matches the player has played, the latest first
+func (s *APIServer) GetPlayerMatches(ctx *gin.Context) {
+	playerID := ctx.Param("player_id")
+
+	var req GetPlayerMatchesRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if req.Limit == 0 {
+		req.Limit = 20
+	}
+
+	if !req.From.IsZero() && !req.To.IsZero() && req.To.Before(req.From) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "to is before from"})
+		return
+	}
+
+	matches, total := s.MatchKeeper.PlayerMatches(playerID, match.HistoryFilter{
+		From:   req.From,
+		To:     req.To,
+		Status: req.Status,
+		Offset: req.Offset,
+		Limit:  req.Limit,
+	})
+
+	ctx.JSON(http.StatusOK, GetPlayerMatchesResponse{
+		PlayerID: playerID,
+		Total:    total,
+		Limit:    req.Limit,
+		Offset:   req.Offset,
+		Matches:  matches,
+	})
+}
@@ -0,0 +1,149 @@
+package apiserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/TanyEm/match-maker/v2/internal/lobby"
+	"github.com/TanyEm/match-maker/v2/internal/match"
+	"github.com/TanyEm/match-maker/v2/internal/rating"
+	"github.com/google/go-cmp/cmp"
+	"go.uber.org/mock/gomock"
+)
+
+func TestGetPlayerMatches(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	srv := NewAPIServer(lobby.NewMockLobbier(ctrl), match.NewMockKeeper(ctrl), rating.NewMockKeeper(ctrl))
+
+	startedAt := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	endedAt := startedAt.Add(10 * time.Minute)
+
+	tests := []struct {
+		name                string
+		reqURL              string
+		expectedError       bool
+		expectedCode        int
+		expectedContentType string
+		expectedBody        string
+		expectedMockCalls   func()
+	}{
+		{
+			name:                "valid request",
+			reqURL:              "/players/player1/matches",
+			expectedError:       false,
+			expectedCode:        200,
+			expectedContentType: "application/json; charset=utf-8",
+			expectedBody: `{
+								"player_id":"player1",
+								"total":2,
+								"limit":20,
+								"offset":0,
+								"matches":[
+									{"match_id":"d1b18698-f7eb-4cb1-b7f2-97e4b44c2c1d","status":"started","started_at":"2024-01-01T13:00:00Z","score":0,"rank":1,"players":2},
+									{"match_id":"72b33e85-e8cd-45e6-89f4-25bfdac584d8","queue":"ranked","status":"finished","started_at":"2024-01-01T12:00:00Z","ended_at":"2024-01-01T12:10:00Z","score":10,"rank":2,"players":4,"team":1}
+								]
+							}`,
+			expectedMockCalls: func() {
+				srv.MatchKeeper.(*match.MockKeeper).EXPECT().
+					PlayerMatches("player1", match.HistoryFilter{Limit: 20}).
+					Times(1).
+					Return([]match.HistoryEntry{
+						{MatchID: "d1b18698-f7eb-4cb1-b7f2-97e4b44c2c1d", Status: match.StatusStarted, StartedAt: startedAt.Add(time.Hour), Rank: 1, Players: 2},
+						{MatchID: "72b33e85-e8cd-45e6-89f4-25bfdac584d8", Queue: "ranked", Status: match.StatusFinished, StartedAt: startedAt, EndedAt: &endedAt, Score: 10, Rank: 2, Players: 4, Team: 1},
+					}, 2)
+			},
+		},
+		{
+			name:                "valid request with filters",
+			reqURL:              "/players/player1/matches?limit=5&offset=10&status=finished&from=2024-01-01T00:00:00Z&to=2024-01-02T00:00:00%2B02:00",
+			expectedError:       false,
+			expectedCode:        200,
+			expectedContentType: "application/json; charset=utf-8",
+			expectedBody:        `{"player_id":"player1","total":2,"limit":5,"offset":10,"matches":[]}`,
+			expectedMockCalls: func() {
+				srv.MatchKeeper.(*match.MockKeeper).EXPECT().
+					PlayerMatches("player1", gomock.Cond(func(f match.HistoryFilter) bool {
+						return f.Limit == 5 && f.Offset == 10 && f.Status == match.StatusFinished &&
+							f.From.Equal(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)) &&
+							f.To.Equal(time.Date(2024, 1, 1, 22, 0, 0, 0, time.UTC))
+					})).
+					Times(1).
+					Return([]match.HistoryEntry{}, 2)
+			},
+		},
+		{
+			name:                "not valid request: limit is too large",
+			reqURL:              "/players/player1/matches?limit=101",
+			expectedError:       true,
+			expectedCode:        400,
+			expectedContentType: "application/json; charset=utf-8",
+			expectedBody:        `{"error":"Key: 'GetPlayerMatchesRequest.Limit' Error:Field validation for 'Limit' failed on the 'max' tag"}`,
+			expectedMockCalls:   func() {},
+		},
+		{
+			name:                "not valid request: unknown status",
+			reqURL:              "/players/player1/matches?status=lost",
+			expectedError:       true,
+			expectedCode:        400,
+			expectedContentType: "application/json; charset=utf-8",
+			expectedBody:        `{"error":"Key: 'GetPlayerMatchesRequest.Status' Error:Field validation for 'Status' failed on the 'oneof' tag"}`,
+			expectedMockCalls:   func() {},
+		},
+		{
+			name:                "not valid request: to is before from",
+			reqURL:              "/players/player1/matches?from=2024-01-02T00:00:00Z&to=2024-01-01T00:00:00Z",
+			expectedError:       true,
+			expectedCode:        400,
+			expectedContentType: "application/json; charset=utf-8",
+			expectedBody:        `{"error":"to is before from"}`,
+			expectedMockCalls:   func() {},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.expectedMockCalls()
+			recorder := httptest.NewRecorder()
+
+			req, err := http.NewRequest(http.MethodGet, tt.reqURL, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			srv.GinEngine.ServeHTTP(recorder, req)
+
+			if recorder.Code != tt.expectedCode {
+				t.Errorf("expected code %d, got %d", tt.expectedCode, recorder.Code)
+			}
+
+			if recorder.Header().Get("Content-Type") != tt.expectedContentType {
+				t.Errorf("expected content type %s, got %s", tt.expectedContentType, recorder.Header().Get("Content-Type"))
+			}
+
+			if !tt.expectedError {
+				var gotResponse map[string]any
+				if err := json.Unmarshal(recorder.Body.Bytes(), &gotResponse); err != nil {
+					t.Fatal(err)
+				}
+
+				var expectedResponse map[string]any
+				if err := json.Unmarshal([]byte(tt.expectedBody), &expectedResponse); err != nil {
+					t.Fatal(err)
+				}
+
+				if !cmp.Equal(gotResponse, expectedResponse) {
+					t.Errorf("expected response '%v', got '%v'", expectedResponse, gotResponse)
+				}
+			} else {
+				if recorder.Body.String() != tt.expectedBody {
+					t.Errorf("expected body '%s', got '%s'", tt.expectedBody, recorder.Body.String())
+				}
+			}
+		})
+	}
+}
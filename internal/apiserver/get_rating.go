@@ -0,0 +1,28 @@
+package apiserver
+
+import (
+	"net/http"
+
+	"github.com/TanyEm/match-maker/v2/internal/rating"
+	"github.com/gin-gonic/gin"
+)
+
+type GetRatingResponse struct {
+	rating.Rating
+}
+
+func (s *APIServer) GetRating(ctx *gin.Context) {
+	playerID := ctx.Query("player_id")
+	if playerID == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "player_id is required"})
+		return
+	}
+
+	r, ok := s.Ratings.GetRating(playerID)
+	if !ok {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "rating not found"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, GetRatingResponse{Rating: r})
+}
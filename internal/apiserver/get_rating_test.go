@@ -0,0 +1,111 @@
+package apiserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/TanyEm/match-maker/v2/internal/lobby"
+	"github.com/TanyEm/match-maker/v2/internal/match"
+	"github.com/TanyEm/match-maker/v2/internal/rating"
+	"github.com/google/go-cmp/cmp"
+	"go.uber.org/mock/gomock"
+)
+
+func TestGetRating(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	srv := NewAPIServer(lobby.NewMockLobbier(ctrl), match.NewMockKeeper(ctrl), rating.NewMockKeeper(ctrl))
+
+	tests := []struct {
+		name                string
+		reqURL              string
+		expectedError       bool
+		expectedCode        int
+		expectedContentType string
+		expectedBody        string
+		expectedMockCalls   func()
+	}{
+		{
+			name:                "valid request",
+			reqURL:              "/rating?player_id=player1",
+			expectedError:       false,
+			expectedCode:        200,
+			expectedContentType: "application/json; charset=utf-8",
+			expectedBody:        `{"player_id":"player1","rating":1662.3,"deviation":120.5,"volatility":0.06,"matches":3}`,
+			expectedMockCalls: func() {
+				srv.Ratings.(*rating.MockKeeper).EXPECT().
+					GetRating("player1").
+					Times(1).
+					Return(rating.Rating{PlayerID: "player1", Rating: 1662.3, Deviation: 120.5, Volatility: 0.06, Matches: 3}, true)
+			},
+		},
+		{
+			name:                "rating not found",
+			reqURL:              "/rating?player_id=player2",
+			expectedError:       true,
+			expectedCode:        404,
+			expectedContentType: "application/json; charset=utf-8",
+			expectedBody:        `{"error":"rating not found"}`,
+			expectedMockCalls: func() {
+				srv.Ratings.(*rating.MockKeeper).EXPECT().
+					GetRating("player2").
+					Times(1).
+					Return(rating.Rating{}, false)
+			},
+		},
+		{
+			name:                "not valid request: empty player_id",
+			reqURL:              "/rating",
+			expectedError:       true,
+			expectedCode:        400,
+			expectedContentType: "application/json; charset=utf-8",
+			expectedBody:        `{"error":"player_id is required"}`,
+			expectedMockCalls:   func() {},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.expectedMockCalls()
+			recorder := httptest.NewRecorder()
+
+			req, err := http.NewRequest(http.MethodGet, tt.reqURL, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			srv.GinEngine.ServeHTTP(recorder, req)
+
+			if recorder.Code != tt.expectedCode {
+				t.Errorf("expected code %d, got %d", tt.expectedCode, recorder.Code)
+			}
+
+			if recorder.Header().Get("Content-Type") != tt.expectedContentType {
+				t.Errorf("expected content type %s, got %s", tt.expectedContentType, recorder.Header().Get("Content-Type"))
+			}
+
+			if !tt.expectedError {
+				var gotRatingResponse GetRatingResponse
+				if err := json.Unmarshal(recorder.Body.Bytes(), &gotRatingResponse); err != nil {
+					t.Fatal(err)
+				}
+
+				var expectedRatingResponse GetRatingResponse
+				if err := json.Unmarshal([]byte(tt.expectedBody), &expectedRatingResponse); err != nil {
+					t.Fatal(err)
+				}
+
+				if !cmp.Equal(gotRatingResponse, expectedRatingResponse) {
+					t.Errorf("expected rating response '%v', got '%v'", expectedRatingResponse, gotRatingResponse)
+				}
+			} else {
+				if recorder.Body.String() != tt.expectedBody {
+					t.Errorf("expected body '%s', got '%s'", tt.expectedBody, recorder.Body.String())
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package apiserver
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/TanyEm/match-maker/v2/internal/lobby"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// StatusTicketExpired is reported for the tickets pruned after their TTL
+const StatusTicketExpired = "ticket_expired"
+
+type TicketResponse struct {
+	JoinID   string            `json:"join_id"`
+	PlayerID string            `json:"player_id"`
+	Queue    string            `json:"queue,omitempty"`
+	State    lobby.TicketState `json:"state"`
+	MatchID  string            `json:"match_id,omitempty"`
+	Reason   lobby.Reason      `json:"reason,omitempty"`
+	// TimeInQueue is the number of seconds the player has waited for a match
+	TimeInQueue float64 `json:"time_in_queue"`
+	// EstimatedWait is the estimated number of seconds the queued player still waits for a match
+	EstimatedWait float64 `json:"estimated_wait,omitempty"`
+	// AcceptBy is the time the player has to accept the match of a ticket in the ready check by
+	AcceptBy *time.Time `json:"accept_by,omitempty"`
+	Accepted bool       `json:"accepted,omitempty"`
+	// CandidateMatchSize is the number of players of the waiting match the queued player is in
+	CandidateMatchSize int `json:"candidate_match_size,omitempty"`
+}
+
+// GetTicket returns the state of a lobby ticket without waiting for a match
+func (s *APIServer) GetTicket(ctx *gin.Context) {
+	joinID := ctx.Param("join_id")
+	if _, err := uuid.Parse(joinID); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "join_id is not valid UUID"})
+		return
+	}
+
+	ticket, err := s.Lobby.GetTicket(joinID)
+	if err != nil {
+		ticketNotFound(ctx, err)
+		return
+	}
+
+	now := time.Now()
+	response := TicketResponse{
+		JoinID:        ticket.JoinID,
+		PlayerID:      ticket.PlayerID,
+		Queue:         ticket.Queue,
+		State:         ticket.State,
+		MatchID:       ticket.MatchID,
+		Reason:        ticket.Reason,
+		TimeInQueue:   ticket.TimeInQueue(now).Seconds(),
+		EstimatedWait: ticket.RemainingWait(now).Seconds(),
+	}
+
+	if ticket.State == lobby.TicketReadyCheck {
+		response.AcceptBy = &ticket.ReadyBy
+		response.Accepted = ticket.Accepted
+	}
+
+	if ticket.State == lobby.TicketQueued {
+		response.CandidateMatchSize = s.Lobby.GetCandidateMatchSize(joinID)
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// ticketNotFound responds that the ticket is pruned after its TTL or it has never existed
+func ticketNotFound(ctx *gin.Context, err error) {
+	if errors.Is(err, lobby.ErrTicketPruned) {
+		ctx.JSON(http.StatusGone, gin.H{"error": "the lobby ticket has expired, try to join the lobby again", "status": StatusTicketExpired})
+		return
+	}
+
+	ctx.JSON(http.StatusNotFound, gin.H{"error": "ticket not found"})
+}
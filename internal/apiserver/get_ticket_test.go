@@ -0,0 +1,158 @@
+package apiserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/TanyEm/match-maker/v2/internal/lobby"
+	"github.com/TanyEm/match-maker/v2/internal/match"
+	"github.com/TanyEm/match-maker/v2/internal/rating"
+	"github.com/google/go-cmp/cmp"
+	"go.uber.org/mock/gomock"
+)
+
+func TestGetTicket(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	srv := NewAPIServer(lobby.NewMockLobbier(ctrl), match.NewMockKeeper(ctrl), rating.NewMockKeeper(ctrl))
+
+	const joinID = "72b33e85-e8cd-45e6-89f4-25bfdac584d8"
+	joinedAt := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name                string
+		reqURL              string
+		expectedError       bool
+		expectedCode        int
+		expectedContentType string
+		expectedBody        string
+		expectedMockCalls   func()
+	}{
+		{
+			name:                "matched ticket",
+			reqURL:              "/lobby/" + joinID,
+			expectedError:       false,
+			expectedCode:        200,
+			expectedContentType: "application/json; charset=utf-8",
+			expectedBody:        `{"join_id":"72b33e85-e8cd-45e6-89f4-25bfdac584d8","player_id":"player1","queue":"ranked","state":"matched","match_id":"d1b18698-f7eb-4cb1-b7f2-97e4b44c2c1d","time_in_queue":12.5}`,
+			expectedMockCalls: func() {
+				srv.Lobby.(*lobby.MockLobbier).EXPECT().
+					GetTicket(joinID).
+					Times(1).
+					Return(lobby.Ticket{
+						JoinID:    joinID,
+						PlayerID:  "player1",
+						Queue:     "ranked",
+						State:     lobby.TicketMatched,
+						MatchID:   "d1b18698-f7eb-4cb1-b7f2-97e4b44c2c1d",
+						JoinedAt:  joinedAt,
+						UpdatedAt: joinedAt.Add(12500 * time.Millisecond),
+					}, nil)
+			},
+		},
+		{
+			name:                "expired ticket",
+			reqURL:              "/lobby/" + joinID,
+			expectedError:       false,
+			expectedCode:        200,
+			expectedContentType: "application/json; charset=utf-8",
+			expectedBody:        `{"join_id":"72b33e85-e8cd-45e6-89f4-25bfdac584d8","player_id":"player1","state":"expired","reason":"not_enough_players","time_in_queue":30}`,
+			expectedMockCalls: func() {
+				srv.Lobby.(*lobby.MockLobbier).EXPECT().
+					GetTicket(joinID).
+					Times(1).
+					Return(lobby.Ticket{
+						JoinID:    joinID,
+						PlayerID:  "player1",
+						State:     lobby.TicketExpired,
+						Reason:    lobby.ReasonNotEnoughPlayers,
+						JoinedAt:  joinedAt,
+						UpdatedAt: joinedAt.Add(30 * time.Second),
+					}, nil)
+			},
+		},
+		{
+			name:                "ticket not found",
+			reqURL:              "/lobby/" + joinID,
+			expectedError:       true,
+			expectedCode:        404,
+			expectedContentType: "application/json; charset=utf-8",
+			expectedBody:        `{"error":"ticket not found"}`,
+			expectedMockCalls: func() {
+				srv.Lobby.(*lobby.MockLobbier).EXPECT().
+					GetTicket(joinID).
+					Times(1).
+					Return(lobby.Ticket{}, lobby.ErrTicketNotFound)
+			},
+		},
+		{
+			name:                "pruned ticket",
+			reqURL:              "/lobby/" + joinID,
+			expectedError:       true,
+			expectedCode:        410,
+			expectedContentType: "application/json; charset=utf-8",
+			expectedBody:        `{"error":"the lobby ticket has expired, try to join the lobby again","status":"ticket_expired"}`,
+			expectedMockCalls: func() {
+				srv.Lobby.(*lobby.MockLobbier).EXPECT().
+					GetTicket(joinID).
+					Times(1).
+					Return(lobby.Ticket{}, lobby.ErrTicketPruned)
+			},
+		},
+		{
+			name:                "not valid request: join_id is not valid UUID",
+			reqURL:              "/lobby/not-valid-uuid",
+			expectedError:       true,
+			expectedCode:        400,
+			expectedContentType: "application/json; charset=utf-8",
+			expectedBody:        `{"error":"join_id is not valid UUID"}`,
+			expectedMockCalls:   func() {},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.expectedMockCalls()
+			recorder := httptest.NewRecorder()
+
+			req, err := http.NewRequest(http.MethodGet, tt.reqURL, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			srv.GinEngine.ServeHTTP(recorder, req)
+
+			if recorder.Code != tt.expectedCode {
+				t.Errorf("expected code %d, got %d", tt.expectedCode, recorder.Code)
+			}
+
+			if recorder.Header().Get("Content-Type") != tt.expectedContentType {
+				t.Errorf("expected content type %s, got %s", tt.expectedContentType, recorder.Header().Get("Content-Type"))
+			}
+
+			if !tt.expectedError {
+				var gotResponse TicketResponse
+				if err := json.Unmarshal(recorder.Body.Bytes(), &gotResponse); err != nil {
+					t.Fatal(err)
+				}
+
+				var expectedResponse TicketResponse
+				if err := json.Unmarshal([]byte(tt.expectedBody), &expectedResponse); err != nil {
+					t.Fatal(err)
+				}
+
+				if !cmp.Equal(gotResponse, expectedResponse) {
+					t.Errorf("expected ticket response '%v', got '%v'", expectedResponse, gotResponse)
+				}
+			} else {
+				if recorder.Body.String() != tt.expectedBody {
+					t.Errorf("expected body '%s', got '%s'", tt.expectedBody, recorder.Body.String())
+				}
+			}
+		})
+	}
+}
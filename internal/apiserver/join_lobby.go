@@ -1,8 +1,10 @@
 package apiserver
 
 import (
+	"errors"
 	"net/http"
 
+	"github.com/TanyEm/match-maker/v2/internal/lobby"
 	"github.com/TanyEm/match-maker/v2/internal/player"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -13,14 +15,18 @@ import (
 // - playerID is any string
 // - players level is a number between 1 and 99
 // - country is a valid ISO 3 letters country code
+// - queue is the name of a configured queue (game mode), the default queue if it is empty
 type LobbyRequest struct {
 	PlayerID string `json:"player_id" binding:"required"`
 	Level    int    `json:"level" binding:"min=1,max=99"`
 	Country  string `json:"country" binding:"required,isocountry"`
+	Queue    string `json:"queue"`
 }
 
 type LobbyResponse struct {
 	JoinID string `json:"join_id"`
+	// EstimatedWait is the estimated number of seconds the player waits for a match
+	EstimatedWait float64 `json:"estimated_wait"`
 }
 
 func (s *APIServer) JoinLobby(ctx *gin.Context) {
@@ -30,14 +36,58 @@ func (s *APIServer) JoinLobby(ctx *gin.Context) {
 		return
 	}
 
-	player := player.Player{
+	player := s.newPlayer(req)
+
+	if err := s.Lobby.AddPlayer(player); err != nil {
+		if errors.Is(err, lobby.ErrDraining) {
+			ctx.JSON(http.StatusServiceUnavailable, gin.H{"error": "the lobby is shutting down, try again later"})
+			return
+		}
+
+		if errors.Is(err, lobby.ErrPenalised) {
+			ctx.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+
+		if errors.Is(err, lobby.ErrDuplicatePlayer) {
+			ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+
+		if errors.Is(err, lobby.ErrUnknownQueue) {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	response := LobbyResponse{JoinID: player.JoinID}
+
+	// The estimate is kept on the ticket before the player is placed into a match
+	if ticket, err := s.Lobby.GetTicket(player.JoinID); err == nil {
+		response.EstimatedWait = ticket.EstimatedWait.Seconds()
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// newPlayer creates a player with a new time ordered join ID and the player's rating if the rating system is on
+func (s *APIServer) newPlayer(req LobbyRequest) player.Player {
+	p := player.Player{
 		PlayerID: req.PlayerID,
 		Level:    req.Level,
 		Country:  req.Country,
-		JoinID:   uuid.New().String(),
+		JoinID:   uuid.Must(uuid.NewV7()).String(),
+		Queue:    req.Queue,
 	}
 
-	s.Lobby.AddPlayer(player)
+	if s.Ratings != nil {
+		if r, ok := s.Ratings.GetRating(p.PlayerID); ok {
+			p.Rating = r.Rating
+		}
+	}
 
-	ctx.JSON(http.StatusOK, LobbyResponse{JoinID: player.JoinID})
+	return p
 }
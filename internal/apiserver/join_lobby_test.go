@@ -3,13 +3,16 @@ package apiserver
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/TanyEm/match-maker/v2/internal/lobby"
 	"github.com/TanyEm/match-maker/v2/internal/match"
 	"github.com/TanyEm/match-maker/v2/internal/player"
+	"github.com/TanyEm/match-maker/v2/internal/rating"
 	"github.com/TanyEm/match-maker/v2/test/utils"
 	"github.com/google/uuid"
 	"go.uber.org/mock/gomock"
@@ -19,7 +22,7 @@ func TestJoinLobby(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
-	srv := NewAPIServer(lobby.NewMockLobbier(ctrl), match.NewMockKeeper(ctrl))
+	srv := NewAPIServer(lobby.NewMockLobbier(ctrl), match.NewMockKeeper(ctrl), rating.NewMockKeeper(ctrl))
 
 	tests := []struct {
 		name                string
@@ -36,7 +39,7 @@ func TestJoinLobby(t *testing.T) {
 			expectedError:       false,
 			expectedCode:        200,
 			expectedContentType: "application/json; charset=utf-8",
-			expectedBody:        `{"join_id":"00000000-0000-0000-0000-000000000000"}`,
+			expectedBody:        `{"join_id":"00000000-0000-0000-0000-000000000000","estimated_wait":12.5}`,
 			expectedMockCalls: func() {
 				p := player.Player{
 					PlayerID: "player1",
@@ -44,7 +47,107 @@ func TestJoinLobby(t *testing.T) {
 					Country:  "USA",
 					JoinID:   gomock.Any().String(),
 				}
+				srv.Ratings.(*rating.MockKeeper).EXPECT().GetRating("player1").Times(1).Return(rating.Rating{}, false)
 				srv.Lobby.(*lobby.MockLobbier).EXPECT().AddPlayer(utils.EqPlayer(p)).Times(1)
+				srv.Lobby.(*lobby.MockLobbier).EXPECT().GetTicket(gomock.Any()).Times(1).Return(lobby.Ticket{EstimatedWait: 12500 * time.Millisecond}, nil)
+			},
+		},
+		{
+			name:                "valid request: rated player",
+			req:                 []byte(`{"player_id": "player2", "level": 1, "country": "USA"}`),
+			expectedError:       false,
+			expectedCode:        200,
+			expectedContentType: "application/json; charset=utf-8",
+			expectedBody:        `{"join_id":"00000000-0000-0000-0000-000000000000","estimated_wait":30}`,
+			expectedMockCalls: func() {
+				p := player.Player{
+					PlayerID: "player2",
+					Level:    1,
+					Country:  "USA",
+					Rating:   1620,
+				}
+				srv.Ratings.(*rating.MockKeeper).EXPECT().GetRating("player2").Times(1).Return(rating.Rating{PlayerID: "player2", Rating: 1620}, true)
+				srv.Lobby.(*lobby.MockLobbier).EXPECT().AddPlayer(utils.EqPlayer(p)).Times(1)
+				srv.Lobby.(*lobby.MockLobbier).EXPECT().GetTicket(gomock.Any()).Times(1).Return(lobby.Ticket{EstimatedWait: 30 * time.Second}, nil)
+			},
+		},
+		{
+			name:                "valid request: named queue",
+			req:                 []byte(`{"player_id": "player1", "level": 1, "country": "USA", "queue": "ranked"}`),
+			expectedError:       false,
+			expectedCode:        200,
+			expectedContentType: "application/json; charset=utf-8",
+			expectedBody:        `{"join_id":"00000000-0000-0000-0000-000000000000","estimated_wait":30}`,
+			expectedMockCalls: func() {
+				p := player.Player{
+					PlayerID: "player1",
+					Level:    1,
+					Country:  "USA",
+					Queue:    "ranked",
+				}
+				srv.Ratings.(*rating.MockKeeper).EXPECT().GetRating("player1").Times(1).Return(rating.Rating{}, false)
+				srv.Lobby.(*lobby.MockLobbier).EXPECT().AddPlayer(utils.EqPlayer(p)).Times(1)
+				srv.Lobby.(*lobby.MockLobbier).EXPECT().GetTicket(gomock.Any()).Times(1).Return(lobby.Ticket{EstimatedWait: 30 * time.Second}, nil)
+			},
+		},
+		{
+			name:                "unknown queue",
+			req:                 []byte(`{"player_id": "player1", "level": 1, "country": "USA", "queue": "unknown"}`),
+			expectedError:       true,
+			expectedCode:        400,
+			expectedContentType: "application/json; charset=utf-8",
+			expectedBody:        `{"error":"queue \"unknown\": unknown queue"}`,
+			expectedMockCalls: func() {
+				srv.Ratings.(*rating.MockKeeper).EXPECT().GetRating("player1").Times(1).Return(rating.Rating{}, false)
+				srv.Lobby.(*lobby.MockLobbier).EXPECT().
+					AddPlayer(gomock.Any()).
+					Times(1).
+					Return(fmt.Errorf("queue %q: %w", "unknown", lobby.ErrUnknownQueue))
+			},
+		},
+		{
+			name:                "player is penalised",
+			req:                 []byte(`{"player_id": "player1", "level": 1, "country": "USA"}`),
+			expectedError:       true,
+			expectedCode:        403,
+			expectedContentType: "application/json; charset=utf-8",
+			expectedBody:        `{"error":"player player1 until 2024-01-01T12:01:00Z: player is penalised for declining a match"}`,
+			expectedMockCalls: func() {
+				srv.Ratings.(*rating.MockKeeper).EXPECT().GetRating("player1").Times(1).Return(rating.Rating{}, false)
+				srv.Lobby.(*lobby.MockLobbier).EXPECT().
+					AddPlayer(gomock.Any()).
+					Times(1).
+					Return(fmt.Errorf("player player1 until 2024-01-01T12:01:00Z: %w", lobby.ErrPenalised))
+			},
+		},
+		{
+			name:                "lobby is draining",
+			req:                 []byte(`{"player_id": "player1", "level": 1, "country": "USA"}`),
+			expectedError:       true,
+			expectedCode:        503,
+			expectedContentType: "application/json; charset=utf-8",
+			expectedBody:        `{"error":"the lobby is shutting down, try again later"}`,
+			expectedMockCalls: func() {
+				srv.Ratings.(*rating.MockKeeper).EXPECT().GetRating("player1").Times(1).Return(rating.Rating{}, false)
+				srv.Lobby.(*lobby.MockLobbier).EXPECT().
+					AddPlayer(gomock.Any()).
+					Times(1).
+					Return(lobby.ErrDraining)
+			},
+		},
+		{
+			name:                "player is already waiting",
+			req:                 []byte(`{"player_id": "player1", "level": 1, "country": "USA"}`),
+			expectedError:       true,
+			expectedCode:        409,
+			expectedContentType: "application/json; charset=utf-8",
+			expectedBody:        `{"error":"player player1: player is already waiting in the lobby"}`,
+			expectedMockCalls: func() {
+				srv.Ratings.(*rating.MockKeeper).EXPECT().GetRating("player1").Times(1).Return(rating.Rating{}, false)
+				srv.Lobby.(*lobby.MockLobbier).EXPECT().
+					AddPlayer(gomock.Any()).
+					Times(1).
+					Return(fmt.Errorf("player player1: %w", lobby.ErrDuplicatePlayer))
 			},
 		},
 		{
@@ -134,6 +237,15 @@ func TestJoinLobby(t *testing.T) {
 				if _, err := uuid.Parse(lobbyResponse.JoinID); err != nil {
 					t.Errorf("join_id is not valid UUID: '%s'", lobbyResponse.JoinID)
 				}
+
+				var expectedResponse LobbyResponse
+				if err := json.Unmarshal([]byte(tt.expectedBody), &expectedResponse); err != nil {
+					t.Fatal(err)
+				}
+
+				if lobbyResponse.EstimatedWait != expectedResponse.EstimatedWait {
+					t.Errorf("expected estimated_wait %v, got %v", expectedResponse.EstimatedWait, lobbyResponse.EstimatedWait)
+				}
 			} else {
 				if recorder.Body.String() != tt.expectedBody {
 					t.Errorf("expected body '%s', got '%s'", tt.expectedBody, recorder.Body.String())
@@ -26,32 +26,54 @@ func (s *APIServer) JoinMatch(ctx *gin.Context) {
 		return
 	}
 
-	// Create a context with a s.Lobby.GetMatchMakingTime() (default 30 sec) timeout
+	// Create a context with a s.Lobby.GetMatchMakingTime() (default 30 sec) timeout, the max wait if the players
+	// are carried over into the next rounds, so a carried over player waits until the ticket is resolved
 	c, cancel := context.WithTimeout(ctx.Request.Context(), s.Lobby.GetMatchMakingTime())
 	defer cancel()
 
 	// Replace the request's context with the new one
 	ctx.Request = ctx.Request.WithContext(c)
 
-	var matchID string
+	var ticket lobby.Ticket
 
-	// Wait for the match to be created for s.Lobby.GetMatchMakingTime() seconds
+	// Wait for the ticket to be resolved for s.Lobby.GetMatchMakingTime() seconds
 	for {
-		matchID = s.Lobby.GetMatchByJoinID(joinID)
-		if matchID != "" {
+		var err error
+		ticket, err = s.Lobby.GetTicket(joinID)
+		if err != nil {
+			ticketNotFound(ctx, err)
+			return
+		}
+
+		// The player who has accepted the match waits for the other players to accept it
+		waiting := ticket.State == lobby.TicketQueued || (ticket.State == lobby.TicketReadyCheck && ticket.Accepted)
+		if !waiting {
 			break
 		}
+
 		time.Sleep(100 * time.Millisecond)
 		if c.Err() != nil {
-			matchID = lobby.ErrNoMatch
 			break
 		}
 	}
 
-	if matchID == lobby.ErrNoMatch {
+	switch ticket.State {
+	case lobby.TicketMatched:
+		ctx.JSON(http.StatusOK, MatchResponse{MatchID: ticket.MatchID})
+	case lobby.TicketExpired:
 		ctx.JSON(http.StatusNotFound, gin.H{"error": "no match for the player, try to join the lobby again"})
-		return
-	}
+	case lobby.TicketCancelled:
+		ctx.JSON(http.StatusGone, gin.H{"error": "the lobby ticket is cancelled", "status": lobby.TicketCancelled})
+	case lobby.TicketFailed:
+		ctx.JSON(http.StatusServiceUnavailable, gin.H{"error": "the lobby could not match the player, try to join the lobby again", "status": lobby.TicketFailed})
+	case lobby.TicketReadyCheck:
+		if !ticket.Accepted {
+			ctx.JSON(http.StatusAccepted, gin.H{"message": "the match is found, accept it to play", "status": lobby.TicketReadyCheck, "accept_by": ticket.ReadyBy})
+			return
+		}
 
-	ctx.JSON(http.StatusOK, MatchResponse{MatchID: matchID})
+		ctx.JSON(http.StatusRequestTimeout, gin.H{"error": "the other players have not accepted the match yet, try again", "status": lobby.TicketReadyCheck})
+	default:
+		ctx.JSON(http.StatusRequestTimeout, gin.H{"error": "the player is still waiting for a match, try again", "status": lobby.TicketQueued})
+	}
 }
@@ -5,9 +5,11 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/TanyEm/match-maker/v2/internal/lobby"
 	"github.com/TanyEm/match-maker/v2/internal/match"
+	"github.com/TanyEm/match-maker/v2/internal/rating"
 	"github.com/google/go-cmp/cmp"
 	"go.uber.org/mock/gomock"
 )
@@ -16,7 +18,7 @@ func TestJoinMatch(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
-	srv := NewAPIServer(lobby.NewMockLobbier(ctrl), match.NewMockKeeper(ctrl))
+	srv := NewAPIServer(lobby.NewMockLobbier(ctrl), match.NewMockKeeper(ctrl), rating.NewMockKeeper(ctrl))
 
 	tests := []struct {
 		name                string
@@ -39,9 +41,9 @@ func TestJoinMatch(t *testing.T) {
 					GetMatchMakingTime().
 					Times(1)
 				srv.Lobby.(*lobby.MockLobbier).EXPECT().
-					GetMatchByJoinID("72b33e85-e8cd-45e6-89f4-25bfdac584d8").
+					GetTicket("72b33e85-e8cd-45e6-89f4-25bfdac584d8").
 					Times(1).
-					Return("d1b18698-f7eb-4cb1-b7f2-97e4b44c2c1d")
+					Return(lobby.Ticket{JoinID: "72b33e85-e8cd-45e6-89f4-25bfdac584d8", State: lobby.TicketMatched, MatchID: "d1b18698-f7eb-4cb1-b7f2-97e4b44c2c1d"}, nil)
 			},
 		},
 		{
@@ -56,9 +58,119 @@ func TestJoinMatch(t *testing.T) {
 					GetMatchMakingTime().
 					Times(1)
 				srv.Lobby.(*lobby.MockLobbier).EXPECT().
-					GetMatchByJoinID("72b33e85-e8cd-45e6-89f4-25bfdac584d8").
+					GetTicket("72b33e85-e8cd-45e6-89f4-25bfdac584d8").
 					Times(1).
-					Return(lobby.ErrNoMatch)
+					Return(lobby.Ticket{JoinID: "72b33e85-e8cd-45e6-89f4-25bfdac584d8", State: lobby.TicketExpired, Reason: lobby.ReasonNotEnoughPlayers}, nil)
+			},
+		},
+		{
+			name:                "valid request but ticket is cancelled",
+			reqURL:              "/match?join_id=72b33e85-e8cd-45e6-89f4-25bfdac584d8",
+			expectedError:       true,
+			expectedCode:        410,
+			expectedContentType: "application/json; charset=utf-8",
+			expectedBody:        `{"error":"the lobby ticket is cancelled","status":"cancelled"}`,
+			expectedMockCalls: func() {
+				srv.Lobby.(*lobby.MockLobbier).EXPECT().
+					GetMatchMakingTime().
+					Times(1)
+				srv.Lobby.(*lobby.MockLobbier).EXPECT().
+					GetTicket("72b33e85-e8cd-45e6-89f4-25bfdac584d8").
+					Times(1).
+					Return(lobby.Ticket{JoinID: "72b33e85-e8cd-45e6-89f4-25bfdac584d8", State: lobby.TicketCancelled, Reason: lobby.ReasonLeft}, nil)
+			},
+		},
+		{
+			name:                "valid request but ticket failed",
+			reqURL:              "/match?join_id=72b33e85-e8cd-45e6-89f4-25bfdac584d8",
+			expectedError:       true,
+			expectedCode:        503,
+			expectedContentType: "application/json; charset=utf-8",
+			expectedBody:        `{"error":"the lobby could not match the player, try to join the lobby again","status":"failed"}`,
+			expectedMockCalls: func() {
+				srv.Lobby.(*lobby.MockLobbier).EXPECT().
+					GetMatchMakingTime().
+					Times(1)
+				srv.Lobby.(*lobby.MockLobbier).EXPECT().
+					GetTicket("72b33e85-e8cd-45e6-89f4-25bfdac584d8").
+					Times(1).
+					Return(lobby.Ticket{JoinID: "72b33e85-e8cd-45e6-89f4-25bfdac584d8", State: lobby.TicketFailed, Reason: lobby.ReasonLobbyStopped}, nil)
+			},
+		},
+		{
+			name:                "valid request but ticket not found",
+			reqURL:              "/match?join_id=72b33e85-e8cd-45e6-89f4-25bfdac584d8",
+			expectedError:       true,
+			expectedCode:        404,
+			expectedContentType: "application/json; charset=utf-8",
+			expectedBody:        `{"error":"ticket not found"}`,
+			expectedMockCalls: func() {
+				srv.Lobby.(*lobby.MockLobbier).EXPECT().
+					GetMatchMakingTime().
+					Times(1)
+				srv.Lobby.(*lobby.MockLobbier).EXPECT().
+					GetTicket("72b33e85-e8cd-45e6-89f4-25bfdac584d8").
+					Times(1).
+					Return(lobby.Ticket{}, lobby.ErrTicketNotFound)
+			},
+		},
+		{
+			name:                "valid request but ticket is pruned",
+			reqURL:              "/match?join_id=72b33e85-e8cd-45e6-89f4-25bfdac584d8",
+			expectedError:       true,
+			expectedCode:        410,
+			expectedContentType: "application/json; charset=utf-8",
+			expectedBody:        `{"error":"the lobby ticket has expired, try to join the lobby again","status":"ticket_expired"}`,
+			expectedMockCalls: func() {
+				srv.Lobby.(*lobby.MockLobbier).EXPECT().
+					GetMatchMakingTime().
+					Times(1)
+				srv.Lobby.(*lobby.MockLobbier).EXPECT().
+					GetTicket("72b33e85-e8cd-45e6-89f4-25bfdac584d8").
+					Times(1).
+					Return(lobby.Ticket{}, lobby.ErrTicketPruned)
+			},
+		},
+		{
+			name:                "valid request but match is in the ready check",
+			reqURL:              "/match?join_id=72b33e85-e8cd-45e6-89f4-25bfdac584d8",
+			expectedError:       true,
+			expectedCode:        202,
+			expectedContentType: "application/json; charset=utf-8",
+			expectedBody:        `{"accept_by":"2024-01-01T12:00:10Z","message":"the match is found, accept it to play","status":"ready_check"}`,
+			expectedMockCalls: func() {
+				srv.Lobby.(*lobby.MockLobbier).EXPECT().
+					GetMatchMakingTime().
+					Times(1)
+				srv.Lobby.(*lobby.MockLobbier).EXPECT().
+					GetTicket("72b33e85-e8cd-45e6-89f4-25bfdac584d8").
+					Times(1).
+					Return(lobby.Ticket{
+						JoinID:  "72b33e85-e8cd-45e6-89f4-25bfdac584d8",
+						State:   lobby.TicketReadyCheck,
+						ReadyBy: time.Date(2024, 1, 1, 12, 0, 10, 0, time.UTC),
+					}, nil)
+			},
+		},
+		{
+			name:                "valid request but other players have not accepted the match",
+			reqURL:              "/match?join_id=72b33e85-e8cd-45e6-89f4-25bfdac584d8",
+			expectedError:       true,
+			expectedCode:        408,
+			expectedContentType: "application/json; charset=utf-8",
+			expectedBody:        `{"error":"the other players have not accepted the match yet, try again","status":"ready_check"}`,
+			expectedMockCalls: func() {
+				srv.Lobby.(*lobby.MockLobbier).EXPECT().
+					GetMatchMakingTime().
+					Times(1)
+				srv.Lobby.(*lobby.MockLobbier).EXPECT().
+					GetTicket("72b33e85-e8cd-45e6-89f4-25bfdac584d8").
+					Times(1).
+					Return(lobby.Ticket{
+						JoinID:   "72b33e85-e8cd-45e6-89f4-25bfdac584d8",
+						State:    lobby.TicketReadyCheck,
+						Accepted: true,
+					}, nil)
 			},
 		},
 		{
@@ -79,6 +191,25 @@ func TestJoinMatch(t *testing.T) {
 			expectedBody:        `{"error":"join_id is not valid UUID"}`,
 			expectedMockCalls:   func() {},
 		},
+		// The queued ticket is polled until the timeout, so the case is the last one to expect GetTicket calls
+		{
+			name:                "valid request but player is still waiting",
+			reqURL:              "/match?join_id=72b33e85-e8cd-45e6-89f4-25bfdac584d8",
+			expectedError:       true,
+			expectedCode:        408,
+			expectedContentType: "application/json; charset=utf-8",
+			expectedBody:        `{"error":"the player is still waiting for a match, try again","status":"queued"}`,
+			expectedMockCalls: func() {
+				srv.Lobby.(*lobby.MockLobbier).EXPECT().
+					GetMatchMakingTime().
+					Times(1).
+					Return(150 * time.Millisecond)
+				srv.Lobby.(*lobby.MockLobbier).EXPECT().
+					GetTicket("72b33e85-e8cd-45e6-89f4-25bfdac584d8").
+					MinTimes(1).
+					Return(lobby.Ticket{JoinID: "72b33e85-e8cd-45e6-89f4-25bfdac584d8", State: lobby.TicketQueued}, nil)
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -0,0 +1,97 @@
+package apiserver
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/TanyEm/match-maker/v2/internal/lobby"
+	"github.com/TanyEm/match-maker/v2/internal/player"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// PartyRequest is a request of several players to join a lobby together.
+// The party waits in the lobby of the first player's country.
+// The queue of the party is used for all members, the queues of the members are ignored.
+type PartyRequest struct {
+	Players []LobbyRequest `json:"players" binding:"required,min=2,dive"`
+	Queue   string         `json:"queue"`
+}
+
+type PartyResponse struct {
+	PartyID string        `json:"party_id"`
+	Members []PartyMember `json:"members"`
+}
+
+// PartyMember is a join ID of a party member, every member checks the match with their own join ID
+type PartyMember struct {
+	PlayerID string `json:"player_id"`
+	JoinID   string `json:"join_id"`
+	// EstimatedWait is the estimated number of seconds the member waits for a match
+	EstimatedWait float64 `json:"estimated_wait"`
+}
+
+func (s *APIServer) JoinParty(ctx *gin.Context) {
+	var req PartyRequest
+	if err := ctx.BindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	partyID := uuid.New().String()
+	members := make([]player.Player, 0, len(req.Players))
+	response := PartyResponse{
+		PartyID: partyID,
+		Members: make([]PartyMember, 0, len(req.Players)),
+	}
+
+	seen := make(map[string]struct{}, len(req.Players))
+	for _, memberReq := range req.Players {
+		if _, ok := seen[memberReq.PlayerID]; ok {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("player %s is in the party more than once", memberReq.PlayerID)})
+			return
+		}
+		seen[memberReq.PlayerID] = struct{}{}
+
+		member := s.newPlayer(memberReq)
+		member.PartyID = partyID
+		member.Queue = req.Queue
+
+		members = append(members, member)
+		response.Members = append(response.Members, PartyMember{PlayerID: member.PlayerID, JoinID: member.JoinID})
+	}
+
+	if err := s.Lobby.AddParty(members); err != nil {
+		if errors.Is(err, lobby.ErrDraining) {
+			ctx.JSON(http.StatusServiceUnavailable, gin.H{"error": "the lobby is shutting down, try again later"})
+			return
+		}
+
+		if errors.Is(err, lobby.ErrPartyTooBig) || errors.Is(err, lobby.ErrUnknownQueue) {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		if errors.Is(err, lobby.ErrPenalised) {
+			ctx.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+
+		if errors.Is(err, lobby.ErrDuplicatePlayer) {
+			ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	for i, member := range response.Members {
+		if ticket, err := s.Lobby.GetTicket(member.JoinID); err == nil {
+			response.Members[i].EstimatedWait = ticket.EstimatedWait.Seconds()
+		}
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,221 @@
+package apiserver
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/TanyEm/match-maker/v2/internal/lobby"
+	"github.com/TanyEm/match-maker/v2/internal/match"
+	"github.com/TanyEm/match-maker/v2/internal/player"
+	"github.com/TanyEm/match-maker/v2/internal/rating"
+	"github.com/google/uuid"
+	"go.uber.org/mock/gomock"
+)
+
+func TestJoinParty(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	srv := NewAPIServer(lobby.NewMockLobbier(ctrl), match.NewMockKeeper(ctrl), rating.NewMockKeeper(ctrl))
+
+	tests := []struct {
+		name                string
+		req                 []byte
+		expectedError       bool
+		expectedCode        int
+		expectedContentType string
+		expectedBody        string
+		expectedMockCalls   func()
+	}{
+		{
+			name:                "valid request",
+			req:                 []byte(`{"players": [{"player_id": "player1", "level": 1, "country": "USA"}, {"player_id": "player2", "level": 3, "country": "CAN"}]}`),
+			expectedError:       false,
+			expectedCode:        200,
+			expectedContentType: "application/json; charset=utf-8",
+			expectedBody:        `{"members":[{"player_id":"player1","estimated_wait":30},{"player_id":"player2","estimated_wait":12.5}]}`,
+			expectedMockCalls: func() {
+				srv.Ratings.(*rating.MockKeeper).EXPECT().GetRating("player1").Times(1).Return(rating.Rating{}, false)
+				srv.Ratings.(*rating.MockKeeper).EXPECT().GetRating("player2").Times(1).Return(rating.Rating{}, false)
+				srv.Lobby.(*lobby.MockLobbier).EXPECT().
+					AddParty(gomock.Any()).
+					Times(1).
+					DoAndReturn(func(members []player.Player) error {
+						if len(members) != 2 || members[0].PlayerID != "player1" || members[1].PlayerID != "player2" {
+							t.Errorf("unexpected party members %v", members)
+						}
+
+						if members[0].PartyID == "" || members[0].PartyID != members[1].PartyID {
+							t.Errorf("expected party members to share a party ID, got %v", members)
+						}
+
+						return nil
+					})
+				srv.Lobby.(*lobby.MockLobbier).EXPECT().GetTicket(gomock.Any()).Times(1).Return(lobby.Ticket{EstimatedWait: 30 * time.Second}, nil)
+				srv.Lobby.(*lobby.MockLobbier).EXPECT().GetTicket(gomock.Any()).Times(1).Return(lobby.Ticket{EstimatedWait: 12500 * time.Millisecond}, nil)
+			},
+		},
+		{
+			name:                "valid request: named queue",
+			req:                 []byte(`{"queue": "ranked", "players": [{"player_id": "player1", "level": 1, "country": "USA", "queue": "casual"}, {"player_id": "player2", "level": 3, "country": "CAN"}]}`),
+			expectedError:       false,
+			expectedCode:        200,
+			expectedContentType: "application/json; charset=utf-8",
+			expectedBody:        `{"members":[{"player_id":"player1","estimated_wait":30},{"player_id":"player2","estimated_wait":30}]}`,
+			expectedMockCalls: func() {
+				srv.Ratings.(*rating.MockKeeper).EXPECT().GetRating(gomock.Any()).Times(2).Return(rating.Rating{}, false)
+				srv.Lobby.(*lobby.MockLobbier).EXPECT().
+					AddParty(gomock.Any()).
+					Times(1).
+					DoAndReturn(func(members []player.Player) error {
+						for _, member := range members {
+							if member.Queue != "ranked" {
+								t.Errorf("expected party members to join the party queue, got %v", members)
+							}
+						}
+
+						return nil
+					})
+				srv.Lobby.(*lobby.MockLobbier).EXPECT().GetTicket(gomock.Any()).Times(2).Return(lobby.Ticket{EstimatedWait: 30 * time.Second}, nil)
+			},
+		},
+		{
+			name:                "party is too big",
+			req:                 []byte(`{"players": [{"player_id": "player1", "level": 1, "country": "USA"}, {"player_id": "player2", "level": 3, "country": "CAN"}]}`),
+			expectedError:       true,
+			expectedCode:        400,
+			expectedContentType: "application/json; charset=utf-8",
+			expectedBody:        `{"error":"party of 2 players: party is too big, max party size is 1"}`,
+			expectedMockCalls: func() {
+				srv.Ratings.(*rating.MockKeeper).EXPECT().GetRating(gomock.Any()).Times(2).Return(rating.Rating{}, false)
+				srv.Lobby.(*lobby.MockLobbier).EXPECT().
+					AddParty(gomock.Any()).
+					Times(1).
+					Return(fmt.Errorf("party of 2 players: %w, max party size is 1", lobby.ErrPartyTooBig))
+			},
+		},
+		{
+			name:                "member is already waiting",
+			req:                 []byte(`{"players": [{"player_id": "player1", "level": 1, "country": "USA"}, {"player_id": "player2", "level": 3, "country": "CAN"}]}`),
+			expectedError:       true,
+			expectedCode:        409,
+			expectedContentType: "application/json; charset=utf-8",
+			expectedBody:        `{"error":"player player2: player is already waiting in the lobby"}`,
+			expectedMockCalls: func() {
+				srv.Ratings.(*rating.MockKeeper).EXPECT().GetRating(gomock.Any()).Times(2).Return(rating.Rating{}, false)
+				srv.Lobby.(*lobby.MockLobbier).EXPECT().
+					AddParty(gomock.Any()).
+					Times(1).
+					Return(fmt.Errorf("player player2: %w", lobby.ErrDuplicatePlayer))
+			},
+		},
+		{
+			name:                "lobby is draining",
+			req:                 []byte(`{"players": [{"player_id": "player1", "level": 1, "country": "USA"}, {"player_id": "player2", "level": 3, "country": "CAN"}]}`),
+			expectedError:       true,
+			expectedCode:        503,
+			expectedContentType: "application/json; charset=utf-8",
+			expectedBody:        `{"error":"the lobby is shutting down, try again later"}`,
+			expectedMockCalls: func() {
+				srv.Ratings.(*rating.MockKeeper).EXPECT().GetRating(gomock.Any()).Times(2).Return(rating.Rating{}, false)
+				srv.Lobby.(*lobby.MockLobbier).EXPECT().
+					AddParty(gomock.Any()).
+					Times(1).
+					Return(lobby.ErrDraining)
+			},
+		},
+		{
+			name:                "not valid request: same player twice",
+			req:                 []byte(`{"players": [{"player_id": "player1", "level": 1, "country": "USA"}, {"player_id": "player1", "level": 1, "country": "USA"}]}`),
+			expectedError:       true,
+			expectedCode:        400,
+			expectedContentType: "application/json; charset=utf-8",
+			expectedBody:        `{"error":"player player1 is in the party more than once"}`,
+			expectedMockCalls: func() {
+				srv.Ratings.(*rating.MockKeeper).EXPECT().GetRating("player1").Times(1).Return(rating.Rating{}, false)
+			},
+		},
+		{
+			name:                "not valid request: single player",
+			req:                 []byte(`{"players": [{"player_id": "player1", "level": 1, "country": "USA"}]}`),
+			expectedError:       true,
+			expectedCode:        400,
+			expectedContentType: "application/json; charset=utf-8",
+			expectedBody:        `{"error":"Key: 'PartyRequest.Players' Error:Field validation for 'Players' failed on the 'min' tag"}`,
+			expectedMockCalls:   func() {},
+		},
+		{
+			name:                "not valid request: incorrect country code of a member",
+			req:                 []byte(`{"players": [{"player_id": "player1", "level": 1, "country": "USA"}, {"player_id": "player2", "level": 1, "country": "US"}]}`),
+			expectedError:       true,
+			expectedCode:        400,
+			expectedContentType: "application/json; charset=utf-8",
+			expectedBody:        `{"error":"Key: 'PartyRequest.Players[1].Country' Error:Field validation for 'Country' failed on the 'isocountry' tag"}`,
+			expectedMockCalls:   func() {},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.expectedMockCalls()
+			recorder := httptest.NewRecorder()
+
+			req, err := http.NewRequest(http.MethodPost, "/lobby/party", bytes.NewReader(tt.req))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			req.Header.Set("Content-Type", "application/json")
+
+			srv.GinEngine.ServeHTTP(recorder, req)
+
+			if recorder.Code != tt.expectedCode {
+				t.Errorf("expected code %d, got %d", tt.expectedCode, recorder.Code)
+			}
+
+			if recorder.Header().Get("Content-Type") != tt.expectedContentType {
+				t.Errorf("expected content type %s, got %s", tt.expectedContentType, recorder.Header().Get("Content-Type"))
+			}
+
+			if !tt.expectedError {
+				var partyResponse PartyResponse
+				if err := json.Unmarshal(recorder.Body.Bytes(), &partyResponse); err != nil {
+					t.Fatal(err)
+				}
+
+				if _, err := uuid.Parse(partyResponse.PartyID); err != nil {
+					t.Errorf("party_id is not valid UUID: '%s'", partyResponse.PartyID)
+				}
+
+				var expectedResponse PartyResponse
+				if err := json.Unmarshal([]byte(tt.expectedBody), &expectedResponse); err != nil {
+					t.Fatal(err)
+				}
+
+				if len(partyResponse.Members) != len(expectedResponse.Members) {
+					t.Fatalf("expected %d members, got %d", len(expectedResponse.Members), len(partyResponse.Members))
+				}
+
+				for i, member := range partyResponse.Members {
+					if _, err := uuid.Parse(member.JoinID); err != nil {
+						t.Errorf("join_id of %s is not valid UUID: '%s'", member.PlayerID, member.JoinID)
+					}
+
+					expected := expectedResponse.Members[i]
+					if member.PlayerID != expected.PlayerID || member.EstimatedWait != expected.EstimatedWait {
+						t.Errorf("expected member %s to wait %v, got %s waiting %v", expected.PlayerID, expected.EstimatedWait, member.PlayerID, member.EstimatedWait)
+					}
+				}
+			} else {
+				if recorder.Body.String() != tt.expectedBody {
+					t.Errorf("expected body '%s', got '%s'", tt.expectedBody, recorder.Body.String())
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,36 @@
+package apiserver
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/TanyEm/match-maker/v2/internal/lobby"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type LeaveLobbyResponse struct {
+	JoinID string            `json:"join_id"`
+	Status lobby.TicketState `json:"status"`
+}
+
+// LeaveLobby cancels the lobby ticket of the player who has not been matched yet
+func (s *APIServer) LeaveLobby(ctx *gin.Context) {
+	joinID := ctx.Param("join_id")
+	if _, err := uuid.Parse(joinID); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "join_id is not valid UUID"})
+		return
+	}
+
+	err := s.Lobby.RemovePlayer(joinID)
+	if errors.Is(err, lobby.ErrAlreadyMatched) {
+		ctx.JSON(http.StatusConflict, gin.H{"error": "the player is already matched"})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "the player is not waiting in the lobby"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, LeaveLobbyResponse{JoinID: joinID, Status: lobby.TicketCancelled})
+}
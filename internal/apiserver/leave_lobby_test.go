@@ -0,0 +1,125 @@
+package apiserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/TanyEm/match-maker/v2/internal/lobby"
+	"github.com/TanyEm/match-maker/v2/internal/match"
+	"github.com/TanyEm/match-maker/v2/internal/rating"
+	"github.com/google/go-cmp/cmp"
+	"go.uber.org/mock/gomock"
+)
+
+func TestLeaveLobby(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	srv := NewAPIServer(lobby.NewMockLobbier(ctrl), match.NewMockKeeper(ctrl), rating.NewMockKeeper(ctrl))
+
+	tests := []struct {
+		name                string
+		reqURL              string
+		expectedError       bool
+		expectedCode        int
+		expectedContentType string
+		expectedBody        string
+		expectedMockCalls   func()
+	}{
+		{
+			name:                "valid request",
+			reqURL:              "/lobby/72b33e85-e8cd-45e6-89f4-25bfdac584d8",
+			expectedError:       false,
+			expectedCode:        200,
+			expectedContentType: "application/json; charset=utf-8",
+			expectedBody:        `{"join_id":"72b33e85-e8cd-45e6-89f4-25bfdac584d8","status":"cancelled"}`,
+			expectedMockCalls: func() {
+				srv.Lobby.(*lobby.MockLobbier).EXPECT().
+					RemovePlayer("72b33e85-e8cd-45e6-89f4-25bfdac584d8").
+					Times(1).
+					Return(nil)
+			},
+		},
+		{
+			name:                "valid request but player is already matched",
+			reqURL:              "/lobby/72b33e85-e8cd-45e6-89f4-25bfdac584d8",
+			expectedError:       true,
+			expectedCode:        409,
+			expectedContentType: "application/json; charset=utf-8",
+			expectedBody:        `{"error":"the player is already matched"}`,
+			expectedMockCalls: func() {
+				srv.Lobby.(*lobby.MockLobbier).EXPECT().
+					RemovePlayer("72b33e85-e8cd-45e6-89f4-25bfdac584d8").
+					Times(1).
+					Return(lobby.ErrAlreadyMatched)
+			},
+		},
+		{
+			name:                "valid request but player is not in the lobby",
+			reqURL:              "/lobby/72b33e85-e8cd-45e6-89f4-25bfdac584d8",
+			expectedError:       true,
+			expectedCode:        404,
+			expectedContentType: "application/json; charset=utf-8",
+			expectedBody:        `{"error":"the player is not waiting in the lobby"}`,
+			expectedMockCalls: func() {
+				srv.Lobby.(*lobby.MockLobbier).EXPECT().
+					RemovePlayer("72b33e85-e8cd-45e6-89f4-25bfdac584d8").
+					Times(1).
+					Return(lobby.ErrTicketNotFound)
+			},
+		},
+		{
+			name:                "not valid request: join_id is not valid UUID",
+			reqURL:              "/lobby/not-valid-uuid",
+			expectedError:       true,
+			expectedCode:        400,
+			expectedContentType: "application/json; charset=utf-8",
+			expectedBody:        `{"error":"join_id is not valid UUID"}`,
+			expectedMockCalls:   func() {},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.expectedMockCalls()
+			recorder := httptest.NewRecorder()
+
+			req, err := http.NewRequest(http.MethodDelete, tt.reqURL, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			srv.GinEngine.ServeHTTP(recorder, req)
+
+			if recorder.Code != tt.expectedCode {
+				t.Errorf("expected code %d, got %d", tt.expectedCode, recorder.Code)
+			}
+
+			if recorder.Header().Get("Content-Type") != tt.expectedContentType {
+				t.Errorf("expected content type %s, got %s", tt.expectedContentType, recorder.Header().Get("Content-Type"))
+			}
+
+			if !tt.expectedError {
+				var gotResponse LeaveLobbyResponse
+				if err := json.Unmarshal(recorder.Body.Bytes(), &gotResponse); err != nil {
+					t.Fatal(err)
+				}
+
+				var expectedResponse LeaveLobbyResponse
+				if err := json.Unmarshal([]byte(tt.expectedBody), &expectedResponse); err != nil {
+					t.Fatal(err)
+				}
+
+				if !cmp.Equal(gotResponse, expectedResponse) {
+					t.Errorf("expected response '%v', got '%v'", expectedResponse, gotResponse)
+				}
+			} else {
+				if recorder.Body.String() != tt.expectedBody {
+					t.Errorf("expected body '%s', got '%s'", tt.expectedBody, recorder.Body.String())
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,23 @@
+package apiserver
+
+import (
+	"encoding/json"
+	"expvar"
+	"net/http"
+
+	"github.com/TanyEm/match-maker/v2/internal/lobby"
+	"github.com/gin-gonic/gin"
+)
+
+// GetMetrics returns the expvar metrics of the lobbies. The other expvar variables,
+// e.g. cmdline and memstats, are not published.
+func (s *APIServer) GetMetrics(ctx *gin.Context) {
+	metrics := gin.H{}
+	for _, name := range []string{lobby.TicketsMetric, lobby.PrunedTicketsMetric} {
+		if v := expvar.Get(name); v != nil {
+			metrics[name] = json.RawMessage(v.String())
+		}
+	}
+
+	ctx.JSON(http.StatusOK, metrics)
+}
@@ -0,0 +1,48 @@
+package apiserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/TanyEm/match-maker/v2/internal/lobby"
+	"github.com/TanyEm/match-maker/v2/internal/match"
+	"github.com/TanyEm/match-maker/v2/internal/rating"
+	"go.uber.org/mock/gomock"
+)
+
+func TestGetMetrics(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	srv := NewAPIServer(lobby.NewMockLobbier(ctrl), match.NewMockKeeper(ctrl), rating.NewMockKeeper(ctrl))
+
+	recorder := httptest.NewRecorder()
+	req, err := http.NewRequest(http.MethodGet, "/debug/vars", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	srv.GinEngine.ServeHTTP(recorder, req)
+
+	if recorder.Code != http.StatusOK {
+		t.Errorf("expected code %d, got %d", http.StatusOK, recorder.Code)
+	}
+
+	var gotResponse map[string]map[string]int
+	if err := json.Unmarshal(recorder.Body.Bytes(), &gotResponse); err != nil {
+		t.Fatal(err)
+	}
+
+	// Only the lobby metrics are published, not cmdline or memstats
+	if len(gotResponse) != 2 {
+		t.Errorf("expected the 2 lobby metrics, got '%s'", recorder.Body.String())
+	}
+
+	for _, name := range []string{lobby.TicketsMetric, lobby.PrunedTicketsMetric} {
+		if _, ok := gotResponse[name]; !ok {
+			t.Errorf("expected metric %s, got '%s'", name, recorder.Body.String())
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package apiserver
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/TanyEm/match-maker/v2/internal/lobby"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// ReadyRequest accepts or declines the match found for the player
+type ReadyRequest struct {
+	Accept *bool `json:"accept" binding:"required"`
+}
+
+type ReadyResponse struct {
+	JoinID string            `json:"join_id"`
+	Status lobby.TicketState `json:"status"`
+	// MatchID is the started match once all its players have accepted it
+	MatchID string `json:"match_id,omitempty"`
+}
+
+// Ready accepts or declines the match of the ticket in the ready check
+func (s *APIServer) Ready(ctx *gin.Context) {
+	joinID := ctx.Param("join_id")
+	if _, err := uuid.Parse(joinID); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "join_id is not valid UUID"})
+		return
+	}
+
+	var req ReadyRequest
+	if err := ctx.BindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := s.Lobby.Ready(joinID, *req.Accept); err != nil {
+		if errors.Is(err, lobby.ErrNoReadyCheck) {
+			ctx.JSON(http.StatusConflict, gin.H{"error": "the lobby ticket is not in a ready check"})
+			return
+		}
+
+		ticketNotFound(ctx, err)
+		return
+	}
+
+	ticket, err := s.Lobby.GetTicket(joinID)
+	if err != nil {
+		ticketNotFound(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, ReadyResponse{JoinID: joinID, Status: ticket.State, MatchID: ticket.MatchID})
+}
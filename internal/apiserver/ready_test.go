@@ -0,0 +1,184 @@
+package apiserver
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/TanyEm/match-maker/v2/internal/lobby"
+	"github.com/TanyEm/match-maker/v2/internal/match"
+	"github.com/TanyEm/match-maker/v2/internal/rating"
+	"github.com/google/go-cmp/cmp"
+	"go.uber.org/mock/gomock"
+)
+
+func TestReady(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	srv := NewAPIServer(lobby.NewMockLobbier(ctrl), match.NewMockKeeper(ctrl), rating.NewMockKeeper(ctrl))
+
+	const joinID = "72b33e85-e8cd-45e6-89f4-25bfdac584d8"
+
+	tests := []struct {
+		name                string
+		reqURL              string
+		req                 []byte
+		expectedError       bool
+		expectedCode        int
+		expectedContentType string
+		expectedBody        string
+		expectedMockCalls   func()
+	}{
+		{
+			name:                "accepted",
+			reqURL:              "/lobby/" + joinID + "/ready",
+			req:                 []byte(`{"accept": true}`),
+			expectedError:       false,
+			expectedCode:        200,
+			expectedContentType: "application/json; charset=utf-8",
+			expectedBody:        `{"join_id":"72b33e85-e8cd-45e6-89f4-25bfdac584d8","status":"ready_check"}`,
+			expectedMockCalls: func() {
+				srv.Lobby.(*lobby.MockLobbier).EXPECT().
+					Ready(joinID, true).
+					Times(1)
+				srv.Lobby.(*lobby.MockLobbier).EXPECT().
+					GetTicket(joinID).
+					Times(1).
+					Return(lobby.Ticket{JoinID: joinID, State: lobby.TicketReadyCheck, Accepted: true}, nil)
+			},
+		},
+		{
+			name:                "accepted by the last player",
+			reqURL:              "/lobby/" + joinID + "/ready",
+			req:                 []byte(`{"accept": true}`),
+			expectedError:       false,
+			expectedCode:        200,
+			expectedContentType: "application/json; charset=utf-8",
+			expectedBody:        `{"join_id":"72b33e85-e8cd-45e6-89f4-25bfdac584d8","status":"matched","match_id":"d1b18698-f7eb-4cb1-b7f2-97e4b44c2c1d"}`,
+			expectedMockCalls: func() {
+				srv.Lobby.(*lobby.MockLobbier).EXPECT().
+					Ready(joinID, true).
+					Times(1)
+				srv.Lobby.(*lobby.MockLobbier).EXPECT().
+					GetTicket(joinID).
+					Times(1).
+					Return(lobby.Ticket{JoinID: joinID, State: lobby.TicketMatched, MatchID: "d1b18698-f7eb-4cb1-b7f2-97e4b44c2c1d"}, nil)
+			},
+		},
+		{
+			name:                "declined",
+			reqURL:              "/lobby/" + joinID + "/ready",
+			req:                 []byte(`{"accept": false}`),
+			expectedError:       false,
+			expectedCode:        200,
+			expectedContentType: "application/json; charset=utf-8",
+			expectedBody:        `{"join_id":"72b33e85-e8cd-45e6-89f4-25bfdac584d8","status":"cancelled"}`,
+			expectedMockCalls: func() {
+				srv.Lobby.(*lobby.MockLobbier).EXPECT().
+					Ready(joinID, false).
+					Times(1)
+				srv.Lobby.(*lobby.MockLobbier).EXPECT().
+					GetTicket(joinID).
+					Times(1).
+					Return(lobby.Ticket{JoinID: joinID, State: lobby.TicketCancelled, Reason: lobby.ReasonDeclined}, nil)
+			},
+		},
+		{
+			name:                "ticket is not in a ready check",
+			reqURL:              "/lobby/" + joinID + "/ready",
+			req:                 []byte(`{"accept": true}`),
+			expectedError:       true,
+			expectedCode:        409,
+			expectedContentType: "application/json; charset=utf-8",
+			expectedBody:        `{"error":"the lobby ticket is not in a ready check"}`,
+			expectedMockCalls: func() {
+				srv.Lobby.(*lobby.MockLobbier).EXPECT().
+					Ready(joinID, true).
+					Times(1).
+					Return(lobby.ErrNoReadyCheck)
+			},
+		},
+		{
+			name:                "ticket not found",
+			reqURL:              "/lobby/" + joinID + "/ready",
+			req:                 []byte(`{"accept": true}`),
+			expectedError:       true,
+			expectedCode:        404,
+			expectedContentType: "application/json; charset=utf-8",
+			expectedBody:        `{"error":"ticket not found"}`,
+			expectedMockCalls: func() {
+				srv.Lobby.(*lobby.MockLobbier).EXPECT().
+					Ready(joinID, true).
+					Times(1).
+					Return(lobby.ErrTicketNotFound)
+			},
+		},
+		{
+			name:                "not valid request: empty accept",
+			reqURL:              "/lobby/" + joinID + "/ready",
+			req:                 []byte(`{}`),
+			expectedError:       true,
+			expectedCode:        400,
+			expectedContentType: "application/json; charset=utf-8",
+			expectedBody:        `{"error":"Key: 'ReadyRequest.Accept' Error:Field validation for 'Accept' failed on the 'required' tag"}`,
+			expectedMockCalls:   func() {},
+		},
+		{
+			name:                "not valid request: join_id is not valid UUID",
+			reqURL:              "/lobby/not-valid-uuid/ready",
+			req:                 []byte(`{"accept": true}`),
+			expectedError:       true,
+			expectedCode:        400,
+			expectedContentType: "application/json; charset=utf-8",
+			expectedBody:        `{"error":"join_id is not valid UUID"}`,
+			expectedMockCalls:   func() {},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.expectedMockCalls()
+			recorder := httptest.NewRecorder()
+
+			req, err := http.NewRequest(http.MethodPost, tt.reqURL, bytes.NewReader(tt.req))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			req.Header.Set("Content-Type", "application/json")
+
+			srv.GinEngine.ServeHTTP(recorder, req)
+
+			if recorder.Code != tt.expectedCode {
+				t.Errorf("expected code %d, got %d", tt.expectedCode, recorder.Code)
+			}
+
+			if recorder.Header().Get("Content-Type") != tt.expectedContentType {
+				t.Errorf("expected content type %s, got %s", tt.expectedContentType, recorder.Header().Get("Content-Type"))
+			}
+
+			if !tt.expectedError {
+				var gotResponse ReadyResponse
+				if err := json.Unmarshal(recorder.Body.Bytes(), &gotResponse); err != nil {
+					t.Fatal(err)
+				}
+
+				var expectedResponse ReadyResponse
+				if err := json.Unmarshal([]byte(tt.expectedBody), &expectedResponse); err != nil {
+					t.Fatal(err)
+				}
+
+				if !cmp.Equal(gotResponse, expectedResponse) {
+					t.Errorf("expected response '%v', got '%v'", expectedResponse, gotResponse)
+				}
+			} else {
+				if recorder.Body.String() != tt.expectedBody {
+					t.Errorf("expected body '%s', got '%s'", tt.expectedBody, recorder.Body.String())
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package apiserver
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/TanyEm/match-maker/v2/internal/rating"
+	"github.com/gin-gonic/gin"
+)
+
+// ReportResultRequest is a result of a finished match reported by a game server.
+// The winner has place 1, players with the same place are tied.
+type ReportResultRequest struct {
+	MatchID   string            `json:"match_id" binding:"required,uuid"`
+	Standings []StandingRequest `json:"standings" binding:"required,min=2,dive"`
+}
+
+type StandingRequest struct {
+	PlayerID string `json:"player_id" binding:"required"`
+	Place    int    `json:"place" binding:"min=1"`
+}
+
+type ReportResultResponse struct {
+	Ratings []rating.Rating `json:"ratings"`
+}
+
+func (s *APIServer) ReportResult(ctx *gin.Context) {
+	var req ReportResultRequest
+	if err := ctx.BindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	leaderBoard := s.MatchKeeper.GetLeaderBoard(req.MatchID)
+	if leaderBoard == nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "match not found"})
+		return
+	}
+
+	// The levels of the players are used as the initial ratings of the players who have no rating yet
+	levels := make(map[string]int, len(leaderBoard.Players))
+	for _, p := range leaderBoard.Players {
+		levels[p.PlayerID] = p.Level
+	}
+
+	standings := make([]rating.Standing, 0, len(req.Standings))
+	reported := make(map[string]struct{}, len(req.Standings))
+	for _, standing := range req.Standings {
+		level, ok := levels[standing.PlayerID]
+		if !ok {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("player %s did not play in the match", standing.PlayerID)})
+			return
+		}
+
+		if _, ok := reported[standing.PlayerID]; ok {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("player %s is reported more than once", standing.PlayerID)})
+			return
+		}
+		reported[standing.PlayerID] = struct{}{}
+
+		standings = append(standings, rating.Standing{
+			PlayerID: standing.PlayerID,
+			Level:    level,
+			Place:    standing.Place,
+		})
+	}
+
+	ratings, err := s.Ratings.ReportResult(req.MatchID, standings)
+	if errors.Is(err, rating.ErrAlreadyReported) {
+		ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// The new ratings rank the players on the global leaderboards
+	updated := make(map[string]float64, len(ratings))
+	for _, r := range ratings {
+		updated[r.PlayerID] = r.Rating
+	}
+
+	if err := s.MatchKeeper.UpdateRatings(req.MatchID, updated); err != nil {
+		log.Printf("Failed to rank the ratings of match %s: %v", req.MatchID, err)
+	}
+
+	ctx.JSON(http.StatusOK, ReportResultResponse{Ratings: ratings})
+}
@@ -0,0 +1,216 @@
+package apiserver
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/TanyEm/match-maker/v2/internal/lobby"
+	"github.com/TanyEm/match-maker/v2/internal/match"
+	"github.com/TanyEm/match-maker/v2/internal/rating"
+	"github.com/google/go-cmp/cmp"
+	"go.uber.org/mock/gomock"
+)
+
+func TestReportResult(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	srv := NewAPIServer(lobby.NewMockLobbier(ctrl), match.NewMockKeeper(ctrl), rating.NewMockKeeper(ctrl))
+	srv.AddGameServer("secret")
+
+	const matchID = "72b33e85-e8cd-45e6-89f4-25bfdac584d8"
+	leaderBoard := &match.LeaderBoard{
+		MatchID: matchID,
+		Players: []match.PlayerInfo{
+			{PlayerID: "player1", Level: 1, Country: "USA"},
+			{PlayerID: "player2", Level: 2, Country: "USA"},
+		},
+	}
+
+	tests := []struct {
+		name                string
+		req                 []byte
+		authorization       string
+		expectedError       bool
+		expectedCode        int
+		expectedContentType string
+		expectedBody        string
+		expectedMockCalls   func()
+	}{
+		{
+			name:                "valid request",
+			req:                 []byte(`{"match_id": "` + matchID + `", "standings": [{"player_id": "player1", "place": 1}, {"player_id": "player2", "place": 2}]}`),
+			authorization:       "Bearer secret",
+			expectedError:       false,
+			expectedCode:        200,
+			expectedContentType: "application/json; charset=utf-8",
+			expectedBody: `{"ratings":[
+								{"player_id":"player1","rating":1036,"deviation":0,"volatility":0,"matches":1},
+								{"player_id":"player2","rating":1004,"deviation":0,"volatility":0,"matches":1}
+							]}`,
+			expectedMockCalls: func() {
+				srv.MatchKeeper.(*match.MockKeeper).EXPECT().
+					GetLeaderBoard(matchID).
+					Times(1).
+					Return(leaderBoard)
+				srv.Ratings.(*rating.MockKeeper).EXPECT().
+					ReportResult(matchID, []rating.Standing{
+						{PlayerID: "player1", Level: 1, Place: 1},
+						{PlayerID: "player2", Level: 2, Place: 2},
+					}).
+					Times(1).
+					Return([]rating.Rating{
+						{PlayerID: "player1", Rating: 1036, Matches: 1},
+						{PlayerID: "player2", Rating: 1004, Matches: 1},
+					}, nil)
+				srv.MatchKeeper.(*match.MockKeeper).EXPECT().
+					UpdateRatings(matchID, map[string]float64{"player1": 1036, "player2": 1004}).
+					Times(1)
+			},
+		},
+		{
+			name:                "already reported",
+			req:                 []byte(`{"match_id": "` + matchID + `", "standings": [{"player_id": "player1", "place": 1}, {"player_id": "player2", "place": 2}]}`),
+			authorization:       "Bearer secret",
+			expectedError:       true,
+			expectedCode:        409,
+			expectedContentType: "application/json; charset=utf-8",
+			expectedBody:        `{"error":"match ` + matchID + `: result of the match is already reported"}`,
+			expectedMockCalls: func() {
+				srv.MatchKeeper.(*match.MockKeeper).EXPECT().
+					GetLeaderBoard(matchID).
+					Times(1).
+					Return(leaderBoard)
+				srv.Ratings.(*rating.MockKeeper).EXPECT().
+					ReportResult(matchID, gomock.Any()).
+					Times(1).
+					Return(nil, fmt.Errorf("match %s: %w", matchID, rating.ErrAlreadyReported))
+			},
+		},
+		{
+			name:                "match not found",
+			req:                 []byte(`{"match_id": "` + matchID + `", "standings": [{"player_id": "player1", "place": 1}, {"player_id": "player2", "place": 2}]}`),
+			authorization:       "Bearer secret",
+			expectedError:       true,
+			expectedCode:        404,
+			expectedContentType: "application/json; charset=utf-8",
+			expectedBody:        `{"error":"match not found"}`,
+			expectedMockCalls: func() {
+				srv.MatchKeeper.(*match.MockKeeper).EXPECT().
+					GetLeaderBoard(matchID).
+					Times(1).
+					Return(nil)
+			},
+		},
+		{
+			name:                "not valid request: player did not play in the match",
+			req:                 []byte(`{"match_id": "` + matchID + `", "standings": [{"player_id": "player1", "place": 1}, {"player_id": "player3", "place": 2}]}`),
+			authorization:       "Bearer secret",
+			expectedError:       true,
+			expectedCode:        400,
+			expectedContentType: "application/json; charset=utf-8",
+			expectedBody:        `{"error":"player player3 did not play in the match"}`,
+			expectedMockCalls: func() {
+				srv.MatchKeeper.(*match.MockKeeper).EXPECT().
+					GetLeaderBoard(matchID).
+					Times(1).
+					Return(leaderBoard)
+			},
+		},
+		{
+			name:                "not valid request: player reported twice",
+			req:                 []byte(`{"match_id": "` + matchID + `", "standings": [{"player_id": "player1", "place": 1}, {"player_id": "player1", "place": 2}]}`),
+			authorization:       "Bearer secret",
+			expectedError:       true,
+			expectedCode:        400,
+			expectedContentType: "application/json; charset=utf-8",
+			expectedBody:        `{"error":"player player1 is reported more than once"}`,
+			expectedMockCalls: func() {
+				srv.MatchKeeper.(*match.MockKeeper).EXPECT().
+					GetLeaderBoard(matchID).
+					Times(1).
+					Return(leaderBoard)
+			},
+		},
+		{
+			name:                "not valid request: single standing",
+			req:                 []byte(`{"match_id": "` + matchID + `", "standings": [{"player_id": "player1", "place": 1}]}`),
+			authorization:       "Bearer secret",
+			expectedError:       true,
+			expectedCode:        400,
+			expectedContentType: "application/json; charset=utf-8",
+			expectedBody:        `{"error":"Key: 'ReportResultRequest.Standings' Error:Field validation for 'Standings' failed on the 'min' tag"}`,
+			expectedMockCalls:   func() {},
+		},
+		{
+			name:                "not valid request: match_id is not valid UUID",
+			req:                 []byte(`{"match_id": "not-valid-uuid", "standings": [{"player_id": "player1", "place": 1}, {"player_id": "player2", "place": 2}]}`),
+			authorization:       "Bearer secret",
+			expectedError:       true,
+			expectedCode:        400,
+			expectedContentType: "application/json; charset=utf-8",
+			expectedBody:        `{"error":"Key: 'ReportResultRequest.MatchID' Error:Field validation for 'MatchID' failed on the 'uuid' tag"}`,
+			expectedMockCalls:   func() {},
+		},
+		{
+			name:                "no token",
+			req:                 []byte(`{"match_id": "` + matchID + `", "standings": [{"player_id": "player1", "place": 1}, {"player_id": "player2", "place": 2}]}`),
+			expectedError:       true,
+			expectedCode:        401,
+			expectedContentType: "application/json; charset=utf-8",
+			expectedBody:        `{"error":"the bearer token is missing or wrong"}`,
+			expectedMockCalls:   func() {},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.expectedMockCalls()
+			recorder := httptest.NewRecorder()
+
+			req, err := http.NewRequest(http.MethodPost, "/results", bytes.NewReader(tt.req))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			req.Header.Set("Content-Type", "application/json")
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+
+			srv.GinEngine.ServeHTTP(recorder, req)
+
+			if recorder.Code != tt.expectedCode {
+				t.Errorf("expected code %d, got %d", tt.expectedCode, recorder.Code)
+			}
+
+			if recorder.Header().Get("Content-Type") != tt.expectedContentType {
+				t.Errorf("expected content type %s, got %s", tt.expectedContentType, recorder.Header().Get("Content-Type"))
+			}
+
+			if !tt.expectedError {
+				var gotResponse ReportResultResponse
+				if err := json.Unmarshal(recorder.Body.Bytes(), &gotResponse); err != nil {
+					t.Fatal(err)
+				}
+
+				var expectedResponse ReportResultResponse
+				if err := json.Unmarshal([]byte(tt.expectedBody), &expectedResponse); err != nil {
+					t.Fatal(err)
+				}
+
+				if !cmp.Equal(gotResponse, expectedResponse) {
+					t.Errorf("expected response '%v', got '%v'", expectedResponse, gotResponse)
+				}
+			} else {
+				if recorder.Body.String() != tt.expectedBody {
+					t.Errorf("expected body '%s', got '%s'", tt.expectedBody, recorder.Body.String())
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package apiserver
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/TanyEm/match-maker/v2/internal/match"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// ReportScoresRequest is the scores of the players in a match reported by a game server.
+// The scores are added to the current scores of the players during the match,
+// and the final scores replace them once the match is over.
+type ReportScoresRequest struct {
+	Scores []ScoreRequest `json:"scores" binding:"required,min=1,dive"`
+	Final  bool           `json:"final"`
+}
+
+type ScoreRequest struct {
+	PlayerID string `json:"player_id" binding:"required"`
+	Score    int    `json:"score"`
+}
+
+// ReportScores applies the scores of the players to the leaderboard of the match
+func (s *APIServer) ReportScores(ctx *gin.Context) {
+	matchID := ctx.Param("match_id")
+	if _, err := uuid.Parse(matchID); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "match_id is not valid UUID"})
+		return
+	}
+
+	var req ReportScoresRequest
+	if err := ctx.BindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	scores := make([]match.Score, 0, len(req.Scores))
+	reported := make(map[string]struct{}, len(req.Scores))
+	for _, score := range req.Scores {
+		if _, ok := reported[score.PlayerID]; ok {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("player %s is reported more than once", score.PlayerID)})
+			return
+		}
+		reported[score.PlayerID] = struct{}{}
+
+		scores = append(scores, match.Score{PlayerID: score.PlayerID, Score: score.Score})
+	}
+
+	leaderBoard, err := s.MatchKeeper.ReportScores(matchID, scores, req.Final)
+	if errors.Is(err, match.ErrMatchNotFound) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "match not found"})
+		return
+	}
+
+	if errors.Is(err, match.ErrScoresFinal) {
+		ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, GetLeaderBoardResponse{LeaderBoard: leaderBoard.Ranked()})
+}
@@ -0,0 +1,234 @@
+package apiserver
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/TanyEm/match-maker/v2/internal/lobby"
+	"github.com/TanyEm/match-maker/v2/internal/match"
+	"github.com/TanyEm/match-maker/v2/internal/rating"
+	"github.com/google/go-cmp/cmp"
+	"go.uber.org/mock/gomock"
+)
+
+func TestReportScores(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	srv := NewAPIServer(lobby.NewMockLobbier(ctrl), match.NewMockKeeper(ctrl), rating.NewMockKeeper(ctrl))
+	srv.AddGameServer("secret")
+
+	const matchID = "72b33e85-e8cd-45e6-89f4-25bfdac584d8"
+
+	tests := []struct {
+		name                string
+		reqURL              string
+		req                 []byte
+		authorization       string
+		expectedError       bool
+		expectedCode        int
+		expectedContentType string
+		expectedBody        string
+		expectedMockCalls   func()
+	}{
+		{
+			name:                "incremental scores",
+			reqURL:              "/match/" + matchID + "/scores",
+			req:                 []byte(`{"scores": [{"player_id": "player1", "score": 10}, {"player_id": "player2", "score": 30}]}`),
+			authorization:       "Bearer secret",
+			expectedError:       false,
+			expectedCode:        200,
+			expectedContentType: "application/json; charset=utf-8",
+			expectedBody: `{
+								"match_id":"72b33e85-e8cd-45e6-89f4-25bfdac584d8",
+								"players":[
+									{"player_id":"player2","level":2,"country":"USA","score":30,"rank":1},
+									{"player_id":"player1","level":1,"country":"USA","score":10,"rank":2}
+								]
+							}`,
+			expectedMockCalls: func() {
+				srv.MatchKeeper.(*match.MockKeeper).EXPECT().
+					ReportScores(matchID, []match.Score{{PlayerID: "player1", Score: 10}, {PlayerID: "player2", Score: 30}}, false).
+					Times(1).
+					Return(&match.LeaderBoard{
+						MatchID: matchID,
+						Players: []match.PlayerInfo{
+							{PlayerID: "player1", Level: 1, Country: "USA", Score: 10},
+							{PlayerID: "player2", Level: 2, Country: "USA", Score: 30},
+						},
+					}, nil)
+			},
+		},
+		{
+			name:                "final scores",
+			reqURL:              "/match/" + matchID + "/scores",
+			req:                 []byte(`{"scores": [{"player_id": "player1", "score": 50}], "final": true}`),
+			authorization:       "Bearer secret",
+			expectedError:       false,
+			expectedCode:        200,
+			expectedContentType: "application/json; charset=utf-8",
+			expectedBody: `{
+								"match_id":"72b33e85-e8cd-45e6-89f4-25bfdac584d8",
+								"players":[
+									{"player_id":"player1","level":1,"country":"USA","score":50,"rank":1},
+									{"player_id":"player2","level":2,"country":"USA","score":30,"rank":2}
+								],
+								"final":true
+							}`,
+			expectedMockCalls: func() {
+				srv.MatchKeeper.(*match.MockKeeper).EXPECT().
+					ReportScores(matchID, []match.Score{{PlayerID: "player1", Score: 50}}, true).
+					Times(1).
+					Return(&match.LeaderBoard{
+						MatchID: matchID,
+						Players: []match.PlayerInfo{
+							{PlayerID: "player1", Level: 1, Country: "USA", Score: 50},
+							{PlayerID: "player2", Level: 2, Country: "USA", Score: 30},
+						},
+						Final: true,
+					}, nil)
+			},
+		},
+		{
+			name:                "match not found",
+			reqURL:              "/match/" + matchID + "/scores",
+			req:                 []byte(`{"scores": [{"player_id": "player1", "score": 10}]}`),
+			authorization:       "Bearer secret",
+			expectedError:       true,
+			expectedCode:        404,
+			expectedContentType: "application/json; charset=utf-8",
+			expectedBody:        `{"error":"match not found"}`,
+			expectedMockCalls: func() {
+				srv.MatchKeeper.(*match.MockKeeper).EXPECT().
+					ReportScores(matchID, gomock.Any(), false).
+					Times(1).
+					Return(nil, match.ErrMatchNotFound)
+			},
+		},
+		{
+			name:                "final scores are already reported",
+			reqURL:              "/match/" + matchID + "/scores",
+			req:                 []byte(`{"scores": [{"player_id": "player1", "score": 10}]}`),
+			authorization:       "Bearer secret",
+			expectedError:       true,
+			expectedCode:        409,
+			expectedContentType: "application/json; charset=utf-8",
+			expectedBody:        `{"error":"final scores of the match are already reported"}`,
+			expectedMockCalls: func() {
+				srv.MatchKeeper.(*match.MockKeeper).EXPECT().
+					ReportScores(matchID, gomock.Any(), false).
+					Times(1).
+					Return(nil, match.ErrScoresFinal)
+			},
+		},
+		{
+			name:                "player did not play in the match",
+			reqURL:              "/match/" + matchID + "/scores",
+			req:                 []byte(`{"scores": [{"player_id": "player3", "score": 10}]}`),
+			authorization:       "Bearer secret",
+			expectedError:       true,
+			expectedCode:        400,
+			expectedContentType: "application/json; charset=utf-8",
+			expectedBody:        `{"error":"player player3: player did not play in the match"}`,
+			expectedMockCalls: func() {
+				srv.MatchKeeper.(*match.MockKeeper).EXPECT().
+					ReportScores(matchID, gomock.Any(), false).
+					Times(1).
+					Return(nil, fmt.Errorf("player player3: %w", match.ErrPlayerNotInMatch))
+			},
+		},
+		{
+			name:                "not valid request: same player twice",
+			reqURL:              "/match/" + matchID + "/scores",
+			req:                 []byte(`{"scores": [{"player_id": "player1", "score": 10}, {"player_id": "player1", "score": 5}]}`),
+			authorization:       "Bearer secret",
+			expectedError:       true,
+			expectedCode:        400,
+			expectedContentType: "application/json; charset=utf-8",
+			expectedBody:        `{"error":"player player1 is reported more than once"}`,
+			expectedMockCalls:   func() {},
+		},
+		{
+			name:                "not valid request: no scores",
+			reqURL:              "/match/" + matchID + "/scores",
+			req:                 []byte(`{"scores": []}`),
+			authorization:       "Bearer secret",
+			expectedError:       true,
+			expectedCode:        400,
+			expectedContentType: "application/json; charset=utf-8",
+			expectedBody:        `{"error":"Key: 'ReportScoresRequest.Scores' Error:Field validation for 'Scores' failed on the 'min' tag"}`,
+			expectedMockCalls:   func() {},
+		},
+		{
+			name:                "not valid request: match_id is not valid UUID",
+			reqURL:              "/match/not-valid-uuid/scores",
+			req:                 []byte(`{"scores": [{"player_id": "player1", "score": 10}]}`),
+			authorization:       "Bearer secret",
+			expectedError:       true,
+			expectedCode:        400,
+			expectedContentType: "application/json; charset=utf-8",
+			expectedBody:        `{"error":"match_id is not valid UUID"}`,
+			expectedMockCalls:   func() {},
+		},
+		{
+			name:                "wrong token",
+			reqURL:              "/match/" + matchID + "/scores",
+			req:                 []byte(`{"scores": [{"player_id": "player1", "score": 10}]}`),
+			authorization:       "Bearer wrong",
+			expectedError:       true,
+			expectedCode:        401,
+			expectedContentType: "application/json; charset=utf-8",
+			expectedBody:        `{"error":"the bearer token is missing or wrong"}`,
+			expectedMockCalls:   func() {},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.expectedMockCalls()
+			recorder := httptest.NewRecorder()
+
+			req, err := http.NewRequest(http.MethodPost, tt.reqURL, bytes.NewReader(tt.req))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Authorization", tt.authorization)
+
+			srv.GinEngine.ServeHTTP(recorder, req)
+
+			if recorder.Code != tt.expectedCode {
+				t.Errorf("expected code %d, got %d", tt.expectedCode, recorder.Code)
+			}
+
+			if recorder.Header().Get("Content-Type") != tt.expectedContentType {
+				t.Errorf("expected content type %s, got %s", tt.expectedContentType, recorder.Header().Get("Content-Type"))
+			}
+
+			if !tt.expectedError {
+				var gotResponse GetLeaderBoardResponse
+				if err := json.Unmarshal(recorder.Body.Bytes(), &gotResponse); err != nil {
+					t.Fatal(err)
+				}
+
+				var expectedResponse GetLeaderBoardResponse
+				if err := json.Unmarshal([]byte(tt.expectedBody), &expectedResponse); err != nil {
+					t.Fatal(err)
+				}
+
+				if !cmp.Equal(gotResponse, expectedResponse) {
+					t.Errorf("expected response '%v', got '%v'", expectedResponse, gotResponse)
+				}
+			} else {
+				if recorder.Body.String() != tt.expectedBody {
+					t.Errorf("expected body '%s', got '%s'", tt.expectedBody, recorder.Body.String())
+				}
+			}
+		})
+	}
+}
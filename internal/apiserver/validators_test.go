@@ -4,6 +4,7 @@ import (
 	"reflect"
 	"testing"
 
+	"github.com/TanyEm/match-maker/v2/internal/region"
 	"github.com/go-playground/validator/v10"
 )
 
@@ -38,3 +39,11 @@ func TestISOCountryValidator(t *testing.T) {
 		})
 	}
 }
+
+func TestISOCountriesHaveRegion(t *testing.T) {
+	for _, country := range ISOCountries {
+		if _, ok := region.Of(country); !ok {
+			t.Errorf("country %s has no region", country)
+		}
+	}
+}
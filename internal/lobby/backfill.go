@@ -0,0 +1,240 @@
+package lobby
+
+import (
+	"errors"
+	"log"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/TanyEm/match-maker/v2/internal/match"
+	"github.com/TanyEm/match-maker/v2/internal/player"
+)
+
+var (
+	ErrNoOpenSlots      = errors.New("backfill has no open slots")
+	ErrBackfillNotFound = errors.New("backfill is not found")
+)
+
+// Backfill is a request of a started match for players to replace the players who dropped from the match.
+// The open slots are filled from the players waiting in the lobby before they are placed into new matches.
+type Backfill struct {
+	MatchID string
+	// Queue is the queue of the match, the default queue if it is empty
+	Queue string
+	// Slots is the number of the open slots of the match
+	Slots int
+	// Countries are the countries the players must come from, any country if it is empty
+	Countries []string
+	// MinLevel and MaxLevel limit the levels of the players, 0 for no limit
+	MinLevel int
+	MaxLevel int
+	// Team is the team the players join, 0 if the match has no teams
+	Team int
+}
+
+// fits tells if all the players can fill the open slots of the match
+func (b *Backfill) fits(members []player.Player) bool {
+	if len(members) > b.Slots {
+		return false
+	}
+
+	for _, p := range members {
+		if len(b.Countries) > 0 && !slices.Contains(b.Countries, p.Country) {
+			return false
+		}
+
+		if (b.MinLevel > 0 && p.Level < b.MinLevel) || (b.MaxLevel > 0 && p.Level > b.MaxLevel) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// AddBackfill opens the slots of the started match. The slots are filled from the players waiting
+// in the lobby right away, the longest waiting players first, and the remaining slots are filled by
+// the players who join later. A new backfill of the match replaces the previous one.
+// It returns the number of the slots that are still open.
+func (l *Lobby) AddBackfill(b Backfill) (int, error) {
+	if b.Slots < 1 {
+		return 0, ErrNoOpenSlots
+	}
+
+	leaderBoard := l.MatchKeeper.GetLeaderBoard(b.MatchID)
+	if leaderBoard == nil {
+		return 0, match.ErrMatchNotFound
+	}
+
+	if leaderBoard.Ended() {
+		return 0, match.ErrMatchEnded
+	}
+
+	l.queueMu.Lock()
+	defer l.queueMu.Unlock()
+
+	if l.draining {
+		return 0, ErrDraining
+	}
+
+	l.backfills = slices.DeleteFunc(l.backfills, func(open *Backfill) bool { return open.MatchID == b.MatchID })
+
+	log.Printf("Match %s opened %d slot(s) for backfill", b.MatchID, b.Slots)
+
+	for _, waiting := range l.waitingUnits() {
+		if b.Slots == 0 {
+			break
+		}
+
+		if !b.fits(waiting.members) {
+			continue
+		}
+
+		for _, p := range waiting.members {
+			waiting.match.RemovePlayer(p.JoinID)
+		}
+
+		if err := l.fill(&b, waiting.members); err != nil {
+			// Put the players back into their match
+			for _, p := range waiting.members {
+				waiting.match.AddPlayer(p)
+			}
+
+			// A player who is already in the match keeps waiting for another match
+			if errors.Is(err, match.ErrPlayerInMatch) {
+				continue
+			}
+
+			return 0, match.ErrMatchNotFound
+		}
+
+		l.rebucket(waiting.location, waiting.match)
+	}
+
+	if b.Slots > 0 {
+		l.backfills = append(l.backfills, &b)
+	}
+
+	return b.Slots, nil
+}
+
+// RemoveBackfill closes the open slots of the match
+func (l *Lobby) RemoveBackfill(matchID string) error {
+	l.queueMu.Lock()
+	defer l.queueMu.Unlock()
+
+	for i, b := range l.backfills {
+		if b.MatchID == matchID {
+			l.backfills = slices.Delete(l.backfills, i, i+1)
+			log.Printf("Match %s closed the backfill", matchID)
+			return nil
+		}
+	}
+
+	return ErrBackfillNotFound
+}
+
+// fillBackfills places the players into the oldest backfill they fit.
+// It returns false if there is no such backfill. The caller must hold queueMu.
+func (l *Lobby) fillBackfills(members []player.Player) bool {
+	for i := 0; i < len(l.backfills); i++ {
+		b := l.backfills[i]
+		if !b.fits(members) {
+			continue
+		}
+
+		// A player who is already in the match can not fill it, but the other players can
+		err := l.fill(b, members)
+		if errors.Is(err, match.ErrPlayerInMatch) {
+			continue
+		}
+
+		if err != nil || b.Slots == 0 {
+			l.backfills = slices.Delete(l.backfills, i, i+1)
+			i--
+		}
+
+		if err == nil {
+			return true
+		}
+	}
+
+	return false
+}
+
+// fill adds the players to the started match of the backfill and notifies them.
+// It returns an error if the match is gone or any of the players is already in it. The caller must hold queueMu.
+func (l *Lobby) fill(b *Backfill, members []player.Player) error {
+	now := time.Now()
+	infos := make([]match.PlayerInfo, 0, len(members))
+	for _, p := range members {
+		infos = append(infos, match.PlayerInfo{
+			PlayerID:  p.PlayerID,
+			Level:     p.Level,
+			Country:   p.Country,
+			Team:      b.Team,
+			PartyID:   p.PartyID,
+			JoinID:    p.JoinID,
+			QueueTime: now.Sub(p.JoinedAt),
+		})
+	}
+
+	if err := l.MatchKeeper.AddPlayers(b.MatchID, infos); err != nil {
+		log.Printf("Failed to backfill match %s: %v", b.MatchID, err)
+		return err
+	}
+
+	l.releasePlayers(members)
+	b.Slots -= len(members)
+
+	for _, p := range members {
+		log.Printf("Player %s backfilled match %s, joinID: %s", p.PlayerID, b.MatchID, p.JoinID)
+	}
+	l.updateTickets(members, TicketMatched, b.MatchID, ReasonBackfill)
+
+	return nil
+}
+
+// waitingUnit is a party or a single player waiting in a match of the lobby
+type waitingUnit struct {
+	members  []player.Player
+	match    *match.Match
+	location *match.MatchLocation
+	joinedAt time.Time
+}
+
+// waitingUnits returns the parties and the single players waiting in the lobby, the longest waiting first.
+// The caller must hold queueMu.
+func (l *Lobby) waitingUnits() []*waitingUnit {
+	result := []*waitingUnit{}
+	l.matchLocations.Range(func(_, loaded interface{}) bool {
+		matchLocation := loaded.(*match.MatchLocation)
+
+		matchLocation.Range(func(m *match.Match) bool {
+			parties := make(map[string]*waitingUnit)
+			for _, p := range m.GetPlayers() {
+				if u, ok := parties[p.PartyID]; ok && p.PartyID != "" {
+					u.members = append(u.members, p)
+					if p.JoinedAt.Before(u.joinedAt) {
+						u.joinedAt = p.JoinedAt
+					}
+					continue
+				}
+
+				u := &waitingUnit{members: []player.Player{p}, match: m, location: matchLocation, joinedAt: p.JoinedAt}
+				parties[p.PartyID] = u
+				result = append(result, u)
+			}
+
+			return true
+		})
+
+		return true
+	})
+
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].joinedAt.Before(result[j].joinedAt)
+	})
+
+	return result
+}
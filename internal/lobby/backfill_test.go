@@ -0,0 +1,149 @@
+package lobby
+
+import (
+	"testing"
+	"time"
+
+	"github.com/TanyEm/match-maker/v2/internal/match"
+	"github.com/TanyEm/match-maker/v2/internal/player"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestLobby_AddBackfill(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockKeeper := match.NewMockKeeper(mockCtrl)
+	mockKeeper.EXPECT().
+		GetLeaderBoard("match1").
+		AnyTimes().
+		Return(&match.LeaderBoard{MatchID: "match1"})
+	mockKeeper.EXPECT().
+		GetLeaderBoard("gone").
+		Times(1).
+		Return(nil)
+	mockKeeper.EXPECT().
+		GetLeaderBoard("finished").
+		Times(1).
+		Return(&match.LeaderBoard{MatchID: "finished", Status: match.StatusFinished})
+
+	backfilled := []string{}
+	mockKeeper.EXPECT().
+		AddPlayers("match1", gomock.Any()).
+		Times(3).
+		DoAndReturn(func(_ string, players []match.PlayerInfo) error {
+			for _, p := range players {
+				assert.Equal(t, 2, p.Team)
+				backfilled = append(backfilled, p.PlayerID)
+			}
+			return nil
+		})
+
+	l := NewLobby(testConfig(time.Minute), mockKeeper, NewDefaultStrategy())
+
+	now := time.Now()
+	l.AddPlayer(player.Player{PlayerID: "1", JoinID: "join1", Level: 10, Country: "FIN", JoinedAt: now.Add(-20 * time.Second)})
+	l.AddPlayer(player.Player{PlayerID: "2", JoinID: "join2", Level: 10, Country: "SWE", JoinedAt: now.Add(-30 * time.Second)})
+	l.AddPlayer(player.Player{PlayerID: "3", JoinID: "join3", Level: 50, Country: "FIN", JoinedAt: now.Add(-30 * time.Second)})
+
+	_, err := l.AddBackfill(Backfill{MatchID: "match1"})
+	assert.ErrorIs(t, err, ErrNoOpenSlots)
+	_, err = l.AddBackfill(Backfill{MatchID: "gone", Slots: 1})
+	assert.ErrorIs(t, err, match.ErrMatchNotFound)
+	_, err = l.AddBackfill(Backfill{MatchID: "finished", Slots: 1})
+	assert.ErrorIs(t, err, match.ErrMatchEnded)
+
+	// The waiting player who fits the constraints fills a slot right away
+	open, err := l.AddBackfill(Backfill{MatchID: "match1", Slots: 3, Countries: []string{"FIN"}, MaxLevel: 20, Team: 2})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, open)
+	assert.Equal(t, "match1", ticketOf(l, "join1").MatchID)
+	assert.Equal(t, TicketQueued, ticketOf(l, "join2").State)
+	assert.Equal(t, TicketQueued, ticketOf(l, "join3").State)
+
+	location, _ := l.matchLocations.Load("FIN")
+	_, ok := location.(*match.MatchLocation).Load(10)
+	assert.False(t, ok, "Expected the empty match to be removed")
+
+	// The joining players fill the slots before the waiting matches
+	l.AddPlayer(player.Player{PlayerID: "4", JoinID: "join4", Level: 11, Country: "FIN"})
+	assert.Equal(t, "match1", ticketOf(l, "join4").MatchID)
+
+	// The party does not fit into the last slot
+	l.AddParty([]player.Player{
+		{PlayerID: "5", JoinID: "join5", Level: 10, Country: "FIN", PartyID: "party"},
+		{PlayerID: "6", JoinID: "join6", Level: 10, Country: "FIN", PartyID: "party"},
+	})
+	assert.Equal(t, TicketQueued, ticketOf(l, "join5").State)
+
+	l.AddPlayer(player.Player{PlayerID: "7", JoinID: "join7", Level: 12, Country: "FIN"})
+	assert.Equal(t, "match1", ticketOf(l, "join7").MatchID)
+	assert.Equal(t, []string{"1", "4", "7"}, backfilled)
+
+	// The backfill is closed once all slots are filled
+	assert.ErrorIs(t, l.RemoveBackfill("match1"), ErrBackfillNotFound)
+	assert.ErrorIs(t, l.RemovePlayer("join7"), ErrAlreadyMatched)
+
+	// The backfilled players can join the lobby again
+	assert.NoError(t, l.AddPlayer(player.Player{PlayerID: "1", JoinID: "join8", Level: 10, Country: "FIN"}))
+}
+
+func TestLobby_RemoveBackfill(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockKeeper := match.NewMockKeeper(mockCtrl)
+	mockKeeper.EXPECT().
+		GetLeaderBoard("match1").
+		Times(1).
+		Return(&match.LeaderBoard{MatchID: "match1"})
+
+	l := NewLobby(testConfig(time.Minute), mockKeeper, NewDefaultStrategy())
+
+	open, err := l.AddBackfill(Backfill{MatchID: "match1", Slots: 1})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, open)
+
+	assert.NoError(t, l.RemoveBackfill("match1"))
+	assert.ErrorIs(t, l.RemoveBackfill("match1"), ErrBackfillNotFound)
+
+	// The player waits for a new match after the backfill is closed
+	l.AddPlayer(player.Player{PlayerID: "1", JoinID: "join1", Level: 10, Country: "FIN"})
+	assert.Equal(t, TicketQueued, ticketOf(l, "join1").State)
+}
+
+func TestLobby_BackfillPlayerInMatch(t *testing.T) {
+	storage := match.NewStorage()
+	storage.AddLeaderBoard(&match.LeaderBoard{MatchID: "match1", Players: []match.PlayerInfo{{PlayerID: "1", JoinID: "join0"}}})
+
+	l := NewLobby(testConfig(time.Minute), storage, NewDefaultStrategy())
+
+	now := time.Now()
+	assert.NoError(t, l.AddPlayer(player.Player{PlayerID: "1", JoinID: "join1", Level: 10, Country: "FIN", JoinedAt: now.Add(-30 * time.Second)}))
+	assert.NoError(t, l.AddPlayer(player.Player{PlayerID: "2", JoinID: "join2", Level: 10, Country: "SWE", JoinedAt: now.Add(-20 * time.Second)}))
+
+	// The player who has left the match is not backfilled into it again, the other players are
+	open, err := l.AddBackfill(Backfill{MatchID: "match1", Slots: 2})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, open)
+	assert.Equal(t, TicketQueued, ticketOf(l, "join1").State)
+	assert.Equal(t, "match1", ticketOf(l, "join2").MatchID)
+
+	// The player joining again waits for another match and leaves the slot open for the others
+	assert.NoError(t, l.RemovePlayer("join1"))
+	assert.NoError(t, l.AddPlayer(player.Player{PlayerID: "1", JoinID: "join3", Level: 10, Country: "FIN"}))
+	assert.Equal(t, TicketQueued, ticketOf(l, "join3").State)
+
+	assert.NoError(t, l.AddPlayer(player.Player{PlayerID: "3", JoinID: "join4", Level: 10, Country: "FIN"}))
+	assert.Equal(t, "match1", ticketOf(l, "join4").MatchID)
+
+	playerIDs := []string{}
+	for _, p := range storage.GetLeaderBoard("match1").Players {
+		playerIDs = append(playerIDs, p.PlayerID)
+	}
+	assert.Equal(t, []string{"1", "2", "3"}, playerIDs)
+
+	_, total := storage.PlayerMatches("1", match.HistoryFilter{})
+	assert.Equal(t, 1, total, "Expected the match in the history of the player once")
+}
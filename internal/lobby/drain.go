@@ -0,0 +1,45 @@
+package lobby
+
+import (
+	"errors"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/TanyEm/match-maker/v2/internal/match"
+)
+
+var ErrDraining = errors.New("lobby is draining before shutdown")
+
+// Drain prepares the lobby for a shutdown. The lobby stops accepting players and backfills
+// with ErrDraining and starts the waiting matches that have at least Config.MinMatchSize players
+// without a ready check, the players of the other matches are notified there is no match.
+// The ready checks opened before can still be answered. So the clients waiting
+// for their matches get the results before the service exits.
+func (l *Lobby) Drain() {
+	l.queueMu.Lock()
+	defer l.queueMu.Unlock()
+
+	log.Printf("Draining the lobby %s...", l.metricKey())
+	l.draining = true
+	l.flush(time.Now(), false, func(_ *match.Match) bool { return true })
+}
+
+// Pending returns the tickets that are still queued or in the ready check in the order of the joins
+func (l *Lobby) Pending() []Ticket {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	pending := []Ticket{}
+	for _, ticket := range l.tickets {
+		if !ticket.Resolved() {
+			pending = append(pending, *ticket)
+		}
+	}
+
+	sort.Slice(pending, func(i, j int) bool {
+		return pending[i].JoinedAt.Before(pending[j].JoinedAt)
+	})
+
+	return pending
+}
@@ -0,0 +1,122 @@
+package lobby
+
+import (
+	"testing"
+	"time"
+
+	"github.com/TanyEm/match-maker/v2/internal/match"
+	"github.com/TanyEm/match-maker/v2/internal/player"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestLobby_Drain(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockKeeper := match.NewMockKeeper(mockCtrl)
+	mockKeeper.EXPECT().
+		AddLeaderBoard(gomock.Any()).
+		Times(1)
+
+	l := NewLobby(testConfig(time.Minute), mockKeeper, NewDefaultStrategy())
+
+	l.AddPlayer(player.Player{PlayerID: "1", JoinID: "join1", Level: 10, Country: "FIN"})
+	l.AddPlayer(player.Player{PlayerID: "2", JoinID: "join2", Level: 10, Country: "FIN"})
+	l.AddPlayer(player.Player{PlayerID: "3", JoinID: "join3", Level: 50, Country: "FIN"})
+
+	// The matches are resolved before their deadlines
+	l.Drain()
+	assert.Equal(t, TicketMatched, ticketOf(l, "join1").State)
+	assert.Equal(t, TicketMatched, ticketOf(l, "join2").State)
+	assert.Equal(t, TicketExpired, ticketOf(l, "join3").State)
+	assert.Empty(t, l.Pending())
+
+	// The lobby accepts no players and backfills
+	assert.ErrorIs(t, l.AddPlayer(player.Player{PlayerID: "4", JoinID: "join4", Level: 10, Country: "FIN"}), ErrDraining)
+	assert.ErrorIs(t, l.AddParty([]player.Player{{PlayerID: "5", JoinID: "join5", Level: 10, Country: "FIN"}}), ErrDraining)
+
+	mockKeeper.EXPECT().
+		GetLeaderBoard("match1").
+		Return(&match.LeaderBoard{MatchID: "match1"})
+	_, err := l.AddBackfill(Backfill{MatchID: "match1", Slots: 1})
+	assert.ErrorIs(t, err, ErrDraining)
+}
+
+func TestLobby_Pending(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	cfg := testConfig(time.Minute)
+	cfg.MaxMatchSize = 2
+	cfg.ReadyCheck = time.Minute
+	l := NewLobby(cfg, match.NewMockKeeper(mockCtrl), NewDefaultStrategy())
+
+	now := time.Now()
+	l.AddPlayer(player.Player{PlayerID: "1", JoinID: "join1", Level: 50, Country: "FIN", JoinedAt: now})
+	l.AddPlayer(player.Player{PlayerID: "2", JoinID: "join2", Level: 10, Country: "FIN", JoinedAt: now.Add(-time.Second)})
+	l.AddPlayer(player.Player{PlayerID: "3", JoinID: "join3", Level: 10, Country: "FIN", JoinedAt: now.Add(-2 * time.Second)})
+	l.AddPlayer(player.Player{PlayerID: "4", JoinID: "join4", Level: 90, Country: "FIN", JoinedAt: now})
+	l.RemovePlayer("join4")
+
+	// The queued tickets and the tickets in the ready check are pending, the longest waiting first
+	pending := l.Pending()
+	joinIDs := []string{}
+	for _, ticket := range pending {
+		joinIDs = append(joinIDs, ticket.JoinID)
+	}
+	assert.Equal(t, []string{"join3", "join2", "join1"}, joinIDs)
+	assert.Equal(t, TicketReadyCheck, pending[0].State)
+	assert.Equal(t, TicketQueued, pending[2].State)
+}
+
+func TestLobby_DrainSkipsReadyCheck(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockKeeper := match.NewMockKeeper(mockCtrl)
+	mockKeeper.EXPECT().
+		AddLeaderBoard(gomock.Any()).
+		Times(1).
+		Do(func(leaderBoard *match.LeaderBoard) {
+			assert.Len(t, leaderBoard.Players, 2)
+		})
+
+	l := NewLobby(readyCheckConfig(), mockKeeper, NewDefaultStrategy())
+
+	l.AddPlayer(player.Player{PlayerID: "1", JoinID: "join1", Level: 10, Country: "FIN"})
+	l.AddPlayer(player.Player{PlayerID: "2", JoinID: "join2", Level: 10, Country: "FIN"})
+
+	// The waiting match is started without a ready check, so its players are matched before the shutdown
+	l.Drain()
+	assert.Equal(t, TicketMatched, ticketOf(l, "join1").State)
+	assert.Equal(t, ticketOf(l, "join1").MatchID, ticketOf(l, "join2").MatchID)
+	assert.Empty(t, l.readyChecks)
+	assert.Empty(t, l.Pending())
+}
+
+func TestLobby_DrainReadyCheck(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	l := NewLobby(readyCheckConfig(), match.NewMockKeeper(mockCtrl), NewDefaultStrategy())
+
+	l.AddPlayer(player.Player{PlayerID: "1", JoinID: "join1", Level: 10, Country: "FIN"})
+	l.AddPlayer(player.Player{PlayerID: "2", JoinID: "join2", Level: 10, Country: "FIN"})
+	l.AddPlayer(player.Player{PlayerID: "3", JoinID: "join3", Level: 10, Country: "FIN"})
+
+	// The ready check can still be answered while the lobby is draining
+	l.Drain()
+	assert.Len(t, l.Pending(), 3)
+	assert.NoError(t, l.Ready("join1", true))
+
+	// The other players are not queued again after a decline
+	assert.NoError(t, l.Ready("join2", false))
+	assert.Equal(t, TicketCancelled, ticketOf(l, "join2").State)
+	for _, joinID := range []string{"join1", "join3"} {
+		ticket := ticketOf(l, joinID)
+		assert.Equal(t, TicketFailed, ticket.State)
+		assert.Equal(t, ReasonLobbyStopped, ticket.Reason)
+	}
+	assert.Empty(t, l.Pending())
+}
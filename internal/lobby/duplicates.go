@@ -0,0 +1,161 @@
+package lobby
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/TanyEm/match-maker/v2/internal/player"
+)
+
+// DuplicatePolicy is what happens when a player who is already waiting in the lobby joins again
+type DuplicatePolicy string
+
+const (
+	// DuplicateReject rejects the new join with ErrDuplicatePlayer
+	DuplicateReject DuplicatePolicy = "reject"
+	// DuplicateReplace cancels the old ticket of the player and queues the new one
+	DuplicateReplace DuplicatePolicy = "replace"
+)
+
+// roster keeps the active tickets of the waiting players and the penalties of the players
+// who have declined a ready check. Queues shares one roster between its lobbies,
+// so a player waits in a single queue at a time.
+type roster struct {
+	mu sync.Mutex
+	// waiting are the active tickets of the waiting players by their player IDs
+	waiting map[string]activeTicket
+	// penalties are the times the players who have declined a ready check can join again by their player IDs
+	penalties map[string]time.Time
+}
+
+// activeTicket is the join ID a player waits with and the lobby the player waits in
+type activeTicket struct {
+	joinID string
+	lobby  *Lobby
+}
+
+func newRoster() *roster {
+	return &roster{
+		waiting:   make(map[string]activeTicket),
+		penalties: make(map[string]time.Time),
+	}
+}
+
+// elsewhere returns the active tickets the players hold in the lobbies other than l
+func (r *roster) elsewhere(members []player.Player, l *Lobby) []activeTicket {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	active := []activeTicket{}
+	for _, p := range members {
+		if ticket, ok := r.waiting[p.PlayerID]; ok && ticket.lobby != l {
+			active = append(active, ticket)
+		}
+	}
+
+	return active
+}
+
+// penalise keeps the player from joining until the time
+func (r *roster) penalise(playerID string, until time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.penalties[playerID] = until
+}
+
+// prunePenalties forgets the penalties that are over
+func (r *roster) prunePenalties(now time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for playerID, until := range r.penalties {
+		if !now.Before(until) {
+			delete(r.penalties, playerID)
+		}
+	}
+}
+
+// claimPlayers registers the players as waiting according to Config.Duplicates.
+// The players penalised for declining a ready check are rejected with ErrPenalised,
+// and the players waiting in another queue are rejected with ErrDuplicatePlayer,
+// see Queues.AddPlayer. Nothing is changed if any of the players is rejected.
+// The caller must hold queueMu.
+func (l *Lobby) claimPlayers(members []player.Player) error {
+	replaced, err := l.duplicates(members)
+	if err != nil {
+		return err
+	}
+
+	for _, joinID := range replaced {
+		l.cancelTicket(joinID, ReasonReplaced)
+	}
+
+	l.roster.mu.Lock()
+	defer l.roster.mu.Unlock()
+
+	// Another queue may have taken the players in the meantime
+	for _, p := range members {
+		if _, ok := l.roster.waiting[p.PlayerID]; ok {
+			return fmt.Errorf("player %s: %w", p.PlayerID, ErrDuplicatePlayer)
+		}
+	}
+
+	for _, p := range members {
+		l.roster.waiting[p.PlayerID] = activeTicket{joinID: p.JoinID, lobby: l}
+	}
+
+	return nil
+}
+
+// duplicates returns the join IDs of the tickets the players replace in the lobby.
+// The caller must hold queueMu.
+func (l *Lobby) duplicates(members []player.Player) ([]string, error) {
+	l.roster.mu.Lock()
+	defer l.roster.mu.Unlock()
+
+	now := time.Now()
+	for _, p := range members {
+		if until, ok := l.roster.penalties[p.PlayerID]; ok && now.Before(until) {
+			return nil, fmt.Errorf("player %s until %s: %w", p.PlayerID, until.Format(time.RFC3339), ErrPenalised)
+		}
+	}
+
+	replaced := []string{}
+	for _, p := range members {
+		ticket, ok := l.roster.waiting[p.PlayerID]
+		if !ok {
+			continue
+		}
+
+		if l.Config.Duplicates != DuplicateReplace || ticket.lobby != l {
+			return nil, fmt.Errorf("player %s: %w", p.PlayerID, ErrDuplicatePlayer)
+		}
+
+		replaced = append(replaced, ticket.joinID)
+	}
+
+	return replaced, nil
+}
+
+// releasePlayers forgets the waiting players who left the queue.
+// The caller must hold queueMu.
+func (l *Lobby) releasePlayers(players []player.Player) {
+	l.roster.mu.Lock()
+	defer l.roster.mu.Unlock()
+
+	for _, p := range players {
+		if l.roster.waiting[p.PlayerID].joinID == p.JoinID {
+			delete(l.roster.waiting, p.PlayerID)
+		}
+	}
+}
+
+// replaceTicket cancels the ticket of the player who joins another queue instead
+func (l *Lobby) replaceTicket(joinID string) {
+	l.queueMu.Lock()
+	defer l.queueMu.Unlock()
+
+	l.cancelTicket(joinID, ReasonReplaced)
+}
@@ -0,0 +1,166 @@
+package lobby
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/TanyEm/match-maker/v2/internal/match"
+	"github.com/TanyEm/match-maker/v2/internal/player"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestLobby_DuplicateReject(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockKeeper := match.NewMockKeeper(mockCtrl)
+	mockKeeper.EXPECT().
+		AddLeaderBoard(gomock.Any()).
+		Times(1)
+
+	l := NewLobby(testConfig(30*time.Second), mockKeeper, NewDefaultStrategy())
+
+	assert.NoError(t, l.AddPlayer(player.Player{PlayerID: "1", JoinID: "join1", Level: 10, Country: "FIN"}))
+	assert.ErrorIs(t, l.AddPlayer(player.Player{PlayerID: "1", JoinID: "join2", Level: 10, Country: "FIN"}), ErrDuplicatePlayer)
+
+	// The party is rejected as a whole if any member is waiting
+	err := l.AddParty([]player.Player{
+		{PlayerID: "2", JoinID: "join3", Level: 10, Country: "FIN", PartyID: "party"},
+		{PlayerID: "1", JoinID: "join4", Level: 10, Country: "FIN", PartyID: "party"},
+	})
+	assert.ErrorIs(t, err, ErrDuplicatePlayer)
+	assert.NoError(t, l.AddPlayer(player.Player{PlayerID: "2", JoinID: "join5", Level: 10, Country: "FIN"}))
+
+	// The players can join again once their match is started
+	l.StartMatches()
+	assert.NoError(t, l.AddPlayer(player.Player{PlayerID: "1", JoinID: "join6", Level: 10, Country: "FIN"}))
+
+	// The player can join again after leaving the lobby
+	assert.NoError(t, l.RemovePlayer("join6"))
+	assert.NoError(t, l.AddPlayer(player.Player{PlayerID: "1", JoinID: "join7", Level: 10, Country: "FIN"}))
+}
+
+func TestLobby_DuplicateReplace(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	cfg := testConfig(30 * time.Second)
+	cfg.Duplicates = DuplicateReplace
+	l := NewLobby(cfg, match.NewMockKeeper(mockCtrl), NewDefaultStrategy())
+
+	assert.NoError(t, l.AddPlayer(player.Player{PlayerID: "1", JoinID: "join1", Level: 10, Country: "FIN"}))
+	assert.NoError(t, l.AddPlayer(player.Player{PlayerID: "1", JoinID: "join2", Level: 30, Country: "FIN"}))
+
+	assert.Equal(t, TicketCancelled, ticketOf(l, "join1").State)
+	assert.ErrorIs(t, l.RemovePlayer("join1"), ErrTicketNotFound)
+
+	location, _ := l.matchLocations.Load("FIN")
+	_, ok := location.(*match.MatchLocation).Load(10)
+	assert.False(t, ok, "Expected the old ticket to leave the match")
+	loaded, ok := location.(*match.MatchLocation).Load(30)
+	assert.True(t, ok, "Expected the new ticket to wait")
+	assert.Equal(t, "join2", loaded.GetPlayers()[0].JoinID)
+}
+
+func TestLobby_DuplicateConcurrentJoins(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	l := NewLobby(testConfig(30*time.Second), match.NewMockKeeper(mockCtrl), NewDefaultStrategy())
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		accepted int
+	)
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			err := l.AddPlayer(player.Player{PlayerID: "1", JoinID: fmt.Sprintf("join%d", i), Level: 10, Country: "FIN"})
+			if err == nil {
+				mu.Lock()
+				accepted++
+				mu.Unlock()
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	assert.Equal(t, 1, accepted)
+
+	location, _ := l.matchLocations.Load("FIN")
+	loaded, _ := location.(*match.MatchLocation).Load(10)
+	assert.Equal(t, 1, loaded.GetPlayersCount())
+}
+
+func TestQueues_DuplicatesAcrossQueues(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockKeeper := match.NewMockKeeper(mockCtrl)
+
+	casualConfig := testConfig(30 * time.Second)
+	casualConfig.Queue = "casual"
+	casual := NewLobby(casualConfig, mockKeeper, NewDefaultStrategy())
+
+	rankedConfig := testConfig(30 * time.Second)
+	rankedConfig.Queue = "ranked"
+	rankedConfig.Duplicates = DuplicateReplace
+	ranked := NewLobby(rankedConfig, mockKeeper, NewDefaultStrategy())
+
+	q, err := NewQueues("casual", map[string]*Lobby{"casual": casual, "ranked": ranked})
+	assert.NoError(t, err)
+
+	// A player waiting in one queue can not join another queue that rejects the duplicates
+	assert.NoError(t, q.AddPlayer(player.Player{PlayerID: "1", JoinID: "join1", Level: 10, Country: "FIN", Queue: "ranked"}))
+	assert.ErrorIs(t, q.AddPlayer(player.Player{PlayerID: "1", JoinID: "join2", Level: 10, Country: "FIN"}), ErrDuplicatePlayer)
+	assert.Equal(t, TicketQueued, ticketOf(q, "join1").State)
+
+	// A queue that replaces the duplicates cancels the ticket of the other queue
+	assert.NoError(t, q.AddPlayer(player.Player{PlayerID: "2", JoinID: "join3", Level: 10, Country: "FIN"}))
+	assert.NoError(t, q.AddPlayer(player.Player{PlayerID: "2", JoinID: "join4", Level: 10, Country: "FIN", Queue: "ranked"}))
+	assert.Equal(t, TicketCancelled, ticketOf(q, "join3").State)
+	assert.Equal(t, ReasonReplaced, ticketOf(q, "join3").Reason)
+	assert.Equal(t, TicketQueued, ticketOf(q, "join4").State)
+	assert.Zero(t, casual.GetCandidateMatchSize("join3"), "Expected the old ticket to leave the casual queue")
+
+	// The player can join another queue after leaving
+	assert.NoError(t, q.RemovePlayer("join1"))
+	assert.NoError(t, q.AddPlayer(player.Player{PlayerID: "1", JoinID: "join5", Level: 10, Country: "FIN"}))
+}
+
+func TestQueues_PenaltiesAcrossQueues(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockKeeper := match.NewMockKeeper(mockCtrl)
+
+	casualConfig := testConfig(30 * time.Second)
+	casualConfig.Queue = "casual"
+	casual := NewLobby(casualConfig, mockKeeper, NewDefaultStrategy())
+
+	rankedConfig := testConfig(30 * time.Second)
+	rankedConfig.Queue = "ranked"
+	rankedConfig.MaxMatchSize = 2
+	rankedConfig.ReadyCheck = 10 * time.Second
+	rankedConfig.DeclinePenalty = time.Minute
+	ranked := NewLobby(rankedConfig, mockKeeper, NewDefaultStrategy())
+
+	q, err := NewQueues("casual", map[string]*Lobby{"casual": casual, "ranked": ranked})
+	assert.NoError(t, err)
+
+	assert.NoError(t, q.AddPlayer(player.Player{PlayerID: "1", JoinID: "join1", Level: 10, Country: "FIN", Queue: "ranked"}))
+	assert.NoError(t, q.AddPlayer(player.Player{PlayerID: "2", JoinID: "join2", Level: 10, Country: "FIN", Queue: "ranked"}))
+	assert.NoError(t, q.Ready("join1", false))
+
+	// The player who has declined the ready check of one queue can not join any queue until the penalty is over
+	assert.ErrorIs(t, q.AddPlayer(player.Player{PlayerID: "1", JoinID: "join3", Level: 10, Country: "FIN"}), ErrPenalised)
+
+	casual.ExpireReadyChecks(time.Now().Add(2 * time.Minute))
+	assert.NoError(t, q.AddPlayer(player.Player{PlayerID: "1", JoinID: "join3", Level: 10, Country: "FIN"}))
+}
@@ -0,0 +1,64 @@
+package lobby
+
+import (
+	"time"
+
+	"github.com/TanyEm/match-maker/v2/internal/player"
+)
+
+const (
+	// waitSamples is the number of the latest times to match a wait estimate is averaged over
+	waitSamples = 20
+	// waitLevelBand is the width of the level bands the times to match are kept by
+	waitLevelBand = 10
+	// anyBand keeps the times to match of all levels of a country
+	anyBand = -1
+)
+
+// waitKey is the country and the level band the times to match are kept by
+type waitKey struct {
+	country string
+	band    int
+}
+
+// recordWait keeps the time the player waited for a match, only the latest waitSamples times
+// of the player's country and level band are kept. The caller must hold l.mu.
+func (l *Lobby) recordWait(p player.Player, wait time.Duration) {
+	for _, key := range []waitKey{{p.Country, p.Level / waitLevelBand}, {p.Country, anyBand}} {
+		samples := append(l.waits[key], wait)
+		if len(samples) > waitSamples {
+			samples = samples[len(samples)-waitSamples:]
+		}
+		l.waits[key] = samples
+	}
+}
+
+// EstimateWait returns the estimated time the player waits for a match: the average time to match
+// of the latest players of the player's country and level band, or of the whole country
+// if there are no players of the band. It is Config.WaitingTime, the longest wait,
+// if no players of the country have been matched yet.
+func (l *Lobby) EstimateWait(p player.Player) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.estimateWait(p)
+}
+
+// estimateWait is EstimateWait for the caller holding l.mu
+func (l *Lobby) estimateWait(p player.Player) time.Duration {
+	for _, key := range []waitKey{{p.Country, p.Level / waitLevelBand}, {p.Country, anyBand}} {
+		samples := l.waits[key]
+		if len(samples) == 0 {
+			continue
+		}
+
+		var total time.Duration
+		for _, wait := range samples {
+			total += wait
+		}
+
+		return total / time.Duration(len(samples))
+	}
+
+	return l.Config.WaitingTime
+}
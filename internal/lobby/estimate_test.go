@@ -0,0 +1,61 @@
+package lobby
+
+import (
+	"testing"
+	"time"
+
+	"github.com/TanyEm/match-maker/v2/internal/match"
+	"github.com/TanyEm/match-maker/v2/internal/player"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestLobby_EstimateWait(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockKeeper := match.NewMockKeeper(mockCtrl)
+	mockKeeper.EXPECT().
+		AddLeaderBoard(gomock.Any()).
+		Times(1)
+
+	cfg := testConfig(time.Minute)
+	cfg.MaxMatchSize = 2
+	l := NewLobby(cfg, mockKeeper, NewDefaultStrategy())
+
+	// There are no matched players yet, so the longest wait is estimated
+	assert.Equal(t, time.Minute, l.EstimateWait(player.Player{Level: 15, Country: "FIN"}))
+
+	now := time.Now()
+	l.AddPlayer(player.Player{PlayerID: "1", JoinID: "join1", Level: 10, Country: "FIN", JoinedAt: now.Add(-20 * time.Second)})
+	l.AddPlayer(player.Player{PlayerID: "2", JoinID: "join2", Level: 11, Country: "FIN", JoinedAt: now.Add(-10 * time.Second)})
+	assert.Equal(t, TicketMatched, ticketOf(l, "join1").State)
+
+	// The players of the same level band
+	assert.InDelta(t, 15*time.Second, l.EstimateWait(player.Player{Level: 15, Country: "FIN"}), float64(time.Second))
+	// The players of the same country if no players of the band are matched
+	assert.InDelta(t, 15*time.Second, l.EstimateWait(player.Player{Level: 50, Country: "FIN"}), float64(time.Second))
+	assert.Equal(t, time.Minute, l.EstimateWait(player.Player{Level: 15, Country: "SWE"}))
+
+	// The ticket keeps the estimate of the time the player joined
+	l.AddPlayer(player.Player{PlayerID: "3", JoinID: "join3", Level: 50, Country: "SWE"})
+	ticket := ticketOf(l, "join3")
+	assert.Equal(t, time.Minute, ticket.EstimatedWait)
+	assert.Equal(t, 50*time.Second, ticket.RemainingWait(ticket.JoinedAt.Add(10*time.Second)))
+	assert.Equal(t, time.Duration(0), ticket.RemainingWait(ticket.JoinedAt.Add(2*time.Minute)))
+}
+
+func TestLobby_EstimateWaitLatestSamples(t *testing.T) {
+	l := NewLobby(testConfig(time.Minute), match.NewMockKeeper(gomock.NewController(t)), NewDefaultStrategy())
+	p := player.Player{Level: 5, Country: "FIN"}
+
+	for i := 0; i < 5; i++ {
+		l.recordWait(p, time.Minute)
+	}
+	for i := 0; i < waitSamples; i++ {
+		l.recordWait(p, 10*time.Second)
+	}
+
+	assert.Len(t, l.waits[waitKey{"FIN", 0}], waitSamples)
+	assert.Equal(t, 10*time.Second, l.EstimateWait(p))
+}
@@ -1,61 +1,215 @@
 package lobby
 
 import (
+	"errors"
+	"fmt"
 	"log"
+	"slices"
 	"sync"
 	"time"
 
 	"github.com/TanyEm/match-maker/v2/internal/match"
 	"github.com/TanyEm/match-maker/v2/internal/player"
+	"github.com/TanyEm/match-maker/v2/internal/region"
 )
 
-const ErrNoMatch = "ErrNoMatch"
+var (
+	ErrEmptyParty      = errors.New("party has no players")
+	ErrPartyTooBig     = errors.New("party is too big")
+	ErrTicketNotFound  = errors.New("ticket is not found in the lobby")
+	ErrAlreadyMatched  = errors.New("ticket is already matched")
+	ErrDuplicatePlayer = errors.New("player is already waiting in the lobby")
+)
 
 //go:generate mockgen -destination=./lobby_mock.go -package=lobby github.com/TanyEm/match-maker/v2/internal/lobby Lobbier
 type Lobbier interface {
-	AddPlayer(p player.Player)
-	GetMatchByJoinID(joinID string) string
+	AddBackfill(b Backfill) (int, error)
+	AddParty(members []player.Player) error
+	AddPlayer(p player.Player) error
+	GetCandidateMatchSize(joinID string) int
 	GetMatchMakingTime() time.Duration
+	GetTicket(joinID string) (Ticket, error)
+	Ready(joinID string, accept bool) error
+	RemoveBackfill(matchID string) error
+	RemovePlayer(joinID string) error
 	Run()
 	Stop()
 }
 
+// Config holds the settings of a lobby
+type Config struct {
+	// Queue is the name of the queue (game mode) the lobby serves, the leaderboards are tagged with it
+	Queue string
+	// WaitingTime is the time to wait for players before starting matches
+	WaitingTime time.Duration
+	// MinMatchSize is the least number of players a match can start with
+	MinMatchSize int
+	// MaxMatchSize is the number of players that starts a match right away
+	MaxMatchSize int
+	// LevelWidening is the curve the acceptable level band of waiting players expands with
+	LevelWidening []WideningStep
+	// RegionFallback is how far from their country the players of too thin matches are merged
+	// when the match making time is up. SameCountry disables the fallback.
+	RegionFallback region.Proximity
+	// Regions resolves the proximity of countries, the UN M49 regions are used if it is nil
+	Regions *region.Map
+	// TeamCount is the number of balanced teams the players of a match are split into, 0 for no teams
+	TeamCount int
+	// PartyLevel is how the level and the rating of a party is calculated from its members,
+	// PartyLevelAverage if it is empty
+	PartyLevel PartyLevelMode
+	// TicketTTL is how long the resolved tickets are kept, 0 to keep them forever
+	TicketTTL time.Duration
+	// MaxWait is the longest time a player waits for a match. The players of too thin matches
+	// keep waiting for another round of WaitingTime until MaxWait is up, 0 to notify them right away.
+	MaxWait time.Duration
+	// ReadyCheck is the time the players of a full match have to accept it before the match starts,
+	// 0 to start the matches right away
+	ReadyCheck time.Duration
+	// DeclinePenalty is the time a player who has declined or missed a ready check can not join the lobby
+	DeclinePenalty time.Duration
+	// Duplicates is what happens when a player who is already waiting joins again,
+	// DuplicateReject if it is empty
+	Duplicates DuplicatePolicy
+}
+
+func (c Config) Validate() error {
+	if c.WaitingTime <= 0 {
+		return fmt.Errorf("waiting time must be positive, got %s", c.WaitingTime)
+	}
+
+	if c.MinMatchSize < 1 {
+		return fmt.Errorf("min match size must be at least 1, got %d", c.MinMatchSize)
+	}
+
+	if c.MaxMatchSize < c.MinMatchSize {
+		return fmt.Errorf("max match size %d is less than min match size %d", c.MaxMatchSize, c.MinMatchSize)
+	}
+
+	if c.TicketTTL < 0 {
+		return fmt.Errorf("ticket TTL must not be negative, got %s", c.TicketTTL)
+	}
+
+	if c.MaxWait != 0 && c.MaxWait < c.WaitingTime {
+		return fmt.Errorf("max wait %s is less than waiting time %s", c.MaxWait, c.WaitingTime)
+	}
+
+	if c.ReadyCheck < 0 {
+		return fmt.Errorf("ready check must not be negative, got %s", c.ReadyCheck)
+	}
+
+	if c.DeclinePenalty < 0 {
+		return fmt.Errorf("decline penalty must not be negative, got %s", c.DeclinePenalty)
+	}
+
+	if c.TeamCount < 0 {
+		return fmt.Errorf("team count must not be negative, got %d", c.TeamCount)
+	}
+
+	if c.TeamCount > 0 {
+		if c.MaxMatchSize%c.TeamCount != 0 {
+			return fmt.Errorf("max match size %d can not be split into %d teams of the same size", c.MaxMatchSize, c.TeamCount)
+		}
+
+		if c.MinMatchSize < c.TeamCount {
+			return fmt.Errorf("min match size %d is less than team count %d", c.MinMatchSize, c.TeamCount)
+		}
+	}
+
+	if c.PartyLevel != "" && c.PartyLevel != PartyLevelAverage && c.PartyLevel != PartyLevelMax {
+		return fmt.Errorf("unknown party level %q, expected %q or %q", c.PartyLevel, PartyLevelAverage, PartyLevelMax)
+	}
+
+	if c.Duplicates != "" && c.Duplicates != DuplicateReject && c.Duplicates != DuplicateReplace {
+		return fmt.Errorf("unknown duplicate policy %q, expected %q or %q", c.Duplicates, DuplicateReject, DuplicateReplace)
+	}
+
+	if err := ValidateWidening(c.LevelWidening); err != nil {
+		return fmt.Errorf("invalid level widening: %w", err)
+	}
+
+	return nil
+}
+
 type Lobby struct {
-	stopCh          chan struct{}
-	mu              sync.Mutex
-	matchLocations  sync.Map
-	WaitingTime     time.Duration
-	MatchKeeper     match.Keeper
-	playersToNotify map[string]string
+	stopCh chan struct{}
+	// mu guards tickets and waits
+	mu sync.Mutex
+	// queueMu guards the matches waiting in matchLocations, the backfills, the ready checks and draining
+	queueMu        sync.Mutex
+	matchLocations sync.Map
+	// roster keeps the waiting players and the penalties, it is shared by the lobbies of Queues
+	roster *roster
+	// backfills are the open slots of the started matches, the oldest first
+	backfills []*Backfill
+	// readyChecks are the full matches waiting for their players to accept them, the oldest first
+	readyChecks []*readyCheck
+	// draining rejects the new players and backfills, see Drain
+	draining    bool
+	Config      Config
+	MatchKeeper match.Keeper
+	Strategy    MatchmakingStrategy
+	regions     *region.Map
+	// tickets are the tickets of the players by their join IDs
+	tickets map[string]*Ticket
+	// pruned are the times the tickets were pruned by their join IDs
+	pruned map[string]time.Time
+	// waits are the latest times to match of the players by their countries and level bands
+	waits map[waitKey][]time.Duration
 }
 
-func NewLobby(waitingTime time.Duration, matchKeeper match.Keeper) *Lobby {
+func NewLobby(cfg Config, matchKeeper match.Keeper, strategy MatchmakingStrategy) *Lobby {
+	regions := cfg.Regions
+	if regions == nil {
+		regions = region.NewMap(nil)
+	}
+
 	return &Lobby{
-		stopCh:          make(chan struct{}),
-		matchLocations:  sync.Map{},
-		WaitingTime:     waitingTime,
-		MatchKeeper:     matchKeeper,
-		playersToNotify: make(map[string]string),
+		stopCh:         make(chan struct{}),
+		matchLocations: sync.Map{},
+		roster:         newRoster(),
+		Config:         cfg,
+		MatchKeeper:    matchKeeper,
+		Strategy:       strategy,
+		regions:        regions,
+		tickets:        make(map[string]*Ticket),
+		pruned:         make(map[string]time.Time),
+		waits:          make(map[waitKey][]time.Duration),
 	}
 }
 
+// GetMatchMakingTime returns the longest time a player waits for a match, Config.MaxWait
+// if the players of too thin matches are carried over into the next rounds, otherwise Config.WaitingTime
 func (l *Lobby) GetMatchMakingTime() time.Duration {
-	return l.WaitingTime
+	l.queueMu.Lock()
+	defer l.queueMu.Unlock()
+
+	return max(l.Config.WaitingTime, l.Config.MaxWait)
 }
 
+// Run checks the deadlines of the waiting players until the lobby is stopped.
+// A match is started or its players are notified there is no match once the deadline
+// of the longest waiting player of the match has passed. The resolved tickets are pruned
+// once they are older than Config.TicketTTL, and the ready checks are resolved once their time is up.
 func (l *Lobby) Run() {
-	ticker := time.NewTicker(l.WaitingTime)
-	defer ticker.Stop()
-
 	log.Println("Lobby is running. Waiting for people to join...")
 
+	pruneTicker := time.NewTicker(ticketPruneInterval)
+	defer pruneTicker.Stop()
+
 	for {
 		select {
-		case <-ticker.C:
-			log.Println("Time is up! Start mathmaking...")
-			l.StartMatches()
+		case now := <-pruneTicker.C:
+			if pruned := l.PruneTickets(now); pruned > 0 {
+				log.Printf("Pruned %d ticket(s)", pruned)
+			}
 		case <-time.After(100 * time.Millisecond): // Throttle the loop to decrease the load on the CPU
+			now := time.Now()
+			l.WidenMatches(now)
+			l.StartDueMatches(now)
+			l.ExpireReadyChecks(now)
 		case <-l.stopCh:
+			l.failQueued(ReasonLobbyStopped)
 			log.Println("Lobby is stopped.")
 			return
 		}
@@ -67,118 +221,287 @@ func (l *Lobby) Stop() {
 	l.stopCh <- struct{}{}
 }
 
-func (l *Lobby) AddPlayer(p player.Player) {
+// AddPlayer adds the player to the lobby. A player who is already waiting is rejected
+// with ErrDuplicatePlayer or replaces the old ticket, see Config.Duplicates.
+func (l *Lobby) AddPlayer(p player.Player) error {
 	log.Printf("Player %s joined the lobby, joinID: %s", p.PlayerID, p.JoinID)
 
-	// If the player's location is not in the lobby, create a new match, new location and store it.
-	matchLocation := &match.MatchLocation{}
-	loaded, ok := l.matchLocations.Load(p.Country)
-	if !ok {
-		levelToStore := p.Level
-		// If the player's level is 1, store the match at level 2
-		// to allow players from level 1 to join the match as well as players from level 2 and 3
-		if p.Level == 1 {
-			levelToStore = 2
+	return l.enqueue([]player.Player{p})
+}
+
+// AddParty adds the players who queue together. The party is placed into a match as a whole
+// and is matched by the average or the max level of its members, see Config.PartyLevel.
+// The party waits in the location of its first member.
+func (l *Lobby) AddParty(members []player.Player) error {
+	if len(members) == 0 {
+		return ErrEmptyParty
+	}
+
+	l.queueMu.Lock()
+	maxPartySize := l.maxPartySize()
+	l.queueMu.Unlock()
+
+	if len(members) > maxPartySize {
+		return fmt.Errorf("party of %d players: %w, max party size is %d", len(members), ErrPartyTooBig, maxPartySize)
+	}
+
+	for _, p := range members {
+		log.Printf("Player %s joined the lobby in party %s, joinID: %s", p.PlayerID, p.PartyID, p.JoinID)
+	}
+
+	return l.enqueue(members)
+}
+
+// enqueue places the players into a waiting match of their location
+// or creates a new match if there is no match the players can join
+func (l *Lobby) enqueue(members []player.Player) error {
+	l.queueMu.Lock()
+	defer l.queueMu.Unlock()
+
+	if l.draining {
+		return ErrDraining
+	}
+
+	now := time.Now()
+	for i := range members {
+		if members[i].JoinedAt.IsZero() {
+			members[i].JoinedAt = now
+		}
+		if members[i].Deadline.IsZero() {
+			members[i].Deadline = members[i].JoinedAt.Add(l.Config.WaitingTime)
 		}
-		newMatch := match.NewMatch(p.Country, levelToStore)
-		newMatch.AddPlayer(p)
+	}
 
-		matchLocation.Store(levelToStore, newMatch)
-		l.matchLocations.Store(p.Country, matchLocation)
-		return
+	if err := l.claimPlayers(members); err != nil {
+		return err
 	}
+	l.updateTickets(members, TicketQueued, "", "")
+	l.place(members)
 
-	matchLocation = loaded.(*match.MatchLocation)
+	return nil
+}
+
+// place puts the queued players into a backfill, a waiting match of their location
+// or a new match. The caller must hold queueMu.
+func (l *Lobby) place(members []player.Player) {
+	p := partyRepresentative(members, l.Config.PartyLevel)
+
+	// Open slots of the started matches are filled before the waiting matches
+	if l.fillBackfills(members) {
+		return
+	}
 
-	// If the player's location is in the lobby, check if there is a match that the player can join.
-	// I assume that the player can compete with players from the same level and the levels above and below
-	// but the player with level 1 can only compete with players from levels 1, 2, and 3
-	levelsToJoin := make([]int, 3)
-	if p.Level > 1 {
-		levelsToJoin[0], levelsToJoin[1], levelsToJoin[2] = p.Level-1, p.Level, p.Level+1
+	// If the player's location is not in the lobby, create a new location and store it.
+	matchLocation := &match.MatchLocation{}
+	if loaded, ok := l.matchLocations.Load(p.Country); ok {
+		matchLocation = loaded.(*match.MatchLocation)
 	} else {
-		levelsToJoin[0], levelsToJoin[1], levelsToJoin[2] = 1, 2, 3
+		l.matchLocations.Store(p.Country, matchLocation)
 	}
 
-	// If there is a match that the player can join, add the player to the match
-	for _, level := range levelsToJoin {
-		if loaded, ok := matchLocation.Load(level); ok {
-			matchToJoin := loaded.(*match.Match)
-			matchToJoin.AddPlayer(p)
+	// Check if there is a match in the location that the player can join.
+	// The strategy decides which level buckets the player can compete in.
+	// If there is a match that the player can join and has room for the whole party, add the players to the match
+	for _, level := range l.Strategy.Candidates(p) {
+		for _, matchToJoin := range matchLocation.Bucket(level) {
+			if matchToJoin.GetPlayersCount()+len(members) > l.Config.MaxMatchSize ||
+				!l.fitTeams(slices.Concat(matchToJoin.GetPlayers(), members)) {
+				continue
+			}
+
+			for _, member := range members {
+				matchToJoin.AddPlayer(member)
+			}
 
 			// If the match is full, start the match and delete it from the location in the lobby
-			if matchToJoin.GetPlayersCount() == 10 {
-				l.StartMatch(matchToJoin, matchLocation)
-				matchLocation.Delete(level)
+			if matchToJoin.GetPlayersCount() >= l.Config.MaxMatchSize && l.StartMatch(matchToJoin, matchLocation) {
+				matchLocation.Delete(matchToJoin)
 			}
 
 			return
 		}
 	}
 
-	// If no match is found, create a new match
-	log.Printf("No existing match found for Player %s at any nearby levels. Creating new match at level %d", p.PlayerID, p.Level)
-	m := match.NewMatch(p.Country, p.Level)
-	m.AddPlayer(p)
+	// If no match is found, create a new match at the player's bucket, next to the matches
+	// the party does not fit into
+	levelToStore := l.Strategy.Bucket(p)
+	log.Printf("No existing match found for Player %s at any nearby levels. Creating new match at level %d", p.PlayerID, levelToStore)
+	m := match.NewMatch(p.Country, levelToStore)
+	for _, member := range members {
+		m.AddPlayer(member)
+	}
+
+	// A match of a single player or a party is full already in small modes
+	if m.GetPlayersCount() >= l.Config.MaxMatchSize && l.StartMatch(m, matchLocation) {
+		return
+	}
 
 	// Store the match in the player's location
-	matchLocation.Store(p.Level, m)
-	l.matchLocations.Store(p.Country, matchLocation)
+	matchLocation.Store(m)
 }
 
-func (l *Lobby) StartMatch(m *match.Match, matchLocation *match.MatchLocation) {
-	joinIDs := m.Start()
-	l.mu.Lock()
-	for _, joinID := range joinIDs {
-		l.playersToNotify[joinID] = m.MatchID
+// RemovePlayer takes the player with the join ID out of the waiting match or declines
+// the ready check of the player. Other members of the player's party keep waiting.
+func (l *Lobby) RemovePlayer(joinID string) error {
+	l.queueMu.Lock()
+	defer l.queueMu.Unlock()
+
+	if l.cancelTicket(joinID, ReasonLeft) {
+		return nil
+	}
+
+	if ticket, err := l.GetTicket(joinID); err == nil && ticket.State == TicketMatched {
+		return ErrAlreadyMatched
 	}
-	l.mu.Unlock()
+
+	return ErrTicketNotFound
+}
+
+// cancelTicket takes the player with the join ID out of the waiting match or its ready check
+// and cancels the ticket for the reason. It returns false if the player is not waiting. The caller must hold queueMu.
+func (l *Lobby) cancelTicket(joinID string, reason Reason) bool {
+	var removed *player.Player
+	l.matchLocations.Range(func(_, loaded interface{}) bool {
+		matchLocation := loaded.(*match.MatchLocation)
+
+		matchLocation.Range(func(m *match.Match) bool {
+			p, ok := m.RemovePlayer(joinID)
+			if !ok {
+				return true
+			}
+
+			log.Printf("Player %s left the lobby, joinID: %s", p.PlayerID, joinID)
+			removed = &p
+			l.releasePlayers([]player.Player{p})
+
+			l.rebucket(matchLocation, m)
+
+			return false
+		})
+
+		return removed == nil
+	})
+
+	if removed == nil {
+		return l.answerReady(joinID, false, reason) == nil
+	}
+
+	l.updateTickets([]player.Player{*removed}, TicketCancelled, "", reason)
+
+	return true
+}
+
+// rebucket fixes up the level bucket of the waiting match some players have left.
+// The match is removed from the lobby if it has no players left, otherwise it is moved
+// to the level bucket of the remaining players. The caller must hold queueMu.
+func (l *Lobby) rebucket(matchLocation *match.MatchLocation, m *match.Match) {
+	if m.GetPlayersCount() == 0 {
+		matchLocation.Delete(m)
+		return
+	}
+
+	if bucket := l.Strategy.Bucket(partyRepresentative(m.GetPlayers(), PartyLevelAverage)); bucket != m.Level {
+		matchLocation.Move(m, bucket)
+	}
+}
+
+// StartMatch splits the players of the full match into teams and starts the match, or holds it
+// until its players accept it if Config.ReadyCheck is set. A draining lobby starts the match right away,
+// as there is no time left for a ready check. It returns false and leaves the match
+// waiting if the parties of the match do not fit into the teams. The caller must hold queueMu.
+func (l *Lobby) StartMatch(m *match.Match, matchLocation *match.MatchLocation) bool {
+	if l.Config.TeamCount > 0 {
+		if err := m.AssignTeams(l.Config.TeamCount); err != nil {
+			log.Printf("Match %s country %s level %d is not started: %v", m.MatchID, m.Country, m.Level, err)
+			return false
+		}
+	}
+
+	if l.Config.ReadyCheck > 0 && !l.draining {
+		l.checkReady(m)
+		return true
+	}
+
+	l.startMatch(m)
+
+	return true
+}
+
+// fitTeams tells if the parties of the players fit into the Config.TeamCount teams of a full match
+func (l *Lobby) fitTeams(players []player.Player) bool {
+	return l.Config.TeamCount == 0 || match.FitTeams(players, l.Config.TeamCount, l.Config.MaxMatchSize)
+}
+
+// startMatch resolves the tickets of the players of the match split into teams and keeps the leaderboard
+func (l *Lobby) startMatch(m *match.Match) {
+	m.Start()
+	l.releasePlayers(m.GetPlayers())
+	l.updateTickets(m.GetPlayers(), TicketMatched, m.MatchID, "")
 
 	leaderBoard := m.GetLeaderboard()
+	leaderBoard.Queue = l.Config.Queue
 	l.MatchKeeper.AddLeaderBoard(&leaderBoard)
 }
 
-// StartMatches starts the matches that have more than one player across all locations in the lobby
-// and cleans the lobby
+// StartMatches starts the matches that have at least Config.MinMatchSize players across all locations in the lobby
+// and cleans the lobby regardless of the deadlines of the players.
+// Too thin matches are merged across countries within Config.RegionFallback first.
 func (l *Lobby) StartMatches() {
-	l.matchLocations.Range(func(country, loaded interface{}) bool {
+	l.queueMu.Lock()
+	defer l.queueMu.Unlock()
+
+	l.flush(time.Now(), false, func(_ *match.Match) bool { return true })
+}
+
+// StartDueMatches starts the matches whose longest waiting player's deadline has passed
+// and removes them from the lobby. Matches with less than Config.MinMatchSize players
+// are merged across countries within Config.RegionFallback first, and if they are
+// still too thin, their players keep waiting until Config.MaxWait is up or are notified there is no match.
+func (l *Lobby) StartDueMatches(now time.Time) {
+	l.queueMu.Lock()
+	defer l.queueMu.Unlock()
+
+	l.flush(now, true, func(m *match.Match) bool { return !now.Before(m.Deadline()) })
+}
+
+// flush starts the due matches or notifies their players there is no match.
+// The players of too thin matches are carried over into the next round if carryOver is set.
+// The caller must hold queueMu.
+func (l *Lobby) flush(now time.Time, carryOver bool, due func(m *match.Match) bool) {
+	// Give the players of too thin matches a chance to play with players from nearby countries
+	l.mergeThinMatches(now, due)
+
+	l.matchLocations.Range(func(_, loaded interface{}) bool {
 		matchLocation := loaded.(*match.MatchLocation)
 
-		matchLocation.Range(func(level, loaded interface{}) bool {
-			matchToStart := loaded.(*match.Match)
+		matchLocation.Range(func(matchToStart *match.Match) bool {
+			if !due(matchToStart) {
+				return true
+			}
 
-			// If there is more than one player in the match, start the match
-			if matchToStart.GetPlayersCount() > 1 {
-				l.StartMatch(matchToStart, matchLocation)
-			} else {
-				log.Printf("Match %s country %s level %d has only one player. Skipping the match and notifying the player...\n",
+			// If there are enough players in the match and they fit into the teams, start the match
+			switch {
+			case matchToStart.GetPlayersCount() >= l.Config.MinMatchSize && l.StartMatch(matchToStart, matchLocation):
+				// The match is started or held for the ready check
+			case carryOver && l.Config.MaxWait > 0:
+				l.carryOver(now, matchLocation, matchToStart)
+				return true
+			default:
+				log.Printf("Match %s country %s level %d has only %d player(s). Skipping the match and notifying the players...\n",
 					matchToStart.MatchID,
 					matchToStart.Country,
 					matchToStart.Level,
+					matchToStart.GetPlayersCount(),
 				)
 
-				l.mu.Lock()
-				stalePlayer := matchToStart.GetPlayers()[0]
-				l.playersToNotify[stalePlayer.JoinID] = ErrNoMatch
-				l.mu.Unlock()
+				l.releasePlayers(matchToStart.GetPlayers())
+				l.updateTickets(matchToStart.GetPlayers(), TicketExpired, "", ReasonNotEnoughPlayers)
 			}
 
-			matchLocation.Delete(level)
+			matchLocation.Delete(matchToStart)
 			return true
 		})
 
-		l.matchLocations.Clear()
 		return true
 	})
 }
-
-func (l *Lobby) GetMatchByJoinID(joinID string) string {
-	l.mu.Lock()
-	defer l.mu.Unlock()
-
-	if matchID, ok := l.playersToNotify[joinID]; ok {
-		return matchID
-	}
-
-	return ""
-}
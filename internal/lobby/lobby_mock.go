@@ -41,10 +41,41 @@ func (m *MockLobbier) EXPECT() *MockLobbierMockRecorder {
 	return m.recorder
 }
 
+// AddBackfill mocks base method.
+func (m *MockLobbier) AddBackfill(b Backfill) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddBackfill", b)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddBackfill indicates an expected call of AddBackfill.
+func (mr *MockLobbierMockRecorder) AddBackfill(b any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddBackfill", reflect.TypeOf((*MockLobbier)(nil).AddBackfill), b)
+}
+
+// AddParty mocks base method.
+func (m *MockLobbier) AddParty(members []player.Player) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddParty", members)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddParty indicates an expected call of AddParty.
+func (mr *MockLobbierMockRecorder) AddParty(members any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddParty", reflect.TypeOf((*MockLobbier)(nil).AddParty), members)
+}
+
 // AddPlayer mocks base method.
-func (m *MockLobbier) AddPlayer(p player.Player) {
+func (m *MockLobbier) AddPlayer(p player.Player) error {
 	m.ctrl.T.Helper()
-	m.ctrl.Call(m, "AddPlayer", p)
+	ret := m.ctrl.Call(m, "AddPlayer", p)
+	ret0, _ := ret[0].(error)
+	return ret0
 }
 
 // AddPlayer indicates an expected call of AddPlayer.
@@ -53,18 +84,18 @@ func (mr *MockLobbierMockRecorder) AddPlayer(p any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddPlayer", reflect.TypeOf((*MockLobbier)(nil).AddPlayer), p)
 }
 
-// GetMatchByJoinID mocks base method.
-func (m *MockLobbier) GetMatchByJoinID(joinID string) string {
+// GetCandidateMatchSize mocks base method.
+func (m *MockLobbier) GetCandidateMatchSize(joinID string) int {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetMatchByJoinID", joinID)
-	ret0, _ := ret[0].(string)
+	ret := m.ctrl.Call(m, "GetCandidateMatchSize", joinID)
+	ret0, _ := ret[0].(int)
 	return ret0
 }
 
-// GetMatchByJoinID indicates an expected call of GetMatchByJoinID.
-func (mr *MockLobbierMockRecorder) GetMatchByJoinID(joinID any) *gomock.Call {
+// GetCandidateMatchSize indicates an expected call of GetCandidateMatchSize.
+func (mr *MockLobbierMockRecorder) GetCandidateMatchSize(joinID any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMatchByJoinID", reflect.TypeOf((*MockLobbier)(nil).GetMatchByJoinID), joinID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCandidateMatchSize", reflect.TypeOf((*MockLobbier)(nil).GetCandidateMatchSize), joinID)
 }
 
 // GetMatchMakingTime mocks base method.
@@ -81,6 +112,63 @@ func (mr *MockLobbierMockRecorder) GetMatchMakingTime() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMatchMakingTime", reflect.TypeOf((*MockLobbier)(nil).GetMatchMakingTime))
 }
 
+// GetTicket mocks base method.
+func (m *MockLobbier) GetTicket(joinID string) (Ticket, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTicket", joinID)
+	ret0, _ := ret[0].(Ticket)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTicket indicates an expected call of GetTicket.
+func (mr *MockLobbierMockRecorder) GetTicket(joinID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTicket", reflect.TypeOf((*MockLobbier)(nil).GetTicket), joinID)
+}
+
+// Ready mocks base method.
+func (m *MockLobbier) Ready(joinID string, accept bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Ready", joinID, accept)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Ready indicates an expected call of Ready.
+func (mr *MockLobbierMockRecorder) Ready(joinID, accept any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Ready", reflect.TypeOf((*MockLobbier)(nil).Ready), joinID, accept)
+}
+
+// RemoveBackfill mocks base method.
+func (m *MockLobbier) RemoveBackfill(matchID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveBackfill", matchID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveBackfill indicates an expected call of RemoveBackfill.
+func (mr *MockLobbierMockRecorder) RemoveBackfill(matchID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveBackfill", reflect.TypeOf((*MockLobbier)(nil).RemoveBackfill), matchID)
+}
+
+// RemovePlayer mocks base method.
+func (m *MockLobbier) RemovePlayer(joinID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemovePlayer", joinID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemovePlayer indicates an expected call of RemovePlayer.
+func (mr *MockLobbierMockRecorder) RemovePlayer(joinID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemovePlayer", reflect.TypeOf((*MockLobbier)(nil).RemovePlayer), joinID)
+}
+
 // Run mocks base method.
 func (m *MockLobbier) Run() {
 	m.ctrl.T.Helper()
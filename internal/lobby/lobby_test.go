@@ -1,6 +1,7 @@
 package lobby
 
 import (
+	"fmt"
 	"testing"
 	"time"
 
@@ -10,9 +11,23 @@ import (
 	"go.uber.org/mock/gomock"
 )
 
+// ticketOf returns the ticket of the join ID, an empty ticket if there is no such ticket
+func ticketOf(l Lobbier, joinID string) Ticket {
+	ticket, _ := l.GetTicket(joinID)
+	return ticket
+}
+
+func testConfig(waitingTime time.Duration) Config {
+	return Config{
+		WaitingTime:  waitingTime,
+		MinMatchSize: 2,
+		MaxMatchSize: 10,
+	}
+}
+
 func TestNewLobby(t *testing.T) {
 	matchKeeper := match.NewMockKeeper(gomock.NewController(t))
-	lobby := NewLobby(10*time.Second, matchKeeper)
+	lobby := NewLobby(testConfig(10*time.Second), matchKeeper, NewDefaultStrategy())
 
 	assert.NotNil(t, lobby)
 	assert.Equal(t, 10*time.Second, lobby.GetMatchMakingTime())
@@ -26,7 +41,7 @@ func TestNewLobby(t *testing.T) {
 	})
 
 	assert.True(t, isEmpty, "Expected matchLocations to be empty")
-	assert.NotNil(t, lobby.playersToNotify)
+	assert.NotNil(t, lobby.tickets)
 }
 
 func TestLobby_AddSinglePlayer(t *testing.T) {
@@ -34,7 +49,7 @@ func TestLobby_AddSinglePlayer(t *testing.T) {
 	defer mockCtrl.Finish()
 
 	mockMatchKeeper := match.NewMockKeeper(mockCtrl)
-	l := NewLobby(1*time.Minute, mockMatchKeeper)
+	l := NewLobby(testConfig(1*time.Minute), mockMatchKeeper, NewDefaultStrategy())
 
 	tests := []struct {
 		name            string
@@ -77,7 +92,7 @@ func TestLobby_AddSinglePlayer(t *testing.T) {
 			loadedMatch, ok := matchLocation.Load(tt.expectedLevel)
 			assert.True(t, ok, "Expected match at level %d", tt.expectedLevel)
 
-			m := loadedMatch.(*match.Match)
+			m := loadedMatch
 			assert.Equal(t, 1, m.GetPlayersCount(), "Expected player count to be 1")
 			assert.Equal(t, tt.player.PlayerID, m.GetPlayers()[0].PlayerID, "Expected player ID to match")
 		})
@@ -94,7 +109,7 @@ func TestLobby_AddMultiplePlayers2(t *testing.T) {
 		AddLeaderBoard(gomock.Any()).
 		Times(1)
 
-	l := NewLobby(1*time.Minute, mockKeeper)
+	l := NewLobby(testConfig(1*time.Minute), mockKeeper, NewDefaultStrategy())
 
 	tests := []struct {
 		name                       string
@@ -133,7 +148,7 @@ func TestLobby_AddMultiplePlayers2(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			l = NewLobby(1*time.Minute, mockKeeper)
+			l = NewLobby(testConfig(1*time.Minute), mockKeeper, NewDefaultStrategy())
 
 			for _, p := range tt.players {
 				l.AddPlayer(p)
@@ -145,9 +160,8 @@ func TestLobby_AddMultiplePlayers2(t *testing.T) {
 			l.matchLocations.Range(func(_, location interface{}) bool {
 				totalMatchesCount++
 				matchLocation := location.(*match.MatchLocation)
-				matchLocation.Range(func(_, m interface{}) bool {
-					match := m.(*match.Match)
-					totalPlayersCount += match.GetPlayersCount()
+				matchLocation.Range(func(m *match.Match) bool {
+					totalPlayersCount += m.GetPlayersCount()
 					return true
 				})
 				return true
@@ -159,54 +173,244 @@ func TestLobby_AddMultiplePlayers2(t *testing.T) {
 	}
 }
 
-func TestLobby_GetMatchByJoinID(t *testing.T) {
+func TestLobby_GetTicket(t *testing.T) {
 	matchKeeper := match.NewMockKeeper(gomock.NewController(t))
-	lobby := NewLobby(10*time.Second, matchKeeper)
+	lobby := NewLobby(testConfig(10*time.Second), matchKeeper, NewDefaultStrategy())
 
-	player1 := player.Player{PlayerID: "player1", JoinID: "join1", Country: "FIN", Level: 1}
+	joinedAt := time.Now().Add(-5 * time.Second)
+	player1 := player.Player{PlayerID: "player1", JoinID: "join1", Country: "FIN", Level: 1, JoinedAt: joinedAt}
 	lobby.AddPlayer(player1)
 
-	lobby.mu.Lock()
-	lobby.playersToNotify["join1"] = "match1"
-	lobby.mu.Unlock()
-
-	matchID := lobby.GetMatchByJoinID("join1")
-	assert.Equal(t, "match1", matchID)
-
-	matchID = lobby.GetMatchByJoinID("nonexistent")
-	assert.Equal(t, "", matchID)
+	ticket, err := lobby.GetTicket("join1")
+	assert.NoError(t, err)
+	assert.Equal(t, "player1", ticket.PlayerID)
+	assert.Equal(t, TicketQueued, ticket.State)
+	assert.Equal(t, joinedAt, ticket.JoinedAt)
+	assert.GreaterOrEqual(t, ticket.TimeInQueue(time.Now()), 5*time.Second)
+	assert.Equal(t, 1, lobby.GetCandidateMatchSize("join1"))
+
+	_, err = lobby.GetTicket("nonexistent")
+	assert.ErrorIs(t, err, ErrTicketNotFound)
+	assert.Equal(t, 0, lobby.GetCandidateMatchSize("nonexistent"))
 }
 
-func TestLobby_GetMatchByJoinID2(t *testing.T) {
+func TestLobby_TicketStates(t *testing.T) {
 	mockCtrl := gomock.NewController(t)
 	defer mockCtrl.Finish()
 
 	mockMatchKeeper := match.NewMockKeeper(mockCtrl)
-	l := NewLobby(1*time.Minute, mockMatchKeeper)
+	mockMatchKeeper.EXPECT().
+		AddLeaderBoard(gomock.Any()).
+		Times(1)
 
-	// Add players to the lobby and simulate match creation
-	player1 := player.Player{PlayerID: "1", JoinID: "join1", Level: 2, Country: "FIN"}
-	player2 := player.Player{PlayerID: "2", JoinID: "join2", Level: 3, Country: "FIN"}
+	l := NewLobby(testConfig(1*time.Minute), mockMatchKeeper, NewDefaultStrategy())
 
-	l.AddPlayer(player1)
-	l.AddPlayer(player2)
+	l.AddPlayer(player.Player{PlayerID: "1", JoinID: "join1", Level: 2, Country: "FIN"})
+	l.AddPlayer(player.Player{PlayerID: "2", JoinID: "join2", Level: 3, Country: "FIN"})
+	l.AddPlayer(player.Player{PlayerID: "3", JoinID: "join3", Level: 50, Country: "FIN"})
+	l.AddPlayer(player.Player{PlayerID: "4", JoinID: "join4", Level: 90, Country: "FIN"})
+	assert.Equal(t, 2, l.GetCandidateMatchSize("join2"))
 
-	matchID := "match123"
-	l.playersToNotify["join1"] = matchID
+	assert.NoError(t, l.RemovePlayer("join4"))
+	l.StartMatches()
 
 	tests := []struct {
-		name     string
-		joinID   string
-		expected string
+		name            string
+		joinID          string
+		expectedState   TicketState
+		expectedReason  Reason
+		expectedMatchID bool
 	}{
-		{"Valid joinID", "join1", matchID},
-		{"Invalid joinID", "join3", ""},
+		{"Matched ticket", "join1", TicketMatched, "", true},
+		{"Matched ticket of the same match", "join2", TicketMatched, "", true},
+		{"Expired ticket", "join3", TicketExpired, ReasonNotEnoughPlayers, false},
+		{"Cancelled ticket", "join4", TicketCancelled, ReasonLeft, false},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := l.GetMatchByJoinID(tt.joinID)
-			assert.Equal(t, tt.expected, result, "Expected match ID to match")
+			ticket, err := l.GetTicket(tt.joinID)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedState, ticket.State)
+			assert.Equal(t, tt.expectedReason, ticket.Reason)
+			assert.Equal(t, tt.expectedMatchID, ticket.MatchID != "", "Expected match ID to be set only for a matched ticket")
+			assert.Equal(t, 0, l.GetCandidateMatchSize(tt.joinID))
 		})
 	}
+
+	assert.Equal(t, ticketOf(l, "join1").MatchID, ticketOf(l, "join2").MatchID)
+}
+
+func TestLobby_StopFailsQueuedTickets(t *testing.T) {
+	l := NewLobby(testConfig(1*time.Minute), match.NewMockKeeper(gomock.NewController(t)), NewDefaultStrategy())
+
+	l.AddPlayer(player.Player{PlayerID: "1", JoinID: "join1", Level: 2, Country: "FIN"})
+
+	go l.Run()
+	l.Stop()
+
+	assert.Eventually(t, func() bool {
+		return ticketOf(l, "join1").State == TicketFailed
+	}, time.Second, 10*time.Millisecond)
+	assert.Equal(t, ReasonLobbyStopped, ticketOf(l, "join1").Reason)
+}
+
+func TestConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name          string
+		cfg           Config
+		expectedError bool
+	}{
+		{"valid config", Config{WaitingTime: time.Second, MinMatchSize: 2, MaxMatchSize: 10}, false},
+		{"valid 1v1 config", Config{WaitingTime: time.Second, MinMatchSize: 2, MaxMatchSize: 2}, false},
+		{"zero waiting time", Config{WaitingTime: 0, MinMatchSize: 2, MaxMatchSize: 10}, true},
+		{"zero min match size", Config{WaitingTime: time.Second, MinMatchSize: 0, MaxMatchSize: 10}, true},
+		{"max less than min", Config{WaitingTime: time.Second, MinMatchSize: 4, MaxMatchSize: 2}, true},
+		{"valid teams", Config{WaitingTime: time.Second, MinMatchSize: 2, MaxMatchSize: 10, TeamCount: 2}, false},
+		{"uneven teams", Config{WaitingTime: time.Second, MinMatchSize: 2, MaxMatchSize: 10, TeamCount: 3}, true},
+		{"min less than team count", Config{WaitingTime: time.Second, MinMatchSize: 2, MaxMatchSize: 12, TeamCount: 4}, true},
+		{"negative ticket TTL", Config{WaitingTime: time.Second, MinMatchSize: 2, MaxMatchSize: 10, TicketTTL: -time.Second}, true},
+		{"valid max wait", Config{WaitingTime: time.Second, MinMatchSize: 2, MaxMatchSize: 10, MaxWait: time.Minute}, false},
+		{"max wait less than waiting time", Config{WaitingTime: time.Minute, MinMatchSize: 2, MaxMatchSize: 10, MaxWait: time.Second}, true},
+		{"negative ready check", Config{WaitingTime: time.Second, MinMatchSize: 2, MaxMatchSize: 10, ReadyCheck: -time.Second}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.cfg.Validate()
+			assert.Equal(t, tt.expectedError, err != nil, "Unexpected validation result: %v", err)
+		})
+	}
+}
+
+func TestLobby_MatchSizeLimits(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockKeeper := match.NewMockKeeper(mockCtrl)
+	mockKeeper.EXPECT().
+		AddLeaderBoard(gomock.Any()).
+		Times(2)
+
+	l := NewLobby(Config{WaitingTime: time.Minute, MinMatchSize: 3, MaxMatchSize: 4}, mockKeeper, NewDefaultStrategy())
+
+	// 4 players in FIN start a match right away
+	for _, id := range []string{"1", "2", "3", "4"} {
+		l.AddPlayer(player.Player{PlayerID: id, JoinID: "fin" + id, Level: 5, Country: "FIN"})
+	}
+
+	// 3 players in USA start a match on the tick, 2 players in SWE do not
+	for _, id := range []string{"1", "2", "3"} {
+		l.AddPlayer(player.Player{PlayerID: "usa" + id, JoinID: "usa" + id, Level: 5, Country: "USA"})
+	}
+	for _, id := range []string{"1", "2"} {
+		l.AddPlayer(player.Player{PlayerID: "swe" + id, JoinID: "swe" + id, Level: 5, Country: "SWE"})
+	}
+
+	assert.Equal(t, TicketMatched, ticketOf(l, "fin1").State, "Expected the full match to be started")
+	assert.Equal(t, TicketQueued, ticketOf(l, "usa1").State, "Expected the match to wait for the tick")
+
+	l.StartMatches()
+
+	assert.Equal(t, TicketMatched, ticketOf(l, "usa1").State)
+	assert.Equal(t, TicketExpired, ticketOf(l, "swe1").State)
+	assert.Equal(t, TicketExpired, ticketOf(l, "swe2").State)
+}
+
+func TestLobby_StartMatchWithTeams(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	var leaderBoard *match.LeaderBoard
+	mockKeeper := match.NewMockKeeper(mockCtrl)
+	mockKeeper.EXPECT().
+		AddLeaderBoard(gomock.Any()).
+		Times(1).
+		Do(func(lb *match.LeaderBoard) { leaderBoard = lb })
+
+	l := NewLobby(Config{WaitingTime: time.Minute, MinMatchSize: 2, MaxMatchSize: 4, TeamCount: 2}, mockKeeper, NewDefaultStrategy())
+
+	for i, level := range []int{4, 5, 5, 4} {
+		l.AddPlayer(player.Player{PlayerID: fmt.Sprint(i), JoinID: fmt.Sprint("join", i), Level: level, Country: "FIN"})
+	}
+
+	assert.NotNil(t, leaderBoard, "Expected the match to be started")
+	assert.Len(t, leaderBoard.Teams, 2)
+	for _, team := range leaderBoard.Teams {
+		assert.Len(t, team.PlayerIDs, 2)
+		assert.Equal(t, 4.5, team.AverageLevel)
+	}
+}
+
+func TestLobby_StartDueMatches(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockKeeper := match.NewMockKeeper(mockCtrl)
+	mockKeeper.EXPECT().
+		AddLeaderBoard(gomock.Any()).
+		Times(1)
+
+	l := NewLobby(testConfig(30*time.Second), mockKeeper, NewDefaultStrategy())
+
+	now := time.Now()
+	// The deadline of the single player has passed
+	l.AddPlayer(player.Player{PlayerID: "1", JoinID: "join1", Level: 10, Country: "FIN", JoinedAt: now.Add(-40 * time.Second)})
+	// The deadline of the longest waiting player of the match has passed
+	l.AddPlayer(player.Player{PlayerID: "2", JoinID: "join2", Level: 20, Country: "FIN", JoinedAt: now.Add(-31 * time.Second)})
+	l.AddPlayer(player.Player{PlayerID: "3", JoinID: "join3", Level: 20, Country: "FIN", JoinedAt: now})
+	// The players joined just before the deadline of the others
+	l.AddPlayer(player.Player{PlayerID: "4", JoinID: "join4", Level: 30, Country: "FIN", JoinedAt: now.Add(-time.Second)})
+	l.AddPlayer(player.Player{PlayerID: "5", JoinID: "join5", Level: 30, Country: "FIN", JoinedAt: now.Add(-time.Second)})
+
+	l.StartDueMatches(now)
+
+	assert.Equal(t, TicketExpired, ticketOf(l, "join1").State)
+	assert.Equal(t, TicketMatched, ticketOf(l, "join2").State)
+	assert.Equal(t, ticketOf(l, "join2").MatchID, ticketOf(l, "join3").MatchID)
+	assert.Equal(t, TicketQueued, ticketOf(l, "join4").State, "Expected the player to keep waiting")
+	assert.Equal(t, TicketQueued, ticketOf(l, "join5").State, "Expected the player to keep waiting")
+
+	location, _ := l.matchLocations.Load("FIN")
+	_, ok := location.(*match.MatchLocation).Load(30)
+	assert.True(t, ok, "Expected the match at level 30 to keep waiting")
+}
+
+func TestLobby_RemovePlayer(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockKeeper := match.NewMockKeeper(mockCtrl)
+	mockKeeper.EXPECT().
+		AddLeaderBoard(gomock.Any()).
+		Times(1)
+
+	l := NewLobby(testConfig(30*time.Second), mockKeeper, NewDefaultStrategy())
+
+	l.AddPlayer(player.Player{PlayerID: "1", JoinID: "join1", Level: 10, Country: "FIN"})
+	l.AddPlayer(player.Player{PlayerID: "2", JoinID: "join2", Level: 11, Country: "FIN"})
+
+	assert.NoError(t, l.RemovePlayer("join1"))
+	assert.Equal(t, TicketCancelled, ticketOf(l, "join1").State)
+	assert.ErrorIs(t, l.RemovePlayer("join1"), ErrTicketNotFound)
+
+	// The match is moved to the level bucket of the remaining player
+	location, _ := l.matchLocations.Load("FIN")
+	_, ok := location.(*match.MatchLocation).Load(10)
+	assert.False(t, ok, "Expected the match to leave level 10")
+	loaded, ok := location.(*match.MatchLocation).Load(11)
+	assert.True(t, ok, "Expected the match to move to level 11")
+	assert.Equal(t, 1, loaded.GetPlayersCount())
+
+	// The empty match is removed from the lobby
+	assert.NoError(t, l.RemovePlayer("join2"))
+	_, ok = location.(*match.MatchLocation).Load(11)
+	assert.False(t, ok, "Expected the empty match to be removed")
+
+	l.AddPlayer(player.Player{PlayerID: "3", JoinID: "join3", Level: 20, Country: "FIN"})
+	l.AddPlayer(player.Player{PlayerID: "4", JoinID: "join4", Level: 20, Country: "FIN"})
+	l.StartMatches()
+
+	assert.ErrorIs(t, l.RemovePlayer("join3"), ErrAlreadyMatched)
+	assert.ErrorIs(t, l.RemovePlayer("unknown"), ErrTicketNotFound)
 }
@@ -0,0 +1,67 @@
+package lobby
+
+import (
+	"math"
+
+	"github.com/TanyEm/match-maker/v2/internal/player"
+	"github.com/TanyEm/match-maker/v2/internal/rating"
+)
+
+// PartyLevelMode is how the level and the rating of a party is calculated from its members
+type PartyLevelMode string
+
+const (
+	// PartyLevelAverage matches a party by the average level and rating of its members
+	PartyLevelAverage PartyLevelMode = "average"
+	// PartyLevelMax matches a party by the level and rating of its strongest member
+	PartyLevelMax PartyLevelMode = "max"
+)
+
+// partyRepresentative returns a player the strategy places the party by: the first member
+// with the average or the max level and rating of the party. The level based rating is used
+// for the members who have no rating if any member of the party has one.
+func partyRepresentative(members []player.Player, mode PartyLevelMode) player.Player {
+	representative := members[0]
+	if len(members) == 1 {
+		return representative
+	}
+
+	rated := false
+	levels, ratings := 0.0, 0.0
+	maxLevel, maxRating := 0, 0.0
+	for _, p := range members {
+		r := p.Rating
+		if r != 0 {
+			rated = true
+		} else {
+			r = rating.FromLevel(p.Level)
+		}
+
+		levels += float64(p.Level)
+		ratings += r
+		maxLevel = max(maxLevel, p.Level)
+		maxRating = max(maxRating, r)
+	}
+
+	if mode == PartyLevelMax {
+		representative.Level, representative.Rating = maxLevel, maxRating
+	} else {
+		representative.Level = int(math.Round(levels / float64(len(members))))
+		representative.Rating = ratings / float64(len(members))
+	}
+
+	if !rated {
+		representative.Rating = 0
+	}
+
+	return representative
+}
+
+// maxPartySize returns the biggest party that fits into a match or into a team if the match has teams
+func (l *Lobby) maxPartySize() int {
+	if l.Config.TeamCount > 0 {
+		return l.Config.MaxMatchSize / l.Config.TeamCount
+	}
+
+	return l.Config.MaxMatchSize
+}
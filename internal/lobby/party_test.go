@@ -0,0 +1,178 @@
+package lobby
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/TanyEm/match-maker/v2/internal/match"
+	"github.com/TanyEm/match-maker/v2/internal/player"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestPartyRepresentative(t *testing.T) {
+	members := []player.Player{
+		{PlayerID: "1", Level: 10, Country: "FIN"},
+		{PlayerID: "2", Level: 21, Country: "SWE", Rating: 1800},
+	}
+
+	average := partyRepresentative(members, PartyLevelAverage)
+	assert.Equal(t, "1", average.PlayerID)
+	assert.Equal(t, "FIN", average.Country)
+	assert.Equal(t, 16, average.Level)
+	assert.Equal(t, 1450.0, average.Rating)
+
+	strongest := partyRepresentative(members, PartyLevelMax)
+	assert.Equal(t, 21, strongest.Level)
+	assert.Equal(t, 1800.0, strongest.Rating)
+
+	unrated := partyRepresentative([]player.Player{{Level: 10}, {Level: 20}}, PartyLevelAverage)
+	assert.Equal(t, 15, unrated.Level)
+	assert.Equal(t, 0.0, unrated.Rating)
+}
+
+func TestLobby_AddParty(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockKeeper := match.NewMockKeeper(mockCtrl)
+	mockKeeper.EXPECT().
+		AddLeaderBoard(gomock.Any()).
+		Times(2)
+
+	l := NewLobby(Config{WaitingTime: time.Minute, MinMatchSize: 2, MaxMatchSize: 4}, mockKeeper, NewDefaultStrategy())
+
+	l.AddPlayer(player.Player{PlayerID: "1", JoinID: "join1", Level: 5, Country: "FIN"})
+	l.AddPlayer(player.Player{PlayerID: "2", JoinID: "join2", Level: 5, Country: "FIN"})
+
+	// The party of 3 does not fit into the match of 2 players, so it waits in its own match
+	party := []player.Player{
+		{PlayerID: "3", JoinID: "join3", Level: 4, Country: "FIN", PartyID: "party1"},
+		{PlayerID: "4", JoinID: "join4", Level: 5, Country: "FIN", PartyID: "party1"},
+		{PlayerID: "5", JoinID: "join5", Level: 6, Country: "FIN", PartyID: "party1"},
+	}
+	assert.NoError(t, l.AddParty(party))
+
+	location, _ := l.matchLocations.Load("FIN")
+	matches := 0
+	location.(*match.MatchLocation).Range(func(_ *match.Match) bool {
+		matches++
+		return true
+	})
+	assert.Equal(t, 2, matches, "Expected the party to wait in a separate match")
+
+	// The next players fill the first match, and then the match of the party
+	l.AddPlayer(player.Player{PlayerID: "6", JoinID: "join6", Level: 5, Country: "FIN"})
+	l.AddPlayer(player.Player{PlayerID: "7", JoinID: "join7", Level: 5, Country: "FIN"})
+	assert.Equal(t, TicketMatched, ticketOf(l, "join1").State, "Expected the first match to be started")
+	assert.Equal(t, TicketQueued, ticketOf(l, "join3").State, "Expected the party to wait")
+
+	l.AddPlayer(player.Player{PlayerID: "8", JoinID: "join8", Level: 6, Country: "FIN"})
+
+	partyMatchID := ticketOf(l, "join3").MatchID
+	assert.NotEqual(t, "", partyMatchID, "Expected the party match to be started")
+	assert.Equal(t, partyMatchID, ticketOf(l, "join4").MatchID)
+	assert.Equal(t, partyMatchID, ticketOf(l, "join5").MatchID)
+
+	err := l.AddParty(make([]player.Player, 5))
+	assert.True(t, errors.Is(err, ErrPartyTooBig))
+}
+
+func TestLobby_AddPartyOverflow(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockKeeper := match.NewMockKeeper(mockCtrl)
+	l := NewLobby(testConfig(30*time.Second), mockKeeper, NewDefaultStrategy())
+
+	party := func(partyID string, size, level int) []player.Player {
+		members := []player.Player{}
+		for i := 0; i < size; i++ {
+			id := fmt.Sprintf("%s-%d", partyID, i)
+			members = append(members, player.Player{PlayerID: id, JoinID: id, Level: level, Country: "FIN", PartyID: partyID})
+		}
+		return members
+	}
+
+	// The party of 2 does not fit into the match of the party of 9, so it waits in another match of its own bucket
+	assert.NoError(t, l.AddParty(party("party1", 9, 1)))
+	assert.NoError(t, l.AddParty(party("party2", 2, 1)))
+	l.AddPlayer(player.Player{PlayerID: "3", JoinID: "join3", Level: 4, Country: "FIN"})
+
+	location, _ := l.matchLocations.Load("FIN")
+	matchLocation := location.(*match.MatchLocation)
+	assert.Len(t, matchLocation.Bucket(2), 2, "Expected both parties to wait at their own bucket")
+	assert.Len(t, matchLocation.Bucket(4), 1, "Expected the player to wait alone")
+
+	// Every waiting player can compete in the bucket of the match
+	matchLocation.Range(func(m *match.Match) bool {
+		for _, p := range m.GetPlayers() {
+			assert.Contains(t, l.Strategy.Candidates(p), m.Level, "Expected player %s level %d out of the band of match level %d", p.PlayerID, p.Level, m.Level)
+		}
+		return true
+	})
+
+	// The joining players fill the oldest match of the bucket first, and then the overflow match
+	mockKeeper.EXPECT().
+		AddLeaderBoard(gomock.Any()).
+		Times(1)
+	l.AddPlayer(player.Player{PlayerID: "4", JoinID: "join4", Level: 2, Country: "FIN"})
+	l.AddPlayer(player.Player{PlayerID: "5", JoinID: "join5", Level: 2, Country: "FIN"})
+
+	overflow := matchLocation.Bucket(2)
+	if assert.Len(t, overflow, 1, "Expected the full match to start") {
+		assert.Equal(t, 3, overflow[0].GetPlayersCount())
+	}
+	assert.Equal(t, 1, matchLocation.Bucket(4)[0].GetPlayersCount())
+}
+
+func TestLobby_AddPartyTeams(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	var leaderBoard *match.LeaderBoard
+	mockKeeper := match.NewMockKeeper(mockCtrl)
+	mockKeeper.EXPECT().
+		AddLeaderBoard(gomock.Any()).
+		Times(1).
+		Do(func(lb *match.LeaderBoard) { leaderBoard = lb })
+
+	l := NewLobby(Config{WaitingTime: time.Minute, MinMatchSize: 2, MaxMatchSize: 6, TeamCount: 2}, mockKeeper, NewDefaultStrategy())
+
+	party := func(partyID string, size int, country string) []player.Player {
+		members := []player.Player{}
+		for i := 0; i < size; i++ {
+			id := fmt.Sprintf("%s-%d", partyID, i)
+			members = append(members, player.Player{PlayerID: id, JoinID: id, Level: 10, Country: country, PartyID: partyID})
+		}
+		return members
+	}
+
+	// Three parties of 2 do not fit into 2 teams of 3, so the third party waits in another match
+	assert.NoError(t, l.AddParty(party("party1", 2, "FIN")))
+	assert.NoError(t, l.AddParty(party("party2", 2, "FIN")))
+	assert.NoError(t, l.AddParty(party("party3", 2, "FIN")))
+	assert.Equal(t, 4, l.GetCandidateMatchSize("party1-0"))
+	assert.Equal(t, 2, l.GetCandidateMatchSize("party3-0"))
+
+	// The players fill the first match, and its teams are never bigger than 3
+	l.AddPlayer(player.Player{PlayerID: "5", JoinID: "join5", Level: 10, Country: "FIN"})
+	l.AddPlayer(player.Player{PlayerID: "6", JoinID: "join6", Level: 10, Country: "FIN"})
+	if assert.NotNil(t, leaderBoard, "Expected the match to be started") {
+		for _, team := range leaderBoard.Teams {
+			assert.Len(t, team.PlayerIDs, 3)
+		}
+	}
+	assert.Equal(t, 2, l.GetCandidateMatchSize("party3-0"))
+
+	// The parties do not fit into the smaller teams of the matches at the deadline, so the matches are not started:
+	// the party of 2 into 2 teams of 1, and the party of 3 and a player into 2 teams of 2
+	assert.NoError(t, l.AddParty(party("party4", 3, "SWE")))
+	l.AddPlayer(player.Player{PlayerID: "7", JoinID: "join7", Level: 10, Country: "SWE"})
+	l.StartDueMatches(time.Now().Add(2 * time.Minute))
+	assert.Equal(t, TicketExpired, ticketOf(l, "party3-0").State)
+	assert.Equal(t, TicketExpired, ticketOf(l, "party4-0").State)
+	assert.Equal(t, TicketExpired, ticketOf(l, "join7").State)
+}
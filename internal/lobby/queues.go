@@ -0,0 +1,305 @@
+package lobby
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/TanyEm/match-maker/v2/internal/player"
+)
+
+var (
+	ErrUnknownQueue = errors.New("unknown queue")
+	ErrQueueRemoved = errors.New("queue can not be removed while it runs")
+)
+
+// Queues hosts several independently configured lobbies, one per queue (game mode),
+// and routes the players to the lobby of their queue. Players who do not name a queue
+// join the default queue. A player waits in a single queue at a time, the lobbies share
+// the waiting players and the penalties. Queues implements Lobbier, so it is used in place of a single lobby.
+type Queues struct {
+	// mu guards defaultQueue, lobbies and running, the queues are replaced on a reload
+	mu           sync.RWMutex
+	defaultQueue string
+	lobbies      map[string]*Lobby
+	running      bool
+	wg           sync.WaitGroup
+	roster       *roster
+}
+
+// NewQueues creates the queues from the lobbies by the queue names. The default queue must be one of them.
+func NewQueues(defaultQueue string, lobbies map[string]*Lobby) (*Queues, error) {
+	if _, ok := lobbies[defaultQueue]; !ok {
+		return nil, fmt.Errorf("default queue %q: %w", defaultQueue, ErrUnknownQueue)
+	}
+
+	q := &Queues{
+		defaultQueue: defaultQueue,
+		lobbies:      lobbies,
+		roster:       newRoster(),
+	}
+	for _, l := range lobbies {
+		l.roster = q.roster
+	}
+
+	return q, nil
+}
+
+// Lobby returns the lobby of the queue, the default queue if the name is empty
+func (q *Queues) Lobby(queue string) (*Lobby, error) {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	if queue == "" {
+		queue = q.defaultQueue
+	}
+
+	l, ok := q.lobbies[queue]
+	if !ok {
+		return nil, fmt.Errorf("queue %q: %w", queue, ErrUnknownQueue)
+	}
+
+	return l, nil
+}
+
+func (q *Queues) AddBackfill(b Backfill) (int, error) {
+	l, err := q.Lobby(b.Queue)
+	if err != nil {
+		return 0, err
+	}
+
+	return l.AddBackfill(b)
+}
+
+func (q *Queues) AddPlayer(p player.Player) error {
+	l, err := q.Lobby(p.Queue)
+	if err != nil {
+		return err
+	}
+
+	q.replace(l, []player.Player{p})
+
+	return l.AddPlayer(p)
+}
+
+// AddParty adds the party to the queue of its first member
+func (q *Queues) AddParty(members []player.Player) error {
+	if len(members) == 0 {
+		return ErrEmptyParty
+	}
+
+	l, err := q.Lobby(members[0].Queue)
+	if err != nil {
+		return err
+	}
+
+	q.replace(l, members)
+
+	return l.AddParty(members)
+}
+
+// replace cancels the tickets the players hold in the other queues if the lobby replaces
+// the duplicates, otherwise the lobby rejects the players, see Config.Duplicates
+func (q *Queues) replace(l *Lobby, members []player.Player) {
+	l.queueMu.Lock()
+	policy := l.Config.Duplicates
+	l.queueMu.Unlock()
+
+	if policy != DuplicateReplace {
+		return
+	}
+
+	for _, active := range q.roster.elsewhere(members, l) {
+		active.lobby.replaceTicket(active.joinID)
+	}
+}
+
+func (q *Queues) GetCandidateMatchSize(joinID string) int {
+	for _, l := range q.all() {
+		if size := l.GetCandidateMatchSize(joinID); size > 0 {
+			return size
+		}
+	}
+
+	return 0
+}
+
+// GetMatchMakingTime returns the longest time a player of any of the queues waits for a match,
+// so a player of any queue is notified before the time is up
+func (q *Queues) GetMatchMakingTime() time.Duration {
+	var longest time.Duration
+	for _, l := range q.all() {
+		longest = max(longest, l.GetMatchMakingTime())
+	}
+
+	return longest
+}
+
+func (q *Queues) GetTicket(joinID string) (Ticket, error) {
+	result := ErrTicketNotFound
+	for _, l := range q.all() {
+		ticket, err := l.GetTicket(joinID)
+		if err == nil {
+			return ticket, nil
+		}
+
+		if errors.Is(err, ErrTicketPruned) {
+			result = err
+		}
+	}
+
+	return Ticket{}, result
+}
+
+// Ready answers the ready check in the queue of the ticket
+func (q *Queues) Ready(joinID string, accept bool) error {
+	result := ErrTicketNotFound
+	for _, l := range q.all() {
+		err := l.Ready(joinID, accept)
+		if err == nil || errors.Is(err, ErrNoReadyCheck) {
+			return err
+		}
+
+		if errors.Is(err, ErrTicketPruned) {
+			result = err
+		}
+	}
+
+	return result
+}
+
+func (q *Queues) RemoveBackfill(matchID string) error {
+	for _, l := range q.all() {
+		if err := l.RemoveBackfill(matchID); err == nil {
+			return nil
+		}
+	}
+
+	return ErrBackfillNotFound
+}
+
+func (q *Queues) RemovePlayer(joinID string) error {
+	result := ErrTicketNotFound
+	for _, l := range q.all() {
+		err := l.RemovePlayer(joinID)
+		if err == nil {
+			return nil
+		}
+
+		if errors.Is(err, ErrAlreadyMatched) {
+			result = err
+		}
+	}
+
+	return result
+}
+
+// Run runs the lobbies of all queues until they are stopped
+func (q *Queues) Run() {
+	q.mu.Lock()
+	q.running = true
+	for _, l := range q.lobbies {
+		q.run(l)
+	}
+	q.mu.Unlock()
+
+	q.wg.Wait()
+}
+
+// run runs the lobby in the background. The caller must hold mu.
+func (q *Queues) run(l *Lobby) {
+	q.wg.Add(1)
+	go func() {
+		defer q.wg.Done()
+		l.Run()
+	}()
+}
+
+func (q *Queues) Stop() {
+	for _, l := range q.all() {
+		l.Stop()
+	}
+}
+
+// Drain drains the lobbies of all queues, see Lobby.Drain
+func (q *Queues) Drain() {
+	for _, l := range q.all() {
+		l.Drain()
+	}
+}
+
+// Pending returns the tickets of all queues that are still queued or in the ready check in the order of the joins
+func (q *Queues) Pending() []Ticket {
+	pending := []Ticket{}
+	for _, l := range q.all() {
+		pending = append(pending, l.Pending()...)
+	}
+
+	sort.Slice(pending, func(i, j int) bool {
+		return pending[i].JoinedAt.Before(pending[j].JoinedAt)
+	})
+
+	return pending
+}
+
+// Reconfigure applies the configs and strategies of the reloaded lobbies to the running queues
+// without dropping the queued tickets, see Lobby.Reconfigure. The new queues are started,
+// and a queue can not be removed while it runs, as its players would lose their tickets.
+// Nothing is changed if any of the configs is invalid.
+func (q *Queues) Reconfigure(defaultQueue string, lobbies map[string]*Lobby) error {
+	if _, ok := lobbies[defaultQueue]; !ok {
+		return fmt.Errorf("default queue %q: %w", defaultQueue, ErrUnknownQueue)
+	}
+
+	for name, l := range lobbies {
+		if err := l.Config.Validate(); err != nil {
+			return fmt.Errorf("queue %q: %w", name, err)
+		}
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for name := range q.lobbies {
+		if _, ok := lobbies[name]; !ok {
+			return fmt.Errorf("queue %q: %w", name, ErrQueueRemoved)
+		}
+	}
+
+	for name, reloaded := range lobbies {
+		l, ok := q.lobbies[name]
+		if !ok {
+			reloaded.roster = q.roster
+			q.lobbies[name] = reloaded
+			if q.running {
+				q.run(reloaded)
+			}
+			log.Printf("Queue %s is added", name)
+			continue
+		}
+
+		if err := l.Reconfigure(reloaded.Config, reloaded.Strategy); err != nil {
+			return fmt.Errorf("queue %q: %w", name, err)
+		}
+	}
+
+	q.defaultQueue = defaultQueue
+
+	return nil
+}
+
+// all returns the lobbies of all queues
+func (q *Queues) all() []*Lobby {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	lobbies := make([]*Lobby, 0, len(q.lobbies))
+	for _, l := range q.lobbies {
+		lobbies = append(lobbies, l)
+	}
+
+	return lobbies
+}
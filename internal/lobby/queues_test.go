@@ -0,0 +1,122 @@
+package lobby
+
+import (
+	"testing"
+	"time"
+
+	"github.com/TanyEm/match-maker/v2/internal/match"
+	"github.com/TanyEm/match-maker/v2/internal/player"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestNewQueues(t *testing.T) {
+	l := NewLobby(testConfig(time.Minute), match.NewMockKeeper(gomock.NewController(t)), NewDefaultStrategy())
+
+	_, err := NewQueues("ranked", map[string]*Lobby{"casual": l})
+	assert.ErrorIs(t, err, ErrUnknownQueue)
+
+	q, err := NewQueues("casual", map[string]*Lobby{"casual": l})
+	assert.NoError(t, err)
+	assert.NotNil(t, q)
+}
+
+func TestQueues(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockKeeper := match.NewMockKeeper(mockCtrl)
+	mockKeeper.EXPECT().
+		AddLeaderBoard(gomock.Any()).
+		Times(1).
+		Do(func(leaderBoard *match.LeaderBoard) {
+			assert.Equal(t, "ranked", leaderBoard.Queue)
+			assert.Len(t, leaderBoard.Players, 2)
+		})
+
+	casualConfig := testConfig(30 * time.Second)
+	casualConfig.Queue = "casual"
+	casual := NewLobby(casualConfig, mockKeeper, NewDefaultStrategy())
+
+	rankedConfig := Config{Queue: "ranked", WaitingTime: time.Minute, MinMatchSize: 2, MaxMatchSize: 2}
+	ranked := NewLobby(rankedConfig, mockKeeper, NewRatingStrategy(100))
+
+	q, err := NewQueues("casual", map[string]*Lobby{"casual": casual, "ranked": ranked})
+	assert.NoError(t, err)
+
+	assert.Equal(t, time.Minute, q.GetMatchMakingTime(), "Expected the longest waiting time of the queues")
+
+	// Players of different queues are never matched together
+	assert.NoError(t, q.AddPlayer(player.Player{PlayerID: "1", JoinID: "join1", Level: 10, Country: "FIN"}))
+	assert.NoError(t, q.AddPlayer(player.Player{PlayerID: "2", JoinID: "join2", Level: 10, Country: "FIN", Queue: "ranked"}))
+	assert.NoError(t, q.AddPlayer(player.Player{PlayerID: "3", JoinID: "join3", Level: 10, Country: "FIN", Queue: "ranked"}))
+	assert.ErrorIs(t, q.AddPlayer(player.Player{PlayerID: "4", JoinID: "join4", Level: 10, Country: "FIN", Queue: "unknown"}), ErrUnknownQueue)
+
+	assert.Equal(t, TicketQueued, ticketOf(q, "join1").State)
+	assert.Equal(t, TicketMatched, ticketOf(q, "join2").State)
+	assert.Equal(t, ticketOf(q, "join2").MatchID, ticketOf(q, "join3").MatchID)
+
+	// Tickets are cancelled in the queue they wait in
+	assert.NoError(t, q.RemovePlayer("join1"))
+	assert.Equal(t, TicketCancelled, ticketOf(q, "join1").State)
+	assert.ErrorIs(t, q.RemovePlayer("join2"), ErrAlreadyMatched)
+	assert.ErrorIs(t, q.RemovePlayer("join4"), ErrTicketNotFound)
+}
+
+func TestQueues_Reconfigure(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockKeeper := match.NewMockKeeper(mockCtrl)
+	newLobby := func(queue string, waitingTime time.Duration) *Lobby {
+		cfg := testConfig(waitingTime)
+		cfg.Queue = queue
+		return NewLobby(cfg, mockKeeper, NewDefaultStrategy())
+	}
+
+	casual := newLobby("casual", 30*time.Second)
+	q, err := NewQueues("casual", map[string]*Lobby{"casual": casual})
+	assert.NoError(t, err)
+	assert.NoError(t, q.AddPlayer(player.Player{PlayerID: "1", JoinID: "join1", Level: 10, Country: "FIN"}))
+
+	// A running queue can not be removed
+	err = q.Reconfigure("ranked", map[string]*Lobby{"ranked": newLobby("ranked", time.Minute)})
+	assert.ErrorIs(t, err, ErrQueueRemoved)
+
+	invalid := newLobby("ranked", 0)
+	err = q.Reconfigure("casual", map[string]*Lobby{"casual": newLobby("casual", time.Minute), "ranked": invalid})
+	assert.EqualError(t, err, `queue "ranked": waiting time must be positive, got 0s`)
+	assert.Equal(t, 30*time.Second, q.GetMatchMakingTime(), "Expected nothing to change")
+
+	// The existing queue is reconfigured in place and the new queue is added
+	err = q.Reconfigure("ranked", map[string]*Lobby{"casual": newLobby("casual", time.Minute), "ranked": newLobby("ranked", time.Minute)})
+	assert.NoError(t, err)
+	assert.Equal(t, time.Minute, casual.GetMatchMakingTime())
+	assert.Equal(t, TicketQueued, ticketOf(q, "join1").State)
+
+	l, err := q.Lobby("")
+	assert.NoError(t, err)
+	assert.Equal(t, "ranked", l.Config.Queue)
+}
+
+func TestQueues_Drain(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockKeeper := match.NewMockKeeper(mockCtrl)
+	casual := NewLobby(testConfig(time.Minute), mockKeeper, NewDefaultStrategy())
+	ranked := NewLobby(testConfig(time.Minute), mockKeeper, NewDefaultStrategy())
+
+	q, err := NewQueues("casual", map[string]*Lobby{"casual": casual, "ranked": ranked})
+	assert.NoError(t, err)
+
+	now := time.Now()
+	assert.NoError(t, q.AddPlayer(player.Player{PlayerID: "1", JoinID: "join1", Level: 10, Country: "FIN", JoinedAt: now}))
+	assert.NoError(t, q.AddPlayer(player.Player{PlayerID: "2", JoinID: "join2", Level: 10, Country: "FIN", Queue: "ranked", JoinedAt: now.Add(-time.Second)}))
+	assert.Len(t, q.Pending(), 2)
+	assert.Equal(t, "join2", q.Pending()[0].JoinID)
+
+	q.Drain()
+	assert.Empty(t, q.Pending())
+	assert.ErrorIs(t, q.AddPlayer(player.Player{PlayerID: "3", JoinID: "join3", Level: 10, Country: "FIN", Queue: "ranked"}), ErrDraining)
+}
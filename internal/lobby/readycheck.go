@@ -0,0 +1,190 @@
+package lobby
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	"github.com/TanyEm/match-maker/v2/internal/match"
+	"github.com/TanyEm/match-maker/v2/internal/player"
+)
+
+var (
+	ErrNoReadyCheck = errors.New("ticket is not in a ready check")
+	ErrPenalised    = errors.New("player is penalised for declining a match")
+)
+
+// readyCheck is a full match waiting for its players to accept it, see Config.ReadyCheck
+type readyCheck struct {
+	match    *match.Match
+	deadline time.Time
+	// accepted are the join IDs of the players who have accepted the match
+	accepted map[string]bool
+}
+
+// has tells the player with the join ID is in the match of the ready check
+func (c *readyCheck) has(joinID string) bool {
+	for _, p := range c.match.GetPlayers() {
+		if p.JoinID == joinID {
+			return true
+		}
+	}
+
+	return false
+}
+
+// checkReady holds the full match until all its players accept it. The players stay
+// in the lobby, so they can not join again. The caller must hold queueMu.
+func (l *Lobby) checkReady(m *match.Match) {
+	check := &readyCheck{
+		match:    m,
+		deadline: time.Now().Add(l.Config.ReadyCheck),
+		accepted: make(map[string]bool),
+	}
+	l.readyChecks = append(l.readyChecks, check)
+	log.Printf("Match %s is full. Waiting for %d players to accept it until %s", m.MatchID, m.GetPlayersCount(), check.deadline.Format(time.RFC3339))
+
+	l.updateTickets(m.GetPlayers(), TicketReadyCheck, "", "")
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for _, p := range m.GetPlayers() {
+		l.tickets[p.JoinID].ReadyBy = check.deadline
+	}
+}
+
+// Ready accepts or declines the match of the ticket in the ready check. The match starts
+// once all its players have accepted it. A player who declines the match is dropped and
+// penalised for Config.DeclinePenalty, and the other players go back to the front of the queue.
+func (l *Lobby) Ready(joinID string, accept bool) error {
+	l.queueMu.Lock()
+	defer l.queueMu.Unlock()
+
+	return l.answerReady(joinID, accept, ReasonDeclined)
+}
+
+// answerReady is Ready for the caller holding queueMu, the declined ticket is cancelled for the reason
+func (l *Lobby) answerReady(joinID string, accept bool, reason Reason) error {
+	for _, check := range l.readyChecks {
+		if !check.has(joinID) {
+			continue
+		}
+
+		if !accept {
+			l.dropReady(check, map[string]bool{joinID: true}, reason)
+			return nil
+		}
+
+		check.accepted[joinID] = true
+		l.mu.Lock()
+		l.tickets[joinID].Accepted = true
+		l.mu.Unlock()
+
+		if len(check.accepted) == check.match.GetPlayersCount() {
+			l.removeReadyCheck(check)
+			l.startMatch(check.match)
+		}
+
+		return nil
+	}
+
+	if _, err := l.GetTicket(joinID); err != nil {
+		return err
+	}
+
+	return ErrNoReadyCheck
+}
+
+// ExpireReadyChecks drops the players who have not accepted their matches in time,
+// the players who have accepted them go back to the front of the queue
+func (l *Lobby) ExpireReadyChecks(now time.Time) {
+	l.queueMu.Lock()
+	defer l.queueMu.Unlock()
+
+	for _, check := range append([]*readyCheck{}, l.readyChecks...) {
+		if now.Before(check.deadline) {
+			continue
+		}
+
+		dropped := make(map[string]bool)
+		for _, p := range check.match.GetPlayers() {
+			if !check.accepted[p.JoinID] {
+				dropped[p.JoinID] = true
+			}
+		}
+
+		l.dropReady(check, dropped, ReasonReadyTimeout)
+	}
+
+	l.roster.prunePenalties(now)
+}
+
+// dropReady cancels the ready check, the tickets of the dropped players are cancelled for the reason
+// and the other players are queued again. The caller must hold queueMu.
+func (l *Lobby) dropReady(check *readyCheck, dropped map[string]bool, reason Reason) {
+	l.removeReadyCheck(check)
+
+	remaining := []player.Player{}
+	for _, p := range check.match.GetPlayers() {
+		if !dropped[p.JoinID] {
+			remaining = append(remaining, p)
+			continue
+		}
+
+		log.Printf("Player %s dropped from the match %s: %s", p.PlayerID, check.match.MatchID, reason)
+		l.releasePlayers([]player.Player{p})
+		l.updateTickets([]player.Player{p}, TicketCancelled, "", reason)
+
+		// A player who joins again instead of the ticket is not walking away from the match
+		if reason != ReasonReplaced && l.Config.DeclinePenalty > 0 {
+			l.roster.penalise(p.PlayerID, time.Now().Add(l.Config.DeclinePenalty))
+		}
+	}
+
+	// A draining lobby has no next round for the other players
+	if l.draining {
+		l.releasePlayers(remaining)
+		l.updateTickets(remaining, TicketFailed, "", ReasonLobbyStopped)
+		return
+	}
+
+	l.requeue(remaining)
+}
+
+// requeue places the players of a cancelled ready check into the lobby again. The players keep
+// the time they joined the lobby, so they are ahead of the players who joined later,
+// and they get the whole match making time again. The caller must hold queueMu.
+func (l *Lobby) requeue(players []player.Player) {
+	deadline := time.Now().Add(l.Config.WaitingTime)
+
+	// The members of a party are queued together
+	parties := [][]player.Player{}
+	partyIndex := make(map[string]int)
+	for _, p := range players {
+		p.Deadline = deadline
+
+		if i, ok := partyIndex[p.PartyID]; ok && p.PartyID != "" {
+			parties[i] = append(parties[i], p)
+			continue
+		}
+
+		partyIndex[p.PartyID] = len(parties)
+		parties = append(parties, []player.Player{p})
+	}
+
+	for _, members := range parties {
+		l.updateTickets(members, TicketQueued, "", ReasonRequeued)
+		l.place(members)
+	}
+}
+
+// removeReadyCheck forgets the ready check. The caller must hold queueMu.
+func (l *Lobby) removeReadyCheck(check *readyCheck) {
+	for i, c := range l.readyChecks {
+		if c == check {
+			l.readyChecks = append(l.readyChecks[:i], l.readyChecks[i+1:]...)
+			return
+		}
+	}
+}
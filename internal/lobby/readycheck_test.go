@@ -0,0 +1,192 @@
+package lobby
+
+import (
+	"testing"
+	"time"
+
+	"github.com/TanyEm/match-maker/v2/internal/match"
+	"github.com/TanyEm/match-maker/v2/internal/player"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func readyCheckConfig() Config {
+	cfg := testConfig(30 * time.Second)
+	cfg.MaxMatchSize = 3
+	cfg.ReadyCheck = 10 * time.Second
+	cfg.DeclinePenalty = time.Minute
+
+	return cfg
+}
+
+func TestLobby_ReadyCheckAccepted(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockKeeper := match.NewMockKeeper(mockCtrl)
+	l := NewLobby(readyCheckConfig(), mockKeeper, NewDefaultStrategy())
+
+	l.AddPlayer(player.Player{PlayerID: "1", JoinID: "join1", Level: 10, Country: "FIN"})
+	l.AddPlayer(player.Player{PlayerID: "2", JoinID: "join2", Level: 10, Country: "FIN"})
+	l.AddPlayer(player.Player{PlayerID: "3", JoinID: "join3", Level: 10, Country: "FIN"})
+
+	// The full match is held until its players accept it
+	ticket := ticketOf(l, "join1")
+	assert.Equal(t, TicketReadyCheck, ticket.State)
+	assert.Empty(t, ticket.MatchID)
+	assert.WithinDuration(t, time.Now().Add(10*time.Second), ticket.ReadyBy, time.Second)
+	assert.ErrorIs(t, l.AddPlayer(player.Player{PlayerID: "1", JoinID: "join4", Level: 10, Country: "FIN"}), ErrDuplicatePlayer)
+
+	assert.NoError(t, l.Ready("join1", true))
+	assert.NoError(t, l.Ready("join2", true))
+	assert.True(t, ticketOf(l, "join1").Accepted)
+	assert.Equal(t, TicketReadyCheck, ticketOf(l, "join1").State)
+
+	// The match starts once the last player accepts it
+	mockKeeper.EXPECT().
+		AddLeaderBoard(gomock.Any()).
+		Times(1)
+	assert.NoError(t, l.Ready("join3", true))
+
+	for _, joinID := range []string{"join1", "join2", "join3"} {
+		ticket := ticketOf(l, joinID)
+		assert.Equal(t, TicketMatched, ticket.State)
+		assert.NotEmpty(t, ticket.MatchID)
+		assert.False(t, ticket.Accepted)
+	}
+
+	assert.ErrorIs(t, l.Ready("join1", true), ErrNoReadyCheck)
+	assert.ErrorIs(t, l.Ready("unknown", true), ErrTicketNotFound)
+}
+
+func TestLobby_ReadyCheckDeclined(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	l := NewLobby(readyCheckConfig(), match.NewMockKeeper(mockCtrl), NewDefaultStrategy())
+
+	joinedAt := time.Now().Add(-20 * time.Second)
+	l.AddPlayer(player.Player{PlayerID: "1", JoinID: "join1", Level: 10, Country: "FIN", JoinedAt: joinedAt})
+	l.AddParty([]player.Player{
+		{PlayerID: "2", JoinID: "join2", Level: 10, Country: "FIN", PartyID: "party"},
+		{PlayerID: "3", JoinID: "join3", Level: 10, Country: "FIN", PartyID: "party"},
+	})
+	assert.NoError(t, l.Ready("join2", true))
+
+	// The decliner is dropped and penalised, the other players are queued again
+	assert.NoError(t, l.Ready("join1", false))
+
+	ticket := ticketOf(l, "join1")
+	assert.Equal(t, TicketCancelled, ticket.State)
+	assert.Equal(t, ReasonDeclined, ticket.Reason)
+	assert.ErrorIs(t, l.AddPlayer(player.Player{PlayerID: "1", JoinID: "join4", Level: 10, Country: "FIN"}), ErrPenalised)
+
+	for _, joinID := range []string{"join2", "join3"} {
+		ticket := ticketOf(l, joinID)
+		assert.Equal(t, TicketQueued, ticket.State)
+		assert.Equal(t, ReasonRequeued, ticket.Reason)
+		assert.False(t, ticket.Accepted)
+		assert.True(t, ticket.ReadyBy.IsZero())
+	}
+
+	// The party is queued together
+	assert.Equal(t, 2, l.GetCandidateMatchSize("join2"))
+	assert.Len(t, l.readyChecks, 0)
+}
+
+func TestLobby_ReadyCheckRequeuedFirst(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	cfg := readyCheckConfig()
+	cfg.MaxMatchSize = 2
+	l := NewLobby(cfg, match.NewMockKeeper(mockCtrl), NewDefaultStrategy())
+
+	joinedAt := time.Now().Add(-20 * time.Second)
+	l.AddPlayer(player.Player{PlayerID: "1", JoinID: "join1", Level: 10, Country: "FIN", JoinedAt: joinedAt})
+	l.AddPlayer(player.Player{PlayerID: "2", JoinID: "join2", Level: 10, Country: "FIN"})
+	assert.NoError(t, l.Ready("join2", false))
+
+	// The requeued player keeps the time of the join and gets the whole match making time again
+	location, _ := l.matchLocations.Load("FIN")
+	loaded, _ := location.(*match.MatchLocation).Load(10)
+	m := loaded
+	assert.Equal(t, joinedAt, m.WaitingSince())
+	assert.WithinDuration(t, time.Now().Add(30*time.Second), m.Deadline(), time.Second)
+	assert.Equal(t, joinedAt, ticketOf(l, "join1").JoinedAt)
+}
+
+func TestLobby_ReadyCheckExpired(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	l := NewLobby(readyCheckConfig(), match.NewMockKeeper(mockCtrl), NewDefaultStrategy())
+
+	l.AddPlayer(player.Player{PlayerID: "1", JoinID: "join1", Level: 10, Country: "FIN"})
+	l.AddPlayer(player.Player{PlayerID: "2", JoinID: "join2", Level: 10, Country: "FIN"})
+	l.AddPlayer(player.Player{PlayerID: "3", JoinID: "join3", Level: 10, Country: "FIN"})
+	assert.NoError(t, l.Ready("join1", true))
+
+	// The ready check is kept until its time is up
+	l.ExpireReadyChecks(time.Now())
+	assert.Equal(t, TicketReadyCheck, ticketOf(l, "join2").State)
+
+	// The no-shows are dropped
+	l.ExpireReadyChecks(time.Now().Add(11 * time.Second))
+	assert.Equal(t, TicketQueued, ticketOf(l, "join1").State)
+	for _, joinID := range []string{"join2", "join3"} {
+		ticket := ticketOf(l, joinID)
+		assert.Equal(t, TicketCancelled, ticket.State)
+		assert.Equal(t, ReasonReadyTimeout, ticket.Reason)
+	}
+	assert.ErrorIs(t, l.AddPlayer(player.Player{PlayerID: "2", JoinID: "join4", Level: 10, Country: "FIN"}), ErrPenalised)
+
+	// The penalty is forgotten once it is over
+	l.ExpireReadyChecks(time.Now().Add(2 * time.Minute))
+	assert.NoError(t, l.AddPlayer(player.Player{PlayerID: "2", JoinID: "join4", Level: 10, Country: "FIN"}))
+}
+
+func TestLobby_ReadyCheckLeave(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	cfg := readyCheckConfig()
+	cfg.DeclinePenalty = 0
+	l := NewLobby(cfg, match.NewMockKeeper(mockCtrl), NewDefaultStrategy())
+
+	l.AddPlayer(player.Player{PlayerID: "1", JoinID: "join1", Level: 10, Country: "FIN"})
+	l.AddPlayer(player.Player{PlayerID: "2", JoinID: "join2", Level: 10, Country: "FIN"})
+	l.AddPlayer(player.Player{PlayerID: "3", JoinID: "join3", Level: 10, Country: "FIN"})
+
+	// Leaving the lobby declines the match
+	assert.NoError(t, l.RemovePlayer("join3"))
+	ticket := ticketOf(l, "join3")
+	assert.Equal(t, TicketCancelled, ticket.State)
+	assert.Equal(t, ReasonLeft, ticket.Reason)
+	assert.Equal(t, TicketQueued, ticketOf(l, "join1").State)
+
+	// There is no penalty
+	assert.NoError(t, l.AddPlayer(player.Player{PlayerID: "3", JoinID: "join4", Level: 10, Country: "FIN"}))
+}
+
+func TestLobby_ReadyCheckStopped(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	l := NewLobby(readyCheckConfig(), match.NewMockKeeper(mockCtrl), NewDefaultStrategy())
+
+	l.AddPlayer(player.Player{PlayerID: "1", JoinID: "join1", Level: 10, Country: "FIN"})
+	l.AddPlayer(player.Player{PlayerID: "2", JoinID: "join2", Level: 10, Country: "FIN"})
+	l.AddPlayer(player.Player{PlayerID: "3", JoinID: "join3", Level: 10, Country: "FIN"})
+
+	go l.Run()
+	l.Stop()
+
+	assert.Eventually(t, func() bool {
+		return ticketOf(l, "join1").State == TicketFailed
+	}, time.Second, 10*time.Millisecond)
+
+	l.queueMu.Lock()
+	defer l.queueMu.Unlock()
+	assert.Len(t, l.readyChecks, 0)
+}
@@ -0,0 +1,54 @@
+package lobby
+
+import (
+	"log"
+
+	"github.com/TanyEm/match-maker/v2/internal/match"
+	"github.com/TanyEm/match-maker/v2/internal/region"
+)
+
+// Reconfigure applies the new config and strategy to the running lobby without dropping
+// the queued tickets. The waiting matches are moved to the buckets of the new strategy,
+// and the matches that are full with the new match size are started. The waiting players
+// keep their deadlines, the new waiting time applies to the players who join later.
+func (l *Lobby) Reconfigure(cfg Config, strategy MatchmakingStrategy) error {
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+
+	if cfg.Regions == nil {
+		cfg.Regions = region.NewMap(nil)
+	}
+
+	l.queueMu.Lock()
+	defer l.queueMu.Unlock()
+
+	// The tickets and the metrics are kept by the queue name
+	cfg.Queue = l.Config.Queue
+
+	l.mu.Lock()
+	l.Config = cfg
+	l.Strategy = strategy
+	l.regions = cfg.Regions
+	l.mu.Unlock()
+
+	l.matchLocations.Range(func(_, loaded interface{}) bool {
+		matchLocation := loaded.(*match.MatchLocation)
+
+		matchLocation.Range(func(m *match.Match) bool {
+			if m.GetPlayersCount() >= l.Config.MaxMatchSize && l.StartMatch(m, matchLocation) {
+				matchLocation.Delete(m)
+				return true
+			}
+
+			l.rebucket(matchLocation, m)
+			return true
+		})
+
+		return true
+	})
+
+	log.Printf("Lobby %s is reconfigured", l.metricKey())
+
+	return nil
+}
@@ -0,0 +1,62 @@
+package lobby
+
+import (
+	"testing"
+	"time"
+
+	"github.com/TanyEm/match-maker/v2/internal/match"
+	"github.com/TanyEm/match-maker/v2/internal/player"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestLobby_Reconfigure(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockKeeper := match.NewMockKeeper(mockCtrl)
+	mockKeeper.EXPECT().
+		AddLeaderBoard(gomock.Any()).
+		Times(1)
+
+	cfg := testConfig(10 * time.Second)
+	cfg.Queue = "ranked"
+	l := NewLobby(cfg, mockKeeper, &BucketStrategy{Attribute: AttributeLevel, Width: 10, Order: OrderAscending})
+
+	l.AddPlayer(player.Player{PlayerID: "1", JoinID: "join1", Level: 15, Country: "FIN"})
+	l.AddPlayer(player.Player{PlayerID: "2", JoinID: "join2", Level: 16, Country: "FIN"})
+	l.AddPlayer(player.Player{PlayerID: "3", JoinID: "join3", Level: 42, Country: "FIN"})
+
+	// The match of the bucket 1 is full with the new match size, the other match is moved to the level bucket
+	cfg = testConfig(20 * time.Second)
+	cfg.MaxMatchSize = 2
+	assert.NoError(t, l.Reconfigure(cfg, NewDefaultStrategy()))
+
+	assert.Equal(t, TicketMatched, ticketOf(l, "join1").State)
+	assert.Equal(t, TicketMatched, ticketOf(l, "join2").State)
+	assert.Equal(t, TicketQueued, ticketOf(l, "join3").State)
+	assert.Equal(t, "ranked", l.Config.Queue)
+	assert.Equal(t, 20*time.Second, l.GetMatchMakingTime())
+
+	location, _ := l.matchLocations.Load("FIN")
+	_, ok := location.(*match.MatchLocation).Load(42)
+	assert.True(t, ok)
+	_, ok = location.(*match.MatchLocation).Load(4)
+	assert.False(t, ok)
+}
+
+func TestLobby_ReconfigureInvalid(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	l := NewLobby(testConfig(10*time.Second), match.NewMockKeeper(mockCtrl), NewDefaultStrategy())
+	l.AddPlayer(player.Player{PlayerID: "1", JoinID: "join1", Level: 10, Country: "FIN"})
+
+	cfg := testConfig(10 * time.Second)
+	cfg.MaxMatchSize = 1
+	assert.EqualError(t, l.Reconfigure(cfg, NewDefaultStrategy()), "max match size 1 is less than min match size 2")
+
+	// The lobby keeps the old config and the queued ticket
+	assert.Equal(t, 10, l.Config.MaxMatchSize)
+	assert.Equal(t, TicketQueued, ticketOf(l, "join1").State)
+}
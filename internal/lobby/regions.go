@@ -0,0 +1,116 @@
+package lobby
+
+import (
+	"log"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/TanyEm/match-maker/v2/internal/match"
+	"github.com/TanyEm/match-maker/v2/internal/region"
+)
+
+// locatedMatch is a waiting match together with the location it is stored in
+type locatedMatch struct {
+	match    *match.Match
+	location *match.MatchLocation
+}
+
+// mergeThinMatches moves the players of the due matches that have less than Config.MinMatchSize players
+// into the nearest match of another country within Config.RegionFallback.
+// The caller must hold queueMu.
+func (l *Lobby) mergeThinMatches(now time.Time, due func(m *match.Match) bool) {
+	if l.Config.RegionFallback == region.SameCountry {
+		return
+	}
+
+	waiting := []locatedMatch{}
+	l.matchLocations.Range(func(_, loaded interface{}) bool {
+		matchLocation := loaded.(*match.MatchLocation)
+		matchLocation.Range(func(m *match.Match) bool {
+			waiting = append(waiting, locatedMatch{match: m, location: matchLocation})
+			return true
+		})
+		return true
+	})
+
+	// Move the thinnest matches first, and of the matches of the same size the earliest due first,
+	// so the matches are merged the same way whatever order the locations are kept in
+	sort.Slice(waiting, func(i, j int) bool {
+		if waiting[i].match.GetPlayersCount() != waiting[j].match.GetPlayersCount() {
+			return waiting[i].match.GetPlayersCount() < waiting[j].match.GetPlayersCount()
+		}
+		return waiting[i].match.Deadline().Before(waiting[j].match.Deadline())
+	})
+
+	removed := map[*match.Match]bool{}
+	for _, thin := range waiting {
+		if removed[thin.match] || thin.match.GetPlayersCount() >= l.Config.MinMatchSize || !due(thin.match) {
+			continue
+		}
+
+		var best *locatedMatch
+		bestProximity := region.Global
+		for i, other := range waiting {
+			if removed[other.match] || other.match.Country == thin.match.Country {
+				continue
+			}
+
+			if thin.match.GetPlayersCount()+other.match.GetPlayersCount() > l.Config.MaxMatchSize ||
+				!l.fitTeams(slices.Concat(thin.match.GetPlayers(), other.match.GetPlayers())) {
+				continue
+			}
+
+			proximity := l.regions.Proximity(thin.match.Country, other.match.Country)
+			if proximity > l.Config.RegionFallback || !l.levelsCompatible(thin.match, other.match, now) {
+				continue
+			}
+
+			// Prefer the nearest country and then the biggest match
+			if best == nil || proximity < bestProximity ||
+				(proximity == bestProximity && other.match.GetPlayersCount() > best.match.GetPlayersCount()) {
+				best, bestProximity = &waiting[i], proximity
+			}
+		}
+
+		if best == nil {
+			continue
+		}
+
+		log.Printf("Match %s country %s has only %d player(s). Merging into match %s country %s (%s)",
+			thin.match.MatchID,
+			thin.match.Country,
+			thin.match.GetPlayersCount(),
+			best.match.MatchID,
+			best.match.Country,
+			bestProximity,
+		)
+
+		best.match.Merge(thin.match)
+		thin.location.Delete(thin.match)
+		removed[thin.match] = true
+
+		if best.match.GetPlayersCount() >= l.Config.MaxMatchSize && l.StartMatch(best.match, best.location) {
+			best.location.Delete(best.match)
+			removed[best.match] = true
+		}
+	}
+}
+
+// levelsCompatible tells if the players of the matches can compete with each other
+// according to the strategy or the widened level band
+func (l *Lobby) levelsCompatible(a, b *match.Match, now time.Time) bool {
+	for _, p := range a.GetPlayers() {
+		if slices.Contains(l.Strategy.Candidates(p), b.Level) {
+			return true
+		}
+	}
+
+	for _, p := range b.GetPlayers() {
+		if slices.Contains(l.Strategy.Candidates(p), a.Level) {
+			return true
+		}
+	}
+
+	return l.withinWidenedBand(a, b, now)
+}
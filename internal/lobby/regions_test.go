@@ -0,0 +1,109 @@
+package lobby
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/TanyEm/match-maker/v2/internal/match"
+	"github.com/TanyEm/match-maker/v2/internal/player"
+	"github.com/TanyEm/match-maker/v2/internal/region"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestLobby_RegionFallback(t *testing.T) {
+	// The players are due one second after another in the order they joined
+	joinedAt := time.Now().Add(-time.Hour)
+	players := []player.Player{
+		{PlayerID: "1", JoinID: "fin1", Level: 5, Country: "FIN", JoinedAt: joinedAt},
+		{PlayerID: "2", JoinID: "swe1", Level: 6, Country: "SWE", JoinedAt: joinedAt.Add(time.Second)},
+		{PlayerID: "3", JoinID: "deu1", Level: 5, Country: "DEU", JoinedAt: joinedAt.Add(2 * time.Second)},
+		{PlayerID: "4", JoinID: "usa1", Level: 4, Country: "USA", JoinedAt: joinedAt.Add(3 * time.Second)},
+	}
+	allDue := joinedAt.Add(time.Minute + 3*time.Second)
+
+	tests := []struct {
+		name            string
+		fallback        region.Proximity
+		now             time.Time
+		expectedMatches [][]string
+		expectedNoMatch []string
+		expectedQueued  []string
+	}{
+		{
+			name:            "no fallback",
+			fallback:        region.SameCountry,
+			now:             allDue,
+			expectedNoMatch: []string{"fin1", "swe1", "deu1", "usa1"},
+		},
+		{
+			name:            "fallback to the sub-region",
+			fallback:        region.SameSubRegion,
+			now:             allDue,
+			expectedMatches: [][]string{{"fin1", "swe1"}},
+			expectedNoMatch: []string{"deu1", "usa1"},
+		},
+		{
+			// FIN joins SWE in the same sub-region, and then DEU and USA join them as the nearest and the biggest match
+			name:            "fallback to the whole world",
+			fallback:        region.Global,
+			now:             allDue,
+			expectedMatches: [][]string{{"deu1", "fin1", "swe1", "usa1"}},
+		},
+		{
+			// The player in USA is not due yet, so the player keeps waiting
+			name:            "fallback before the last deadline",
+			fallback:        region.Global,
+			now:             allDue.Add(-time.Second),
+			expectedMatches: [][]string{{"deu1", "fin1", "swe1"}},
+			expectedQueued:  []string{"usa1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matches := [][]string{}
+			mockKeeper := match.NewMockKeeper(gomock.NewController(t))
+			mockKeeper.EXPECT().
+				AddLeaderBoard(gomock.Any()).
+				Times(len(tt.expectedMatches)).
+				Do(func(lb *match.LeaderBoard) {
+					joinIDs := []string{}
+					for _, p := range lb.Players {
+						joinIDs = append(joinIDs, p.JoinID)
+					}
+					slices.Sort(joinIDs)
+					matches = append(matches, joinIDs)
+				})
+
+			cfg := testConfig(time.Minute)
+			cfg.RegionFallback = tt.fallback
+			l := NewLobby(cfg, mockKeeper, NewDefaultStrategy())
+
+			for _, p := range players {
+				l.AddPlayer(p)
+			}
+
+			l.StartDueMatches(tt.now)
+
+			if len(tt.expectedMatches) > 0 {
+				assert.Equal(t, tt.expectedMatches, matches)
+			}
+
+			for _, joinIDs := range tt.expectedMatches {
+				for _, joinID := range joinIDs {
+					assert.Equal(t, TicketMatched, ticketOf(l, joinID).State, "Expected a match for %s", joinID)
+				}
+			}
+
+			for _, joinID := range tt.expectedNoMatch {
+				assert.Equal(t, TicketExpired, ticketOf(l, joinID).State, "Expected no match for %s", joinID)
+			}
+
+			for _, joinID := range tt.expectedQueued {
+				assert.Equal(t, TicketQueued, ticketOf(l, joinID).State, "Expected %s to keep waiting", joinID)
+			}
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package lobby
+
+import (
+	"log"
+	"time"
+
+	"github.com/TanyEm/match-maker/v2/internal/match"
+	"github.com/TanyEm/match-maker/v2/internal/player"
+)
+
+// carryOver keeps the players of the too thin match waiting for another round of Config.WaitingTime.
+// The players keep the time they joined the lobby, and the players who have waited
+// for Config.MaxWait are notified there is no match. The caller must hold queueMu.
+func (l *Lobby) carryOver(now time.Time, matchLocation *match.MatchLocation, m *match.Match) {
+	carried, expired := []player.Player{}, []player.Player{}
+	for _, p := range m.GetPlayers() {
+		if now.Before(p.JoinedAt.Add(l.Config.MaxWait)) {
+			carried = append(carried, p)
+		} else {
+			expired = append(expired, p)
+		}
+	}
+
+	for _, p := range carried {
+		deadline := now.Add(l.Config.WaitingTime)
+		if maxDeadline := p.JoinedAt.Add(l.Config.MaxWait); maxDeadline.Before(deadline) {
+			deadline = maxDeadline
+		}
+		m.SetDeadline(p.JoinID, deadline)
+	}
+
+	if len(carried) > 0 {
+		log.Printf("Match %s country %s level %d has only %d player(s). Carrying %d player(s) over into the next round...\n",
+			m.MatchID, m.Country, m.Level, m.GetPlayersCount(), len(carried))
+		l.updateTickets(carried, TicketQueued, "", ReasonRequeued)
+	}
+
+	if len(expired) == 0 {
+		return
+	}
+
+	for _, p := range expired {
+		m.RemovePlayer(p.JoinID)
+	}
+	l.releasePlayers(expired)
+	l.updateTickets(expired, TicketExpired, "", ReasonNotEnoughPlayers)
+	l.rebucket(matchLocation, m)
+}
@@ -0,0 +1,102 @@
+package lobby
+
+import (
+	"testing"
+	"time"
+
+	"github.com/TanyEm/match-maker/v2/internal/match"
+	"github.com/TanyEm/match-maker/v2/internal/player"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestLobby_CarryOver(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	cfg := testConfig(10 * time.Second)
+	cfg.MaxWait = 25 * time.Second
+	l := NewLobby(cfg, match.NewMockKeeper(mockCtrl), NewDefaultStrategy())
+
+	// The players polling for a match wait for all the rounds
+	assert.Equal(t, 25*time.Second, l.GetMatchMakingTime())
+
+	now := time.Now()
+	joinedAt := now.Add(-10 * time.Second)
+	l.AddPlayer(player.Player{PlayerID: "1", JoinID: "join1", Level: 10, Country: "FIN", JoinedAt: joinedAt})
+
+	// The player keeps waiting for the next round with the time of the join
+	l.StartDueMatches(now)
+	ticket := ticketOf(l, "join1")
+	assert.Equal(t, TicketQueued, ticket.State)
+	assert.Equal(t, ReasonRequeued, ticket.Reason)
+	assert.Equal(t, joinedAt, ticket.JoinedAt)
+
+	location, _ := l.matchLocations.Load("FIN")
+	loaded, _ := location.(*match.MatchLocation).Load(10)
+	m := loaded
+	assert.Equal(t, now.Add(10*time.Second), m.Deadline())
+
+	// The last round is cut at the max wait
+	l.StartDueMatches(now.Add(10 * time.Second))
+	assert.Equal(t, TicketQueued, ticketOf(l, "join1").State)
+	assert.Equal(t, joinedAt.Add(25*time.Second), m.Deadline())
+
+	// The player is notified there is no match once the max wait is up
+	l.StartDueMatches(now.Add(15 * time.Second))
+	ticket = ticketOf(l, "join1")
+	assert.Equal(t, TicketExpired, ticket.State)
+	assert.Equal(t, ReasonNotEnoughPlayers, ticket.Reason)
+	_, ok := location.(*match.MatchLocation).Load(10)
+	assert.False(t, ok)
+	assert.NoError(t, l.AddPlayer(player.Player{PlayerID: "1", JoinID: "join2", Level: 10, Country: "FIN"}))
+}
+
+func TestLobby_CarryOverMatched(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockKeeper := match.NewMockKeeper(mockCtrl)
+	mockKeeper.EXPECT().
+		AddLeaderBoard(gomock.Any()).
+		Times(1)
+
+	cfg := testConfig(10 * time.Second)
+	cfg.MaxWait = 30 * time.Second
+	l := NewLobby(cfg, mockKeeper, NewDefaultStrategy())
+
+	now := time.Now()
+	l.AddPlayer(player.Player{PlayerID: "1", JoinID: "join1", Level: 10, Country: "FIN", JoinedAt: now.Add(-20 * time.Second)})
+	l.AddPlayer(player.Player{PlayerID: "2", JoinID: "join2", Level: 50, Country: "FIN", JoinedAt: now.Add(-30 * time.Second)})
+	l.StartDueMatches(now)
+
+	// The player who has waited for the max wait is notified, the other player is carried over
+	assert.Equal(t, TicketExpired, ticketOf(l, "join2").State)
+	assert.Equal(t, TicketQueued, ticketOf(l, "join1").State)
+
+	// The carried over player is matched with a player who joins later
+	l.AddPlayer(player.Player{PlayerID: "3", JoinID: "join3", Level: 10, Country: "FIN", JoinedAt: now})
+	l.StartDueMatches(now.Add(10 * time.Second))
+	assert.Equal(t, TicketMatched, ticketOf(l, "join1").State)
+	assert.Equal(t, TicketMatched, ticketOf(l, "join3").State)
+}
+
+func TestLobby_CarryOverOff(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	cfg := testConfig(10 * time.Second)
+	cfg.MaxWait = time.Minute
+	l := NewLobby(cfg, match.NewMockKeeper(mockCtrl), NewDefaultStrategy())
+
+	// StartMatches cleans the lobby regardless of the max wait
+	l.AddPlayer(player.Player{PlayerID: "1", JoinID: "join1", Level: 10, Country: "FIN"})
+	l.StartMatches()
+	assert.Equal(t, TicketExpired, ticketOf(l, "join1").State)
+
+	// The players are notified right away without the max wait
+	l.Config.MaxWait = 0
+	l.AddPlayer(player.Player{PlayerID: "2", JoinID: "join2", Level: 10, Country: "FIN", JoinedAt: time.Now().Add(-10 * time.Second)})
+	l.StartDueMatches(time.Now())
+	assert.Equal(t, TicketExpired, ticketOf(l, "join2").State)
+}
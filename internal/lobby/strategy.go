@@ -0,0 +1,112 @@
+package lobby
+
+import (
+	"math"
+
+	"github.com/TanyEm/match-maker/v2/internal/player"
+	"github.com/TanyEm/match-maker/v2/internal/rating"
+)
+
+// MatchmakingStrategy decides which pending matches of a location a player can be placed into.
+// Matches inside a location are stored by a level bucket, so a strategy only has to tell
+// the bucket of a new match and the buckets the player is allowed to join.
+type MatchmakingStrategy interface {
+	// Bucket returns the level bucket a new match created for the player is stored at
+	Bucket(p player.Player) int
+	// Candidates returns the level buckets the player can join in order of preference
+	Candidates(p player.Player) []int
+}
+
+// Attribute is the property of the players a BucketStrategy matches them by
+type Attribute string
+
+const (
+	AttributeLevel  Attribute = "level"
+	AttributeRating Attribute = "rating"
+)
+
+// CandidateOrder is the order a BucketStrategy offers the buckets of a player in
+type CandidateOrder string
+
+const (
+	// OrderAscending offers the lowest bucket first
+	OrderAscending CandidateOrder = "ascending"
+	// OrderClosest offers the player's own bucket first and then the nearest buckets, the lower one first
+	OrderClosest CandidateOrder = "closest"
+)
+
+// BucketStrategy combines players by an attribute split into buckets of Width.
+// Players are combined with the players of the buckets at most Band away from theirs.
+// The buckets of the players at the Floor of the attribute are raised by Band, so they
+// can compete with as many buckets as other players, e.g. a player with level 1
+// competes with levels 1, 2 and 3 when the band is 1.
+type BucketStrategy struct {
+	Attribute Attribute
+	Width     float64
+	// Floor is the lowest value of the attribute, nil if the attribute has no floor
+	Floor *float64
+	Band  int
+	Order CandidateOrder
+}
+
+// NewDefaultStrategy combines players with +1/-1 level from theirs.
+// A player with level 1 can compete with players from levels 1, 2, and 3.
+func NewDefaultStrategy() *BucketStrategy {
+	floor := 1.0
+
+	return &BucketStrategy{Attribute: AttributeLevel, Width: 1, Floor: &floor, Band: 1, Order: OrderAscending}
+}
+
+// NewRatingStrategy combines players by their rating. Ratings are split into bands of bandWidth points
+// and players are combined with the players of their band and the bands above and below.
+// The level is used as a coarse rating for the players who have no rating yet.
+func NewRatingStrategy(bandWidth float64) *BucketStrategy {
+	return &BucketStrategy{Attribute: AttributeRating, Width: bandWidth, Band: 1, Order: OrderAscending}
+}
+
+func (s *BucketStrategy) Bucket(p player.Player) int {
+	bucket := s.bucketOf(s.value(p))
+	if s.Floor != nil {
+		bucket = max(bucket, s.bucketOf(*s.Floor)+s.Band)
+	}
+
+	return bucket
+}
+
+func (s *BucketStrategy) Candidates(p player.Player) []int {
+	bucket := s.Bucket(p)
+
+	if s.Order == OrderClosest {
+		candidates := []int{bucket}
+		for distance := 1; distance <= s.Band; distance++ {
+			candidates = append(candidates, bucket-distance, bucket+distance)
+		}
+
+		return candidates
+	}
+
+	candidates := make([]int, 0, 2*s.Band+1)
+	for b := bucket - s.Band; b <= bucket+s.Band; b++ {
+		candidates = append(candidates, b)
+	}
+
+	return candidates
+}
+
+// value returns the attribute of the player, the level is used as a coarse rating
+// for the players who have no rating yet
+func (s *BucketStrategy) value(p player.Player) float64 {
+	if s.Attribute == AttributeRating {
+		if p.Rating == 0 {
+			return rating.FromLevel(p.Level)
+		}
+
+		return p.Rating
+	}
+
+	return float64(p.Level)
+}
+
+func (s *BucketStrategy) bucketOf(value float64) int {
+	return int(math.Floor(value / s.Width))
+}
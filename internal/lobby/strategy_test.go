@@ -0,0 +1,65 @@
+package lobby
+
+import (
+	"testing"
+	"time"
+
+	"github.com/TanyEm/match-maker/v2/internal/match"
+	"github.com/TanyEm/match-maker/v2/internal/player"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestBucketStrategy(t *testing.T) {
+	floor := 1.0
+
+	tests := []struct {
+		name               string
+		strategy           *BucketStrategy
+		player             player.Player
+		expectedBucket     int
+		expectedCandidates []int
+	}{
+		{"default strategy: level 1", NewDefaultStrategy(), player.Player{Level: 1}, 2, []int{1, 2, 3}},
+		{"default strategy: level 2", NewDefaultStrategy(), player.Player{Level: 2}, 2, []int{1, 2, 3}},
+		{"default strategy: level 50", NewDefaultStrategy(), player.Player{Level: 50}, 50, []int{49, 50, 51}},
+		{"wide level bands", &BucketStrategy{AttributeLevel, 10, &floor, 2, OrderAscending}, player.Player{Level: 5}, 2, []int{0, 1, 2, 3, 4}},
+		{"closest level bands first", &BucketStrategy{AttributeLevel, 10, nil, 2, OrderClosest}, player.Player{Level: 55}, 5, []int{5, 4, 6, 3, 7}},
+		{"exact level", &BucketStrategy{AttributeLevel, 1, &floor, 0, OrderAscending}, player.Player{Level: 1}, 1, []int{1}},
+		{"rating strategy: rated player", NewRatingStrategy(100), player.Player{Level: 1, Rating: 1720}, 17, []int{16, 17, 18}},
+		{"rating strategy: new player falls back to level", NewRatingStrategy(100), player.Player{Level: 50}, 15, []int{14, 15, 16}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expectedBucket, tt.strategy.Bucket(tt.player))
+			assert.Equal(t, tt.expectedCandidates, tt.strategy.Candidates(tt.player))
+		})
+	}
+}
+
+// sameBucketStrategy places every player into one bucket regardless of the level
+type sameBucketStrategy struct{}
+
+func (s *sameBucketStrategy) Bucket(_ player.Player) int {
+	return 0
+}
+
+func (s *sameBucketStrategy) Candidates(_ player.Player) []int {
+	return []int{0}
+}
+
+func TestLobby_CustomStrategy(t *testing.T) {
+	mockMatchKeeper := match.NewMockKeeper(gomock.NewController(t))
+	l := NewLobby(testConfig(1*time.Minute), mockMatchKeeper, &sameBucketStrategy{})
+
+	l.AddPlayer(player.Player{PlayerID: "1", JoinID: "join1", Level: 1, Country: "FIN"})
+	l.AddPlayer(player.Player{PlayerID: "2", JoinID: "join2", Level: 99, Country: "FIN"})
+
+	location, ok := l.matchLocations.Load("FIN")
+	assert.True(t, ok, "Expected match location for country FIN")
+
+	loadedMatch, ok := location.(*match.MatchLocation).Load(0)
+	assert.True(t, ok, "Expected match at bucket 0")
+	assert.Equal(t, 2, loadedMatch.GetPlayersCount(), "Expected both players in the same match")
+}
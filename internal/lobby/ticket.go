@@ -0,0 +1,257 @@
+package lobby
+
+import (
+	"errors"
+	"expvar"
+	"time"
+
+	"github.com/TanyEm/match-maker/v2/internal/match"
+	"github.com/TanyEm/match-maker/v2/internal/player"
+)
+
+// ticketPruneInterval is how often the lobby looks for the tickets to prune
+const ticketPruneInterval = time.Second
+
+var ErrTicketPruned = errors.New("ticket has expired and is pruned")
+
+// The names of the expvar metrics of the lobbies
+const (
+	TicketsMetric       = "lobby_tickets"
+	PrunedTicketsMetric = "lobby_tickets_pruned"
+)
+
+var (
+	// ticketsMetric is the number of the tickets kept by the lobbies by their queues
+	ticketsMetric = expvar.NewMap(TicketsMetric)
+	// prunedTicketsMetric is the number of the tickets pruned by the lobbies by their queues
+	prunedTicketsMetric = expvar.NewMap(PrunedTicketsMetric)
+)
+
+// TicketState is the state of a lobby ticket. A ticket is queued until it is matched, expired,
+// cancelled or failed, and it never leaves these final states. A ticket of a full match waits
+// in the ready check until the match is accepted by all its players, see Config.ReadyCheck.
+type TicketState string
+
+const (
+	// TicketQueued is a ticket of a player waiting for a match
+	TicketQueued TicketState = "queued"
+	// TicketReadyCheck is a ticket of a player who has to accept the full match before it starts
+	TicketReadyCheck TicketState = "ready_check"
+	// TicketMatched is a ticket of a player placed into a started match
+	TicketMatched TicketState = "matched"
+	// TicketExpired is a ticket of a player who has not been matched in the match making time
+	TicketExpired TicketState = "expired"
+	// TicketCancelled is a ticket of a player who has left the lobby or joined it again
+	TicketCancelled TicketState = "cancelled"
+	// TicketFailed is a ticket the lobby could not resolve
+	TicketFailed TicketState = "failed"
+)
+
+// Reason tells why a ticket got into its state
+type Reason string
+
+const (
+	// ReasonBackfill is a ticket matched into the open slot of a started match
+	ReasonBackfill Reason = "backfill"
+	// ReasonNotEnoughPlayers is a ticket expired in a match with less than Config.MinMatchSize players
+	ReasonNotEnoughPlayers Reason = "not_enough_players"
+	// ReasonLeft is a ticket cancelled by the player
+	ReasonLeft Reason = "left_lobby"
+	// ReasonReplaced is a ticket replaced by a new ticket of the same player, see DuplicateReplace
+	ReasonReplaced Reason = "replaced"
+	// ReasonLobbyStopped is a ticket still queued when the lobby was stopped
+	ReasonLobbyStopped Reason = "lobby_stopped"
+	// ReasonDeclined is a ticket cancelled by the player who has declined the ready check
+	ReasonDeclined Reason = "declined"
+	// ReasonReadyTimeout is a ticket cancelled because the player has not accepted the ready check in time
+	ReasonReadyTimeout Reason = "ready_check_timeout"
+	// ReasonRequeued is a ticket queued again for another round because there were not enough players,
+	// see Config.MaxWait, or another player has declined the ready check
+	ReasonRequeued Reason = "requeued"
+)
+
+// Ticket is a player's place in the lobby
+type Ticket struct {
+	JoinID   string
+	PlayerID string
+	Queue    string
+	State    TicketState
+	// MatchID is the match of a matched ticket
+	MatchID string
+	Reason  Reason
+	// JoinedAt is the time the player joined the lobby
+	JoinedAt time.Time
+	// UpdatedAt is the time the ticket got into its state
+	UpdatedAt time.Time
+	// EstimatedWait is the estimated time to match when the player joined the lobby, see Lobby.EstimateWait
+	EstimatedWait time.Duration
+	// ReadyBy is the time the player has to accept the match of a ticket in the ready check by
+	ReadyBy time.Time
+	// Accepted tells the player has accepted the match of a ticket in the ready check
+	Accepted bool
+}
+
+// Resolved tells the ticket is in one of the final states
+func (t Ticket) Resolved() bool {
+	return t.State != TicketQueued && t.State != TicketReadyCheck
+}
+
+// TimeInQueue returns the time the player has waited for a match or waited before the ticket was resolved
+func (t Ticket) TimeInQueue(now time.Time) time.Duration {
+	if t.Resolved() {
+		return t.UpdatedAt.Sub(t.JoinedAt)
+	}
+
+	return now.Sub(t.JoinedAt)
+}
+
+// RemainingWait returns the estimated time the queued player still waits for a match,
+// 0 if the ticket is resolved or the player has waited longer than estimated
+func (t Ticket) RemainingWait(now time.Time) time.Duration {
+	if t.State != TicketQueued {
+		return 0
+	}
+
+	return max(t.EstimatedWait-t.TimeInQueue(now), 0)
+}
+
+// GetTicket returns the ticket of the join ID. It returns ErrTicketPruned if the ticket
+// has been pruned in the last Config.TicketTTL, and ErrTicketNotFound for any other unknown join ID.
+func (l *Lobby) GetTicket(joinID string) (Ticket, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	ticket, ok := l.tickets[joinID]
+	if ok {
+		return *ticket, nil
+	}
+
+	if _, ok := l.pruned[joinID]; ok {
+		return Ticket{}, ErrTicketPruned
+	}
+
+	return Ticket{}, ErrTicketNotFound
+}
+
+// PruneTickets removes the tickets that have been matched, expired, cancelled or failed
+// for Config.TicketTTL. Queued tickets and tickets in the ready check are never pruned.
+// The join IDs of the pruned tickets are kept for another Config.TicketTTL, so a pruned ticket
+// is told apart from an unknown one. It returns the number of the pruned tickets.
+func (l *Lobby) PruneTickets(now time.Time) int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.Config.TicketTTL <= 0 {
+		return 0
+	}
+
+	for joinID, prunedAt := range l.pruned {
+		if now.Sub(prunedAt) >= l.Config.TicketTTL {
+			delete(l.pruned, joinID)
+		}
+	}
+
+	pruned := 0
+	for joinID, ticket := range l.tickets {
+		if ticket.Resolved() && now.Sub(ticket.UpdatedAt) >= l.Config.TicketTTL {
+			delete(l.tickets, joinID)
+			l.pruned[joinID] = now
+			pruned++
+		}
+	}
+
+	if pruned > 0 {
+		ticketsMetric.Add(l.metricKey(), -int64(pruned))
+		prunedTicketsMetric.Add(l.metricKey(), int64(pruned))
+	}
+
+	return pruned
+}
+
+// metricKey returns the name of the queue the metrics of the lobby are kept by
+func (l *Lobby) metricKey() string {
+	if l.Config.Queue == "" {
+		return "default"
+	}
+
+	return l.Config.Queue
+}
+
+// GetCandidateMatchSize returns the number of players of the waiting match the queued ticket is in,
+// 0 if the ticket is not queued
+func (l *Lobby) GetCandidateMatchSize(joinID string) int {
+	l.queueMu.Lock()
+	defer l.queueMu.Unlock()
+
+	for _, waiting := range l.waitingUnits() {
+		for _, p := range waiting.members {
+			if p.JoinID == joinID {
+				return waiting.match.GetPlayersCount()
+			}
+		}
+	}
+
+	return 0
+}
+
+// updateTickets moves the tickets of the players to the state, the missing tickets are created.
+// The time to match of the matched players is kept for the wait estimates.
+func (l *Lobby) updateTickets(players []player.Player, state TicketState, matchID string, reason Reason) {
+	now := time.Now()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for _, p := range players {
+		ticket, ok := l.tickets[p.JoinID]
+		if !ok {
+			ticket = &Ticket{
+				JoinID:   p.JoinID,
+				PlayerID: p.PlayerID,
+				Queue:    p.Queue,
+				JoinedAt: p.JoinedAt,
+				// The estimate is taken before the time to match of the player is kept
+				EstimatedWait: l.estimateWait(p),
+			}
+			l.tickets[p.JoinID] = ticket
+			ticketsMetric.Add(l.metricKey(), 1)
+		}
+
+		ticket.State = state
+		ticket.MatchID = matchID
+		ticket.Reason = reason
+		ticket.UpdatedAt = now
+		ticket.ReadyBy = time.Time{}
+		ticket.Accepted = false
+
+		if state == TicketMatched {
+			l.recordWait(p, ticket.TimeInQueue(now))
+		}
+	}
+}
+
+// failQueued fails the tickets of all waiting players and the players in the ready checks
+// for the reason and empties the lobby
+func (l *Lobby) failQueued(reason Reason) {
+	l.queueMu.Lock()
+	defer l.queueMu.Unlock()
+
+	for _, check := range l.readyChecks {
+		l.releasePlayers(check.match.GetPlayers())
+		l.updateTickets(check.match.GetPlayers(), TicketFailed, "", reason)
+	}
+	l.readyChecks = nil
+
+	l.matchLocations.Range(func(_, loaded interface{}) bool {
+		matchLocation := loaded.(*match.MatchLocation)
+
+		matchLocation.Range(func(m *match.Match) bool {
+			l.releasePlayers(m.GetPlayers())
+			l.updateTickets(m.GetPlayers(), TicketFailed, "", reason)
+			matchLocation.Delete(m)
+			return true
+		})
+
+		return true
+	})
+}
@@ -0,0 +1,92 @@
+package lobby
+
+import (
+	"encoding/binary"
+	"expvar"
+	"testing"
+	"time"
+
+	"github.com/TanyEm/match-maker/v2/internal/match"
+	"github.com/TanyEm/match-maker/v2/internal/player"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+// joinIDAt returns a time ordered join ID created at the time
+func joinIDAt(at time.Time) string {
+	id := uuid.Must(uuid.NewV7())
+	var ms [8]byte
+	binary.BigEndian.PutUint64(ms[:], uint64(at.UnixMilli()))
+	copy(id[0:6], ms[2:8])
+
+	return id.String()
+}
+
+// metricValue returns the value of the queue in the expvar map, 0 if it is not set
+func metricValue(name, queue string) int64 {
+	if v, ok := expvar.Get(name).(*expvar.Map).Get(queue).(*expvar.Int); ok {
+		return v.Value()
+	}
+
+	return 0
+}
+
+func TestLobby_PruneTickets(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	cfg := testConfig(time.Minute)
+	cfg.Queue = "prune"
+	cfg.TicketTTL = 10 * time.Minute
+	l := NewLobby(cfg, match.NewMockKeeper(mockCtrl), NewDefaultStrategy())
+
+	tickets, pruned := metricValue("lobby_tickets", "prune"), metricValue("lobby_tickets_pruned", "prune")
+
+	now := time.Now()
+	oldJoinID := joinIDAt(now.Add(-20 * time.Minute))
+	l.AddPlayer(player.Player{PlayerID: "1", JoinID: oldJoinID, Level: 10, Country: "FIN", JoinedAt: now.Add(-20 * time.Minute)})
+	l.AddPlayer(player.Player{PlayerID: "2", JoinID: "join2", Level: 50, Country: "FIN"})
+	l.AddPlayer(player.Player{PlayerID: "3", JoinID: "join3", Level: 90, Country: "FIN"})
+	assert.NoError(t, l.RemovePlayer(oldJoinID))
+	assert.NoError(t, l.RemovePlayer("join2"))
+	assert.Equal(t, tickets+3, metricValue("lobby_tickets", "prune"))
+
+	// The tickets are kept until their TTL is up
+	assert.Equal(t, 0, l.PruneTickets(now.Add(5*time.Minute)))
+
+	// The queued ticket is never pruned
+	assert.Equal(t, 2, l.PruneTickets(now.Add(11*time.Minute)))
+	assert.Equal(t, TicketQueued, ticketOf(l, "join3").State)
+	assert.Equal(t, tickets+1, metricValue("lobby_tickets", "prune"))
+	assert.Equal(t, pruned+2, metricValue("lobby_tickets_pruned", "prune"))
+
+	// The pruned tickets are told apart from the join IDs that were never issued, however old they are
+	_, err := l.GetTicket(oldJoinID)
+	assert.ErrorIs(t, err, ErrTicketPruned)
+	_, err = l.GetTicket("join2")
+	assert.ErrorIs(t, err, ErrTicketPruned)
+	_, err = l.GetTicket(joinIDAt(now.Add(-time.Hour)))
+	assert.ErrorIs(t, err, ErrTicketNotFound)
+	_, err = l.GetTicket(joinIDAt(now))
+	assert.ErrorIs(t, err, ErrTicketNotFound)
+
+	// The join IDs of the pruned tickets are forgotten after another TTL
+	assert.Equal(t, 0, l.PruneTickets(now.Add(21*time.Minute)))
+	_, err = l.GetTicket(oldJoinID)
+	assert.ErrorIs(t, err, ErrTicketNotFound)
+}
+
+func TestLobby_PruneTicketsDisabled(t *testing.T) {
+	l := NewLobby(testConfig(time.Minute), match.NewMockKeeper(gomock.NewController(t)), NewDefaultStrategy())
+
+	oldJoinID := joinIDAt(time.Now().Add(-time.Hour))
+	l.AddPlayer(player.Player{PlayerID: "1", JoinID: oldJoinID, Level: 10, Country: "FIN"})
+	assert.NoError(t, l.RemovePlayer(oldJoinID))
+
+	assert.Equal(t, 0, l.PruneTickets(time.Now().Add(24*time.Hour)))
+	assert.Equal(t, TicketCancelled, ticketOf(l, oldJoinID).State)
+
+	_, err := l.GetTicket(joinIDAt(time.Now().Add(-time.Hour)))
+	assert.ErrorIs(t, err, ErrTicketNotFound)
+}
@@ -0,0 +1,172 @@
+package lobby
+
+import (
+	"fmt"
+	"log"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/TanyEm/match-maker/v2/internal/match"
+)
+
+// AnyLevel is a level band that accepts players of any level
+const AnyLevel = -1
+
+// WideningStep sets the acceptable level band of a player who has waited in the lobby at least After.
+// The band is the largest level difference between the matches the player can be merged with.
+type WideningStep struct {
+	After time.Duration
+	Band  int
+}
+
+// UnmarshalText parses a step in the "<duration>:<band>" format, e.g. "10s:3" or "30s:any"
+func (s *WideningStep) UnmarshalText(text []byte) error {
+	after, band, found := strings.Cut(strings.TrimSpace(string(text)), ":")
+	if !found {
+		return fmt.Errorf("widening step %q is not in the <duration>:<band> format", text)
+	}
+
+	d, err := time.ParseDuration(after)
+	if err != nil {
+		return fmt.Errorf("widening step %q has invalid duration: %w", text, err)
+	}
+
+	s.After = d
+
+	if band == "any" {
+		s.Band = AnyLevel
+		return nil
+	}
+
+	b, err := strconv.Atoi(band)
+	if err != nil {
+		return fmt.Errorf("widening step %q has invalid band, expected a number or \"any\": %w", text, err)
+	}
+
+	s.Band = b
+
+	return nil
+}
+
+// ValidateWidening checks the steps are in the order of their durations and have valid bands
+func ValidateWidening(steps []WideningStep) error {
+	for i, step := range steps {
+		if step.After < 0 {
+			return fmt.Errorf("step %d: duration must not be negative, got %s", i, step.After)
+		}
+
+		if step.Band < 0 && step.Band != AnyLevel {
+			return fmt.Errorf("step %d: band must not be negative, got %d", i, step.Band)
+		}
+
+		if i > 0 && step.After <= steps[i-1].After {
+			return fmt.Errorf("step %d: duration %s must be greater than %s of the previous step", i, step.After, steps[i-1].After)
+		}
+	}
+
+	return nil
+}
+
+// levelBand returns the level band of a player who has waited for the given time.
+// It returns false if the player's band has not been widened yet.
+func levelBand(steps []WideningStep, waited time.Duration) (int, bool) {
+	band, widened := 0, false
+	for _, step := range steps {
+		if waited < step.After {
+			break
+		}
+		band, widened = step.Band, true
+	}
+
+	return band, widened
+}
+
+// WidenMatches merges the waiting matches of the same location whose level difference
+// fits into the widened level band of the longest waiting player of either match
+func (l *Lobby) WidenMatches(now time.Time) {
+	l.queueMu.Lock()
+	defer l.queueMu.Unlock()
+
+	if len(l.Config.LevelWidening) == 0 {
+		return
+	}
+
+	l.matchLocations.Range(func(_, loaded interface{}) bool {
+		matchLocation := loaded.(*match.MatchLocation)
+
+		matches := []*match.Match{}
+		matchLocation.Range(func(m *match.Match) bool {
+			matches = append(matches, m)
+			return true
+		})
+
+		// Merge the longest waiting matches first
+		sort.Slice(matches, func(i, j int) bool {
+			return matches[i].WaitingSince().Before(matches[j].WaitingSince())
+		})
+
+		merged := map[*match.Match]bool{}
+		for i, m := range matches {
+			if merged[m] {
+				continue
+			}
+
+			for _, other := range matches[i+1:] {
+				if merged[other] || merged[m] || !l.canMerge(m, other, now) {
+					continue
+				}
+
+				// Keep the bigger match and its level bucket
+				target, source := m, other
+				if source.GetPlayersCount() > target.GetPlayersCount() {
+					target, source = source, target
+				}
+
+				log.Printf("Level band widened, merging match %s level %d into match %s level %d", source.MatchID, source.Level, target.MatchID, target.Level)
+				target.Merge(source)
+				matchLocation.Delete(source)
+				merged[source] = true
+
+				if target.GetPlayersCount() >= l.Config.MaxMatchSize && l.StartMatch(target, matchLocation) {
+					matchLocation.Delete(target)
+					merged[target] = true
+				}
+			}
+		}
+
+		return true
+	})
+}
+
+func (l *Lobby) canMerge(a, b *match.Match, now time.Time) bool {
+	if a.GetPlayersCount()+b.GetPlayersCount() > l.Config.MaxMatchSize || !l.fitTeams(slices.Concat(a.GetPlayers(), b.GetPlayers())) {
+		return false
+	}
+
+	return l.withinWidenedBand(a, b, now)
+}
+
+// withinWidenedBand tells if the level difference of the matches fits into the widened level band
+// of the longest waiting player of either match
+func (l *Lobby) withinWidenedBand(a, b *match.Match, now time.Time) bool {
+	diff := a.Level - b.Level
+	if diff < 0 {
+		diff = -diff
+	}
+
+	for _, m := range []*match.Match{a, b} {
+		band, widened := levelBand(l.Config.LevelWidening, now.Sub(m.WaitingSince()))
+		if !widened {
+			continue
+		}
+
+		if band == AnyLevel || diff <= band {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,101 @@
+package lobby
+
+import (
+	"testing"
+	"time"
+
+	"github.com/TanyEm/match-maker/v2/internal/match"
+	"github.com/TanyEm/match-maker/v2/internal/player"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestWideningStep_UnmarshalText(t *testing.T) {
+	tests := []struct {
+		name          string
+		text          string
+		expected      WideningStep
+		expectedError bool
+	}{
+		{"numeric band", "10s:3", WideningStep{After: 10 * time.Second, Band: 3}, false},
+		{"any band", "30s:any", WideningStep{After: 30 * time.Second, Band: AnyLevel}, false},
+		{"missing band", "10s", WideningStep{}, true},
+		{"invalid duration", "ten:3", WideningStep{}, true},
+		{"invalid band", "10s:wide", WideningStep{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var step WideningStep
+			err := step.UnmarshalText([]byte(tt.text))
+
+			assert.Equal(t, tt.expectedError, err != nil, "Unexpected error: %v", err)
+			if !tt.expectedError {
+				assert.Equal(t, tt.expected, step)
+			}
+		})
+	}
+}
+
+func TestValidateWidening(t *testing.T) {
+	assert.NoError(t, ValidateWidening(nil))
+	assert.NoError(t, ValidateWidening([]WideningStep{{10 * time.Second, 3}, {30 * time.Second, AnyLevel}}))
+	assert.Error(t, ValidateWidening([]WideningStep{{30 * time.Second, 3}, {10 * time.Second, 5}}))
+	assert.Error(t, ValidateWidening([]WideningStep{{10 * time.Second, -5}}))
+}
+
+func TestLevelBand(t *testing.T) {
+	steps := []WideningStep{{10 * time.Second, 3}, {20 * time.Second, 5}, {30 * time.Second, AnyLevel}}
+
+	tests := []struct {
+		waited          time.Duration
+		expectedBand    int
+		expectedWidened bool
+	}{
+		{5 * time.Second, 0, false},
+		{10 * time.Second, 3, true},
+		{25 * time.Second, 5, true},
+		{time.Minute, AnyLevel, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.waited.String(), func(t *testing.T) {
+			band, widened := levelBand(steps, tt.waited)
+			assert.Equal(t, tt.expectedBand, band)
+			assert.Equal(t, tt.expectedWidened, widened)
+		})
+	}
+}
+
+func TestLobby_WidenMatches(t *testing.T) {
+	mockMatchKeeper := match.NewMockKeeper(gomock.NewController(t))
+
+	cfg := testConfig(time.Minute)
+	cfg.LevelWidening = []WideningStep{{10 * time.Second, 3}, {30 * time.Second, AnyLevel}}
+	l := NewLobby(cfg, mockMatchKeeper, NewDefaultStrategy())
+
+	now := time.Now()
+	l.AddPlayer(player.Player{PlayerID: "1", JoinID: "join1", Level: 40, Country: "FIN", JoinedAt: now.Add(-15 * time.Second)})
+	l.AddPlayer(player.Player{PlayerID: "2", JoinID: "join2", Level: 43, Country: "FIN", JoinedAt: now})
+	l.AddPlayer(player.Player{PlayerID: "3", JoinID: "join3", Level: 60, Country: "FIN", JoinedAt: now})
+
+	countMatches := func() int {
+		location, _ := l.matchLocations.Load("FIN")
+		count := 0
+		location.(*match.MatchLocation).Range(func(_ *match.Match) bool {
+			count++
+			return true
+		})
+		return count
+	}
+
+	assert.Equal(t, 3, countMatches(), "Expected players to wait in separate matches")
+
+	// The level 40 player waited for 15 seconds, so the band is widened to 3 levels
+	l.WidenMatches(now)
+	assert.Equal(t, 2, countMatches(), "Expected level 40 and 43 players to be merged")
+
+	// After 30 seconds anyone can be merged
+	l.WidenMatches(now.Add(20 * time.Second))
+	assert.Equal(t, 1, countMatches(), "Expected all players to be merged")
+}
@@ -0,0 +1,73 @@
+package match
+
+import "time"
+
+// Details is the full record of a match: where it was made, its lifecycle and the roster
+// with the lobby tickets the players were matched with
+type Details struct {
+	MatchID string `json:"match_id"`
+	// Queue is the name of the queue (game mode) the match was made in
+	Queue string `json:"queue,omitempty"`
+	// Country is the country the match was made in
+	Country string `json:"country"`
+	// Level is the level (or rating) bucket the match was made in
+	Level     int        `json:"level"`
+	Status    Status     `json:"status"`
+	CreatedAt time.Time  `json:"created_at"`
+	StartedAt time.Time  `json:"started_at"`
+	EndedAt   *time.Time `json:"ended_at,omitempty"`
+	// Final is set once the final scores of the match are reported
+	Final   bool          `json:"final,omitempty"`
+	Players []RosterEntry `json:"players"`
+	Teams   []Team        `json:"teams,omitempty"`
+}
+
+// RosterEntry is a player of the match with the lobby ticket the player was matched with
+type RosterEntry struct {
+	PlayerInfo
+	JoinID string `json:"join_id"`
+	// QueueTime is the number of seconds the player waited in the lobby before joining the match
+	QueueTime float64 `json:"queue_time"`
+}
+
+// Details returns the full record of the match, the players are ranked by their scores
+func (lb LeaderBoard) Details() Details {
+	ranked := lb.Ranked()
+
+	details := Details{
+		MatchID:   lb.MatchID,
+		Queue:     lb.Queue,
+		Country:   lb.Country,
+		Level:     lb.Level,
+		Status:    lb.Status,
+		CreatedAt: lb.CreatedAt,
+		StartedAt: lb.StartedAt,
+		EndedAt:   lb.EndedAt,
+		Final:     lb.Final,
+		Players:   make([]RosterEntry, 0, len(ranked.Players)),
+		Teams:     lb.Teams,
+	}
+
+	for _, p := range ranked.Players {
+		details.Players = append(details.Players, RosterEntry{
+			PlayerInfo: p,
+			JoinID:     p.JoinID,
+			QueueTime:  p.QueueTime.Seconds(),
+		})
+	}
+
+	return details
+}
+
+// GetMatch returns the full record of the match
+func (s *Storage) GetMatch(matchID string) (Details, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	lb, ok := s.matches[matchID]
+	if !ok {
+		return Details{}, ErrMatchNotFound
+	}
+
+	return lb.Details(), nil
+}
@@ -0,0 +1,55 @@
+package match
+
+import (
+	"testing"
+	"time"
+
+	"github.com/TanyEm/match-maker/v2/internal/player"
+)
+
+func TestGetMatch(t *testing.T) {
+	now := time.Now()
+	m := NewMatch("FIN", 3)
+	m.AddPlayer(player.Player{PlayerID: "1", JoinID: "join1", Level: 4, Country: "FIN", JoinedAt: now.Add(-20 * time.Second)})
+	m.AddPlayer(player.Player{PlayerID: "2", JoinID: "join2", Level: 3, Country: "SWE", JoinedAt: now.Add(-10 * time.Second)})
+	m.Start()
+
+	storage := NewStorage()
+	lb := m.GetLeaderboard()
+	lb.Queue = "ranked"
+	storage.AddLeaderBoard(&lb)
+	storage.AddPlayers(m.MatchID, []PlayerInfo{{PlayerID: "3", JoinID: "join3", Level: 3, Country: "FIN", QueueTime: 5 * time.Second}})
+	storage.ReportScores(m.MatchID, []Score{{PlayerID: "3", Score: 10}}, false)
+
+	if _, err := storage.GetMatch("match2"); err != ErrMatchNotFound {
+		t.Errorf("Expected ErrMatchNotFound, got %v", err)
+	}
+
+	details, err := storage.GetMatch(m.MatchID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if details.Country != "FIN" || details.Level != 3 || details.Queue != "ranked" || details.Status != StatusStarted {
+		t.Errorf("Expected the match made in FIN at level 3 of the ranked queue, got %+v", details)
+	}
+
+	// The roster is ranked by the scores and linked to the lobby tickets
+	expected := []struct {
+		joinID    string
+		queueTime float64
+	}{{"join3", 5}, {"join1", 20}, {"join2", 10}}
+	if len(details.Players) != len(expected) {
+		t.Fatalf("Expected %d players, got %d", len(expected), len(details.Players))
+	}
+
+	for i, p := range details.Players {
+		if p.JoinID != expected[i].joinID {
+			t.Errorf("Expected %s at %d, got %s", expected[i].joinID, i, p.JoinID)
+		}
+
+		if p.QueueTime < expected[i].queueTime || p.QueueTime > expected[i].queueTime+1 {
+			t.Errorf("Expected %s to wait for %vs, got %vs", p.JoinID, expected[i].queueTime, p.QueueTime)
+		}
+	}
+}
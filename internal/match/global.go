@@ -0,0 +1,218 @@
+package match
+
+import (
+	"slices"
+	"sort"
+)
+
+// Order is the order of the players on the global leaderboards
+type Order string
+
+const (
+	OrderScore  Order = "score"
+	OrderRating Order = "rating"
+)
+
+const (
+	// levelBand is the width of the level bands of the global leaderboards, the bands are 1-9, 10-19, ...
+	levelBand = 10
+	// anyBand ranks the players of all levels
+	anyBand = -1
+)
+
+// LevelBand returns the lowest and the highest level of the level band of the level
+func LevelBand(level int) (int, int) {
+	band := level / levelBand
+	return max(band*levelBand, 1), band*levelBand + levelBand - 1
+}
+
+// PlayerTotal is the standing of a player across the matches
+type PlayerTotal struct {
+	PlayerID string `json:"player_id"`
+	// Country and Level are the country and the level of the player in the latest reported match
+	Country string `json:"country"`
+	Level   int    `json:"level"`
+	// Score is the sum of the scores of the player in all the matches
+	Score int `json:"score"`
+	// Matches is the number of the matches with the final scores the player played in
+	Matches int `json:"matches"`
+	// Rating is the latest rating of the player, 0 if the player has not been rated
+	Rating float64 `json:"rating,omitempty"`
+	// Rank is the place of the player on the leaderboard starting from 1, the players with
+	// the same score (or rating) share the rank
+	Rank int `json:"rank"`
+	// rated is set once the rating of the player is reported, only rated players are ranked by the rating
+	rated bool
+}
+
+// BoardFilter selects the global leaderboard and its page
+type BoardFilter struct {
+	// Country selects the players of the country, empty for all countries
+	Country string
+	// Level selects the players of the level band of the level, 0 for all levels
+	Level int
+	// Order is the order of the players, OrderScore if it is empty
+	Order  Order
+	Offset int
+	// Limit is the largest number of the returned players, 0 for no limit
+	Limit int
+}
+
+// boardKey is the country, the level band and the order a global leaderboard is kept by
+type boardKey struct {
+	country string
+	band    int
+	order   Order
+}
+
+// boardKeys returns the keys of the leaderboards the player is ranked on
+func (t *PlayerTotal) boardKeys() []boardKey {
+	orders := []Order{OrderScore}
+	if t.rated {
+		orders = append(orders, OrderRating)
+	}
+
+	keys := make([]boardKey, 0, 4*len(orders))
+	for _, order := range orders {
+		for _, country := range []string{"", t.Country} {
+			for _, band := range []int{anyBand, t.Level / levelBand} {
+				keys = append(keys, boardKey{country, band, order})
+			}
+		}
+	}
+
+	return keys
+}
+
+// board is a global leaderboard, the players are kept sorted as their totals change,
+// so that a page of the leaderboard is read without sorting all the players
+type board struct {
+	order   Order
+	players []*PlayerTotal
+}
+
+// value returns the score or the rating the player is ranked by
+func (b *board) value(t *PlayerTotal) float64 {
+	if b.order == OrderRating {
+		return t.Rating
+	}
+
+	return float64(t.Score)
+}
+
+// ahead tells if the player x is placed before the player y, the players
+// with the same value are placed by their IDs
+func (b *board) ahead(x, y *PlayerTotal) bool {
+	if vx, vy := b.value(x), b.value(y); vx != vy {
+		return vx > vy
+	}
+
+	return x.PlayerID < y.PlayerID
+}
+
+// search returns the position of the player on the board
+func (b *board) search(t *PlayerTotal) int {
+	return sort.Search(len(b.players), func(i int) bool { return !b.ahead(b.players[i], t) })
+}
+
+func (b *board) insert(t *PlayerTotal) {
+	b.players = slices.Insert(b.players, b.search(t), t)
+}
+
+// remove takes the player off the board, it must be done before the total of the player changes
+func (b *board) remove(t *PlayerTotal) {
+	if i := b.search(t); i < len(b.players) && b.players[i] == t {
+		b.players = slices.Delete(b.players, i, i+1)
+	}
+}
+
+// rank returns the rank of the player at the position, the first position of the players with the same value
+func (b *board) rank(i int) int {
+	value := b.value(b.players[i])
+	return sort.Search(i, func(j int) bool { return b.value(b.players[j]) <= value }) + 1
+}
+
+// updateTotal changes the total of the player and moves the player on the global leaderboards.
+// The caller must hold mu.
+func (s *Storage) updateTotal(p PlayerInfo, update func(t *PlayerTotal)) {
+	t, ok := s.totals[p.PlayerID]
+	if ok {
+		for _, key := range t.boardKeys() {
+			s.boards[key].remove(t)
+		}
+	} else {
+		t = &PlayerTotal{PlayerID: p.PlayerID}
+		s.totals[p.PlayerID] = t
+	}
+
+	t.Country = p.Country
+	t.Level = p.Level
+	update(t)
+
+	for _, key := range t.boardKeys() {
+		b, ok := s.boards[key]
+		if !ok {
+			b = &board{order: key.order}
+			s.boards[key] = b
+		}
+		b.insert(t)
+	}
+}
+
+// UpdateRatings sets the ratings of the players of the match on the global leaderboards
+func (s *Storage) UpdateRatings(matchID string, ratings map[string]float64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	lb, ok := s.matches[matchID]
+	if !ok {
+		return ErrMatchNotFound
+	}
+
+	for _, p := range lb.Players {
+		if r, ok := ratings[p.PlayerID]; ok {
+			s.updateTotal(p, func(t *PlayerTotal) {
+				t.Rating = r
+				t.rated = true
+			})
+		}
+	}
+
+	return nil
+}
+
+// GlobalLeaderBoard returns the page of the players ranked across all the matches and the number
+// of all the players of the leaderboard
+func (s *Storage) GlobalLeaderBoard(filter BoardFilter) ([]PlayerTotal, int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := boardKey{country: filter.Country, band: anyBand, order: filter.Order}
+	if filter.Level > 0 {
+		key.band = filter.Level / levelBand
+	}
+
+	if key.order == "" {
+		key.order = OrderScore
+	}
+
+	players := []PlayerTotal{}
+	b, ok := s.boards[key]
+	if !ok {
+		return players, 0
+	}
+
+	start := min(filter.Offset, len(b.players))
+	end := len(b.players)
+	if filter.Limit > 0 {
+		end = min(start+filter.Limit, end)
+	}
+
+	for i := start; i < end; i++ {
+		t := *b.players[i]
+		t.Rank = b.rank(i)
+		players = append(players, t)
+	}
+
+	return players, len(b.players)
+}
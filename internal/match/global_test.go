@@ -0,0 +1,88 @@
+package match
+
+import "testing"
+
+func TestLevelBand(t *testing.T) {
+	for level, expected := range map[int][2]int{1: {1, 9}, 9: {1, 9}, 10: {10, 19}, 55: {50, 59}, 99: {90, 99}} {
+		if from, to := LevelBand(level); from != expected[0] || to != expected[1] {
+			t.Errorf("Expected level %d in band %d-%d, got %d-%d", level, expected[0], expected[1], from, to)
+		}
+	}
+}
+
+func TestGlobalLeaderBoard(t *testing.T) {
+	storage := NewStorage()
+	storage.AddLeaderBoard(&LeaderBoard{
+		MatchID: "match1",
+		Players: []PlayerInfo{
+			{PlayerID: "1", Country: "FIN", Level: 12},
+			{PlayerID: "2", Country: "FIN", Level: 25},
+			{PlayerID: "3", Country: "SWE", Level: 15},
+		},
+	})
+	storage.AddLeaderBoard(&LeaderBoard{
+		MatchID: "match2",
+		Players: []PlayerInfo{
+			{PlayerID: "1", Country: "FIN", Level: 13},
+			{PlayerID: "4", Country: "SWE", Level: 18},
+		},
+	})
+
+	// The scores are ranked as they are reported
+	storage.ReportScores("match1", []Score{{PlayerID: "1", Score: 10}, {PlayerID: "2", Score: 30}}, false)
+	storage.ReportScores("match2", []Score{{PlayerID: "1", Score: 5}, {PlayerID: "4", Score: 25}}, false)
+	assertBoard(t, storage, BoardFilter{}, []string{"2", "4", "1"}, []int{1, 2, 3})
+
+	// The final scores replace the reported ones and count the match for all its players
+	storage.ReportScores("match1", []Score{{PlayerID: "1", Score: 20}}, true)
+	players := assertBoard(t, storage, BoardFilter{}, []string{"2", "1", "4", "3"}, []int{1, 2, 2, 4})
+	if players[1].Score != 25 || players[1].Matches != 1 || players[1].Level != 12 {
+		t.Errorf("Expected player 1 to have 25 points of 1 match at level 12, got %+v", players[1])
+	}
+
+	// The boards of the countries and the level bands
+	assertBoard(t, storage, BoardFilter{Country: "SWE"}, []string{"4", "3"}, []int{1, 2})
+	assertBoard(t, storage, BoardFilter{Level: 15}, []string{"1", "4", "3"}, []int{1, 1, 3})
+	assertBoard(t, storage, BoardFilter{Country: "FIN", Level: 10}, []string{"1"}, []int{1})
+	assertBoard(t, storage, BoardFilter{Country: "NOR"}, []string{}, []int{})
+
+	// The pages keep the ranks of the whole board
+	assertBoard(t, storage, BoardFilter{Offset: 2, Limit: 1}, []string{"4"}, []int{2})
+	assertBoard(t, storage, BoardFilter{Offset: 10}, []string{}, []int{})
+	if _, total := storage.GlobalLeaderBoard(BoardFilter{Offset: 1, Limit: 1}); total != 4 {
+		t.Errorf("Expected 4 players on the board, got %d", total)
+	}
+
+	// Only the rated players are ranked by the rating
+	if err := storage.UpdateRatings("match3", map[string]float64{"1": 1500}); err != ErrMatchNotFound {
+		t.Errorf("Expected ErrMatchNotFound, got %v", err)
+	}
+
+	storage.UpdateRatings("match2", map[string]float64{"1": 1480, "4": 1520})
+	storage.UpdateRatings("match1", map[string]float64{"3": 1520})
+	players = assertBoard(t, storage, BoardFilter{Order: OrderRating}, []string{"3", "4", "1"}, []int{1, 1, 3})
+	if players[2].Rating != 1480 || players[2].Score != 25 || players[2].Level != 13 {
+		t.Errorf("Expected player 1 to keep 25 points at level 13 with the rating 1480, got %+v", players[2])
+	}
+
+	// The player has moved to the country band of the latest match
+	assertBoard(t, storage, BoardFilter{Country: "FIN", Level: 10, Order: OrderRating}, []string{"1"}, []int{1})
+	assertBoard(t, storage, BoardFilter{}, []string{"2", "1", "4", "3"}, []int{1, 2, 2, 4})
+}
+
+func assertBoard(t *testing.T, storage *Storage, filter BoardFilter, playerIDs []string, ranks []int) []PlayerTotal {
+	t.Helper()
+
+	players, _ := storage.GlobalLeaderBoard(filter)
+	if players == nil || len(players) != len(playerIDs) {
+		t.Fatalf("Expected %d players on the board %+v, got %+v", len(playerIDs), filter, players)
+	}
+
+	for i, p := range players {
+		if p.PlayerID != playerIDs[i] || p.Rank != ranks[i] {
+			t.Errorf("Expected player %s ranked %d at %d on the board %+v, got %s ranked %d", playerIDs[i], ranks[i], i, filter, p.PlayerID, p.Rank)
+		}
+	}
+
+	return players
+}
@@ -0,0 +1,103 @@
+package match
+
+import "time"
+
+// HistoryFilter selects the matches of a player's history
+type HistoryFilter struct {
+	// From and To limit the start times of the matches, zero for no limit
+	From time.Time
+	To   time.Time
+	// Status selects the matches of the status, empty for any status
+	Status Status
+	// Offset is the number of the matches to skip, the latest matches first
+	Offset int
+	// Limit is the largest number of the returned matches, 0 for no limit
+	Limit int
+}
+
+// matches tells the match is selected by the filter
+func (f HistoryFilter) matches(lb *LeaderBoard) bool {
+	if !f.From.IsZero() && lb.StartedAt.Before(f.From) {
+		return false
+	}
+
+	if !f.To.IsZero() && lb.StartedAt.After(f.To) {
+		return false
+	}
+
+	return f.Status == "" || lb.Status == f.Status
+}
+
+// HistoryEntry summarises a match of a player
+type HistoryEntry struct {
+	MatchID   string     `json:"match_id"`
+	Queue     string     `json:"queue,omitempty"`
+	Status    Status     `json:"status"`
+	StartedAt time.Time  `json:"started_at"`
+	EndedAt   *time.Time `json:"ended_at,omitempty"`
+	// Score is the score of the player in the match
+	Score int `json:"score"`
+	// Rank is the placement of the player in the match by the score starting from 1
+	Rank int `json:"rank"`
+	// Players is the number of the players in the match
+	Players int `json:"players"`
+	// Team is the team of the player, 0 if the match has no teams
+	Team int `json:"team,omitempty"`
+}
+
+// indexPlayers adds the match to the history of the players. The caller must hold mu.
+func (s *Storage) indexPlayers(matchID string, players []PlayerInfo) {
+	for _, p := range players {
+		s.playerMatches[p.PlayerID] = append(s.playerMatches[p.PlayerID], matchID)
+	}
+}
+
+// PlayerMatches returns the page of the matches the player has played, the latest first,
+// and the number of all matches selected by the filter
+func (s *Storage) PlayerMatches(playerID string, filter HistoryFilter) ([]HistoryEntry, int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	matchIDs := s.playerMatches[playerID]
+
+	entries := []HistoryEntry{}
+	total := 0
+	for i := len(matchIDs) - 1; i >= 0; i-- {
+		lb := s.matches[matchIDs[i]]
+		if !filter.matches(lb) {
+			continue
+		}
+
+		total++
+		if total <= filter.Offset || (filter.Limit > 0 && len(entries) == filter.Limit) {
+			continue
+		}
+
+		entries = append(entries, historyEntry(lb, playerID))
+	}
+
+	return entries, total
+}
+
+// historyEntry summarises the match for the player
+func historyEntry(lb *LeaderBoard, playerID string) HistoryEntry {
+	entry := HistoryEntry{
+		MatchID:   lb.MatchID,
+		Queue:     lb.Queue,
+		Status:    lb.Status,
+		StartedAt: lb.StartedAt,
+		EndedAt:   lb.EndedAt,
+		Players:   len(lb.Players),
+	}
+
+	for _, p := range lb.Ranked().Players {
+		if p.PlayerID == playerID {
+			entry.Score = p.Score
+			entry.Rank = p.Rank
+			entry.Team = p.Team
+			break
+		}
+	}
+
+	return entry
+}
@@ -0,0 +1,69 @@
+package match
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPlayerMatches(t *testing.T) {
+	startedAt := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	storage := NewStorage()
+	for i, matchID := range []string{"match1", "match2", "match3"} {
+		storage.AddLeaderBoard(&LeaderBoard{
+			MatchID:   matchID,
+			Status:    StatusStarted,
+			StartedAt: startedAt.Add(time.Duration(i) * time.Hour),
+			Players:   []PlayerInfo{{PlayerID: "1", Team: 1}, {PlayerID: "2", Team: 2}},
+		})
+	}
+	// Adding the match again does not repeat it in the history
+	storage.AddLeaderBoard(storage.GetLeaderBoard("match1"))
+
+	// The backfilled player gets the match into the history
+	storage.AddPlayers("match2", []PlayerInfo{{PlayerID: "3", Team: 1}})
+	storage.ReportScores("match2", []Score{{PlayerID: "1", Score: 5}, {PlayerID: "3", Score: 10}}, true)
+	storage.EndMatch("match2", StatusFinished)
+
+	entries, total := storage.PlayerMatches("1", HistoryFilter{})
+	if total != 3 || len(entries) != 3 {
+		t.Fatalf("Expected 3 matches, got %d of %d", len(entries), total)
+	}
+
+	// The latest match is the first
+	for i, matchID := range []string{"match3", "match2", "match1"} {
+		if entries[i].MatchID != matchID {
+			t.Errorf("Expected %s at %d, got %s", matchID, i, entries[i].MatchID)
+		}
+	}
+
+	if e := entries[1]; e.Score != 5 || e.Rank != 2 || e.Players != 3 || e.Team != 1 || e.Status != StatusFinished || e.EndedAt == nil {
+		t.Errorf("Expected the player to be second of 3 with 5 points in the finished match, got %+v", e)
+	}
+
+	entries, total = storage.PlayerMatches("3", HistoryFilter{})
+	if total != 1 || entries[0].MatchID != "match2" || entries[0].Rank != 1 {
+		t.Errorf("Expected the backfilled player to win match2, got %+v of %d", entries, total)
+	}
+
+	// The filters are applied before the page is cut
+	entries, total = storage.PlayerMatches("1", HistoryFilter{Status: StatusStarted, Offset: 1, Limit: 5})
+	if total != 2 || len(entries) != 1 || entries[0].MatchID != "match1" {
+		t.Errorf("Expected the second of 2 started matches, got %+v of %d", entries, total)
+	}
+
+	entries, total = storage.PlayerMatches("1", HistoryFilter{From: startedAt.Add(time.Hour), Limit: 1})
+	if total != 2 || len(entries) != 1 || entries[0].MatchID != "match3" {
+		t.Errorf("Expected the first of 2 matches since the second hour, got %+v of %d", entries, total)
+	}
+
+	entries, total = storage.PlayerMatches("1", HistoryFilter{To: startedAt.Add(time.Hour)})
+	if total != 2 || len(entries) != 2 || entries[0].MatchID != "match2" {
+		t.Errorf("Expected 2 matches until the second hour, got %+v of %d", entries, total)
+	}
+
+	entries, total = storage.PlayerMatches("4", HistoryFilter{})
+	if total != 0 || entries == nil || len(entries) != 0 {
+		t.Errorf("Expected an empty history, got %+v of %d", entries, total)
+	}
+}
@@ -1,8 +1,26 @@
 package match
 
+import "time"
+
 type LeaderBoard struct {
-	MatchID string       `json:"match_id"`
+	MatchID string `json:"match_id"`
+	// Queue is the name of the queue (game mode) the match was made in
+	Queue   string       `json:"queue,omitempty"`
 	Players []PlayerInfo `json:"players"`
+	Teams   []Team       `json:"teams,omitempty"`
+	// Final is set once the final scores of the match are reported
+	Final  bool   `json:"final,omitempty"`
+	Status Status `json:"status"`
+	// CreatedAt is the time the match was created in the lobby
+	CreatedAt time.Time `json:"created_at"`
+	// StartedAt is the time the match was started, zero if it has not started yet
+	StartedAt time.Time `json:"started_at"`
+	// EndedAt is the time the match was finished or abandoned, nil while it is being played
+	EndedAt *time.Time `json:"ended_at,omitempty"`
+	// Country is the country the match was made in, the players of a merged match may come from other countries
+	Country string `json:"-"`
+	// Level is the level (or rating) bucket the match was made in
+	Level int `json:"-"`
 }
 
 type PlayerInfo struct {
@@ -10,4 +28,21 @@ type PlayerInfo struct {
 	Level    int    `json:"level"`
 	Country  string `json:"country"`
 	Score    int    `json:"score"`
+	// Rank is the place of the player by the score starting from 1, see LeaderBoard.Ranked
+	Rank int `json:"rank,omitempty"`
+	// Team is the number of the player's team starting from 1, 0 if the match has no teams
+	Team int `json:"team,omitempty"`
+	// PartyID is shared by the players who queued together
+	PartyID string `json:"party_id,omitempty"`
+	// JoinID is the lobby ticket the player was matched with, see Details
+	JoinID string `json:"-"`
+	// QueueTime is the time the player waited in the lobby before joining the match
+	QueueTime time.Duration `json:"-"`
+}
+
+type Team struct {
+	Team          int      `json:"team"`
+	PlayerIDs     []string `json:"player_ids"`
+	AverageLevel  float64  `json:"average_level"`
+	AverageRating float64  `json:"average_rating"`
 }
@@ -0,0 +1,61 @@
+package match
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	ErrMatchEnded    = errors.New("match is already ended")
+	ErrInvalidStatus = errors.New("invalid match status")
+)
+
+// Status is the stage of the lifecycle of a match. A match is created in the lobby, started
+// once it has enough players and then finished or abandoned by its game server.
+type Status string
+
+const (
+	// StatusCreated is a match waiting in the lobby for more players
+	StatusCreated Status = "created"
+	// StatusStarted is a match being played
+	StatusStarted Status = "started"
+	// StatusFinished is a match played to the end
+	StatusFinished Status = "finished"
+	// StatusAbandoned is a match stopped before the end, e.g. the players have left it
+	StatusAbandoned Status = "abandoned"
+)
+
+// Ended tells the match is finished or abandoned
+func (lb LeaderBoard) Ended() bool {
+	return lb.Status == StatusFinished || lb.Status == StatusAbandoned
+}
+
+// EndMatch moves the started match into the finished or abandoned status and records the end time.
+// The stored leaderboard is replaced by an updated copy, so the leaderboards returned before are not changed.
+func (s *Storage) EndMatch(matchID string, status Status) (*LeaderBoard, error) {
+	if status != StatusFinished && status != StatusAbandoned {
+		return nil, fmt.Errorf("%w %q, expected %q or %q", ErrInvalidStatus, status, StatusFinished, StatusAbandoned)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	lb, ok := s.matches[matchID]
+	if !ok {
+		return nil, ErrMatchNotFound
+	}
+
+	if lb.Ended() {
+		return nil, ErrMatchEnded
+	}
+
+	endedAt := time.Now()
+	updated := *lb
+	updated.Status = status
+	updated.EndedAt = &endedAt
+
+	s.matches[matchID] = &updated
+
+	return &updated, nil
+}
@@ -0,0 +1,65 @@
+package match
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/TanyEm/match-maker/v2/internal/player"
+)
+
+func TestMatch_Lifecycle(t *testing.T) {
+	m := NewMatch("FIN", 10)
+	m.AddPlayer(player.Player{PlayerID: "1", JoinID: "join1", Level: 10, Country: "FIN"})
+
+	lb := m.GetLeaderboard()
+	if lb.Status != StatusCreated || lb.CreatedAt.IsZero() || !lb.StartedAt.IsZero() {
+		t.Errorf("Expected a created match, got %s created at %s started at %s", lb.Status, lb.CreatedAt, lb.StartedAt)
+	}
+
+	m.Start()
+
+	lb = m.GetLeaderboard()
+	if lb.Status != StatusStarted || lb.StartedAt.Before(lb.CreatedAt) {
+		t.Errorf("Expected a started match, got %s created at %s started at %s", lb.Status, lb.CreatedAt, lb.StartedAt)
+	}
+
+	if lb.EndedAt != nil || lb.Ended() {
+		t.Errorf("Expected the match not to be ended")
+	}
+}
+
+func TestEndMatch(t *testing.T) {
+	storage := NewStorage()
+	lb := &LeaderBoard{MatchID: "match1", Status: StatusStarted, StartedAt: time.Now()}
+	storage.AddLeaderBoard(lb)
+
+	if _, err := storage.EndMatch("match2", StatusFinished); err != ErrMatchNotFound {
+		t.Errorf("Expected ErrMatchNotFound, got %v", err)
+	}
+
+	if _, err := storage.EndMatch("match1", StatusStarted); !errors.Is(err, ErrInvalidStatus) {
+		t.Errorf("Expected ErrInvalidStatus, got %v", err)
+	}
+
+	ended, err := storage.EndMatch("match1", StatusAbandoned)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if ended.Status != StatusAbandoned || ended.EndedAt == nil || ended.EndedAt.Before(lb.StartedAt) {
+		t.Errorf("Expected an abandoned match with the end time, got %s ended at %v", ended.Status, ended.EndedAt)
+	}
+
+	if storage.GetLeaderBoard("match1") != ended {
+		t.Errorf("Expected the ended leaderboard to be stored")
+	}
+
+	if lb.Status != StatusStarted || lb.EndedAt != nil {
+		t.Errorf("Expected the previous leaderboard not to change")
+	}
+
+	if _, err := storage.EndMatch("match1", StatusFinished); err != ErrMatchEnded {
+		t.Errorf("Expected ErrMatchEnded, got %v", err)
+	}
+}
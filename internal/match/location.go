@@ -0,0 +1,102 @@
+package match
+
+import (
+	"slices"
+	"sort"
+	"sync"
+)
+
+// MatchLocation keeps the waiting matches of a country by their level buckets. A bucket can hold
+// several matches, e.g. when a party does not fit into the match waiting at the bucket of its level.
+// Every match is kept at the bucket of its Level.
+type MatchLocation struct {
+	buckets map[int][]*Match
+	mu      sync.Mutex
+}
+
+// Load returns the oldest match waiting at the level bucket
+func (ml *MatchLocation) Load(level int) (*Match, bool) {
+	ml.mu.Lock()
+	defer ml.mu.Unlock()
+
+	if len(ml.buckets[level]) == 0 {
+		return nil, false
+	}
+
+	return ml.buckets[level][0], true
+}
+
+// Bucket returns the matches waiting at the level bucket, the oldest first
+func (ml *MatchLocation) Bucket(level int) []*Match {
+	ml.mu.Lock()
+	defer ml.mu.Unlock()
+
+	return slices.Clone(ml.buckets[level])
+}
+
+// Store adds the match to the bucket of its level
+func (ml *MatchLocation) Store(m *Match) {
+	ml.mu.Lock()
+	defer ml.mu.Unlock()
+
+	ml.store(m)
+}
+
+// Delete removes the match from the bucket of its level
+func (ml *MatchLocation) Delete(m *Match) {
+	ml.mu.Lock()
+	defer ml.mu.Unlock()
+
+	ml.delete(m)
+}
+
+// Move moves the match to the level bucket and sets its Level
+func (ml *MatchLocation) Move(m *Match, level int) {
+	ml.mu.Lock()
+	defer ml.mu.Unlock()
+
+	ml.delete(m)
+	m.Level = level
+	ml.store(m)
+}
+
+// store adds the match to the bucket of its level. The caller must hold mu.
+func (ml *MatchLocation) store(m *Match) {
+	if ml.buckets == nil {
+		ml.buckets = make(map[int][]*Match)
+	}
+	ml.buckets[m.Level] = append(ml.buckets[m.Level], m)
+}
+
+// delete removes the match from the bucket of its level. The caller must hold mu.
+func (ml *MatchLocation) delete(m *Match) {
+	bucket := slices.DeleteFunc(ml.buckets[m.Level], func(other *Match) bool { return other == m })
+	if len(bucket) == 0 {
+		delete(ml.buckets, m.Level)
+		return
+	}
+	ml.buckets[m.Level] = bucket
+}
+
+// Range calls f for the waiting matches, the lowest buckets first, until f returns false.
+// The matches are taken before the first call, so f can store, move and delete matches.
+func (ml *MatchLocation) Range(f func(m *Match) bool) {
+	ml.mu.Lock()
+	levels := make([]int, 0, len(ml.buckets))
+	for level := range ml.buckets {
+		levels = append(levels, level)
+	}
+	sort.Ints(levels)
+
+	matches := []*Match{}
+	for _, level := range levels {
+		matches = append(matches, ml.buckets[level]...)
+	}
+	ml.mu.Unlock()
+
+	for _, m := range matches {
+		if !f(m) {
+			return
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package match
+
+import "testing"
+
+func TestMatchLocation(t *testing.T) {
+	location := &MatchLocation{}
+	if _, ok := location.Load(5); ok {
+		t.Fatal("Expected an empty location")
+	}
+
+	first, second, other := NewMatch("FIN", 5), NewMatch("FIN", 5), NewMatch("FIN", 3)
+	location.Store(first)
+	location.Store(second)
+	location.Store(other)
+
+	// A bucket keeps several matches, the oldest first
+	if m, ok := location.Load(5); !ok || m != first {
+		t.Errorf("Expected the first match at bucket 5, got %v", m)
+	}
+
+	if bucket := location.Bucket(5); len(bucket) != 2 || bucket[1] != second {
+		t.Errorf("Expected 2 matches at bucket 5, got %v", bucket)
+	}
+
+	location.Move(first, 4)
+	if first.Level != 4 || len(location.Bucket(5)) != 1 || len(location.Bucket(4)) != 1 {
+		t.Errorf("Expected the first match to move to bucket 4, got level %d", first.Level)
+	}
+
+	// The matches can be deleted while they are ranged over, the lowest buckets first
+	ranged := []*Match{}
+	location.Range(func(m *Match) bool {
+		ranged = append(ranged, m)
+		location.Delete(m)
+		return true
+	})
+
+	if len(ranged) != 3 || ranged[0] != other || ranged[1] != first || ranged[2] != second {
+		t.Errorf("Expected the matches of buckets 3, 4 and 5, got %v", ranged)
+	}
+
+	if _, ok := location.Load(5); ok {
+		t.Error("Expected the matches to be deleted")
+	}
+}
@@ -3,16 +3,12 @@ package match
 import (
 	"log"
 	"sync"
+	"time"
 
 	"github.com/TanyEm/match-maker/v2/internal/player"
 	"github.com/google/uuid"
 )
 
-// MatchLocation a map of country to a list of matches
-type MatchLocation struct {
-	sync.Map
-}
-
 type Matcher interface {
 	Match(matchID string) *Match
 	AddPlayer(p player.Player)
@@ -27,16 +23,20 @@ type Match struct {
 	Country string
 	players []player.Player
 	mu      sync.Mutex
-	started bool
+	teams   [][]player.Player
+	// createdAt is the time the match was created in the lobby
+	createdAt time.Time
+	// startedAt is the time the match was started, zero until then
+	startedAt time.Time
 }
 
 func NewMatch(country string, level int) *Match {
 	return &Match{
-		MatchID: uuid.New().String(),
-		Level:   level,
-		Country: country,
-		players: []player.Player{},
-		started: false,
+		MatchID:   uuid.New().String(),
+		Level:     level,
+		Country:   country,
+		players:   []player.Player{},
+		createdAt: time.Now(),
 	}
 }
 
@@ -59,8 +59,96 @@ func (m *Match) AddPlayer(p player.Player) {
 	log.Printf("Player %s level %d joined the match with %d people: country %s level %d matchID: %s", p.PlayerID, p.Level, len(m.players), m.Country, m.Level, m.MatchID)
 }
 
+// RemovePlayer removes the player with the join ID from the match
+func (m *Match) RemovePlayer(joinID string) (player.Player, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i, p := range m.players {
+		if p.JoinID == joinID {
+			m.players = append(m.players[:i], m.players[i+1:]...)
+			log.Printf("Player %s left the match with %d people: country %s level %d matchID: %s", p.PlayerID, len(m.players), m.Country, m.Level, m.MatchID)
+			return p, true
+		}
+	}
+
+	return player.Player{}, false
+}
+
+// Merge moves all players of the other match into this match
+func (m *Match) Merge(other *Match) {
+	for _, p := range other.GetPlayers() {
+		m.AddPlayer(p)
+	}
+}
+
+// WaitingSince returns the time the longest waiting player of the match joined the lobby
+func (m *Match) WaitingSince() time.Time {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var since time.Time
+	for _, p := range m.players {
+		if since.IsZero() || p.JoinedAt.Before(since) {
+			since = p.JoinedAt
+		}
+	}
+
+	return since
+}
+
+// AssignTeams splits the players of the match into count balanced teams.
+// The teams are left as they are if the parties do not fit into the teams, see BalanceTeams.
+func (m *Match) AssignTeams(count int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	teams, err := BalanceTeams(m.players, count)
+	if err != nil {
+		return err
+	}
+	m.teams = teams
+
+	return nil
+}
+
+// GetTeams returns the teams of the match, nil if the teams are not assigned
+func (m *Match) GetTeams() [][]player.Player {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.teams
+}
+
+// Deadline returns the earliest deadline of the players of the match
+func (m *Match) Deadline() time.Time {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var deadline time.Time
+	for _, p := range m.players {
+		if deadline.IsZero() || p.Deadline.Before(deadline) {
+			deadline = p.Deadline
+		}
+	}
+
+	return deadline
+}
+
+// SetDeadline moves the deadline of the player with the join ID
+func (m *Match) SetDeadline(joinID string, deadline time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i := range m.players {
+		if m.players[i].JoinID == joinID {
+			m.players[i].Deadline = deadline
+		}
+	}
+}
+
 func (m *Match) Start() []string {
-	m.started = true
+	m.startedAt = time.Now()
 	log.Printf("Match %s started. Notifying %d players...", m.MatchID, len(m.players))
 
 	joinIDs := make([]string, 0, len(m.players))
@@ -82,8 +170,36 @@ func (m *Match) GetPlayersCount() int {
 
 func (m *Match) GetLeaderboard() LeaderBoard {
 	leaderBoard := LeaderBoard{
-		MatchID: m.MatchID,
-		Players: make([]PlayerInfo, 0, len(m.players)),
+		MatchID:   m.MatchID,
+		Players:   make([]PlayerInfo, 0, len(m.players)),
+		Status:    StatusCreated,
+		CreatedAt: m.createdAt,
+		StartedAt: m.startedAt,
+		Country:   m.Country,
+		Level:     m.Level,
+	}
+	if !m.startedAt.IsZero() {
+		leaderBoard.Status = StatusStarted
+	}
+
+	teamOf := make(map[string]int)
+	for i, team := range m.teams {
+		t := Team{
+			Team:      i + 1,
+			PlayerIDs: make([]string, 0, len(team)),
+		}
+
+		levels, ratings := 0.0, 0.0
+		for _, p := range team {
+			teamOf[p.JoinID] = t.Team
+			t.PlayerIDs = append(t.PlayerIDs, p.PlayerID)
+			levels += float64(p.Level)
+			ratings += skill(p)
+		}
+		t.AverageLevel = average(levels, len(team))
+		t.AverageRating = average(ratings, len(team))
+
+		leaderBoard.Teams = append(leaderBoard.Teams, t)
 	}
 
 	for _, p := range m.players {
@@ -92,6 +208,12 @@ func (m *Match) GetLeaderboard() LeaderBoard {
 			Level:    p.Level,
 			Country:  p.Country,
 			Score:    0,
+			Team:     teamOf[p.JoinID],
+			PartyID:  p.PartyID,
+			JoinID:   p.JoinID,
+		}
+		if !m.startedAt.IsZero() {
+			playerInfo.QueueTime = m.startedAt.Sub(p.JoinedAt)
 		}
 		leaderBoard.Players = append(leaderBoard.Players, playerInfo)
 	}
@@ -0,0 +1,102 @@
+package match
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+var (
+	ErrPlayerNotInMatch = errors.New("player did not play in the match")
+	ErrScoresFinal      = errors.New("final scores of the match are already reported")
+)
+
+// Score is the score of a player in a match reported by a game server
+type Score struct {
+	PlayerID string
+	Score    int
+}
+
+// ReportScores applies the scores of the players to the leaderboard of the match. The scores
+// are added to the current scores of the players, unless they are final, then they replace them
+// and no more scores are accepted. Either all scores are applied or none of them.
+// The stored leaderboard is replaced by an updated copy, so the leaderboards returned before are not changed.
+// The scores are added to the totals of the players on the global leaderboards, and the final scores
+// count the match for all its players.
+func (s *Storage) ReportScores(matchID string, scores []Score, final bool) (*LeaderBoard, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	lb, ok := s.matches[matchID]
+	if !ok {
+		return nil, ErrMatchNotFound
+	}
+
+	if lb.Final {
+		return nil, ErrScoresFinal
+	}
+
+	index := make(map[string]int, len(lb.Players))
+	for i, p := range lb.Players {
+		index[p.PlayerID] = i
+	}
+
+	for _, score := range scores {
+		if _, ok := index[score.PlayerID]; !ok {
+			return nil, fmt.Errorf("player %s: %w", score.PlayerID, ErrPlayerNotInMatch)
+		}
+	}
+
+	updated := *lb
+	updated.Players = append([]PlayerInfo{}, lb.Players...)
+	updated.Final = final
+	for _, score := range scores {
+		p := &updated.Players[index[score.PlayerID]]
+		if final {
+			p.Score = score.Score
+		} else {
+			p.Score += score.Score
+		}
+	}
+
+	s.matches[matchID] = &updated
+
+	scored := make(map[string]struct{}, len(scores))
+	for _, score := range scores {
+		scored[score.PlayerID] = struct{}{}
+	}
+
+	for i, p := range updated.Players {
+		if _, ok := scored[p.PlayerID]; !ok && !final {
+			continue
+		}
+
+		delta := p.Score - lb.Players[i].Score
+		s.updateTotal(p, func(t *PlayerTotal) {
+			t.Score += delta
+			if final {
+				t.Matches++
+			}
+		})
+	}
+
+	return &updated, nil
+}
+
+// Ranked returns a copy of the leaderboard with the players sorted by their scores, the highest first.
+// The players with the same score share the rank, and the next rank is skipped for each of them, e.g. 1, 2, 2, 4.
+func (lb LeaderBoard) Ranked() LeaderBoard {
+	lb.Players = append([]PlayerInfo{}, lb.Players...)
+	sort.SliceStable(lb.Players, func(i, j int) bool {
+		return lb.Players[i].Score > lb.Players[j].Score
+	})
+
+	for i := range lb.Players {
+		lb.Players[i].Rank = i + 1
+		if i > 0 && lb.Players[i].Score == lb.Players[i-1].Score {
+			lb.Players[i].Rank = lb.Players[i-1].Rank
+		}
+	}
+
+	return lb
+}
@@ -0,0 +1,88 @@
+package match
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestReportScores(t *testing.T) {
+	storage := NewStorage()
+	lb := &LeaderBoard{
+		MatchID: "match1",
+		Players: []PlayerInfo{{PlayerID: "1"}, {PlayerID: "2"}, {PlayerID: "3"}},
+	}
+	storage.AddLeaderBoard(lb)
+
+	if _, err := storage.ReportScores("match2", []Score{{PlayerID: "1", Score: 10}}, false); err != ErrMatchNotFound {
+		t.Errorf("Expected ErrMatchNotFound, got %v", err)
+	}
+
+	// Nothing is applied if any of the players did not play in the match
+	_, err := storage.ReportScores("match1", []Score{{PlayerID: "1", Score: 10}, {PlayerID: "4", Score: 10}}, false)
+	if !errors.Is(err, ErrPlayerNotInMatch) {
+		t.Errorf("Expected ErrPlayerNotInMatch, got %v", err)
+	}
+
+	if storage.GetLeaderBoard("match1").Players[0].Score != 0 {
+		t.Errorf("Expected no scores to be applied")
+	}
+
+	// The incremental scores are added up
+	storage.ReportScores("match1", []Score{{PlayerID: "1", Score: 10}, {PlayerID: "2", Score: 5}}, false)
+	updated, err := storage.ReportScores("match1", []Score{{PlayerID: "2", Score: 7}}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if updated.Players[0].Score != 10 || updated.Players[1].Score != 12 || updated.Final {
+		t.Errorf("Expected the scores to be added up, got %v", updated.Players)
+	}
+
+	if lb.Players[1].Score != 0 {
+		t.Errorf("Expected the previous leaderboard not to change")
+	}
+
+	// The final scores replace the scores and close the leaderboard
+	updated, err = storage.ReportScores("match1", []Score{{PlayerID: "1", Score: 20}}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if updated.Players[0].Score != 20 || updated.Players[1].Score != 12 || !updated.Final {
+		t.Errorf("Expected the final scores to replace the scores, got %v", updated.Players)
+	}
+
+	if _, err := storage.ReportScores("match1", []Score{{PlayerID: "1", Score: 1}}, false); err != ErrScoresFinal {
+		t.Errorf("Expected ErrScoresFinal, got %v", err)
+	}
+}
+
+func TestLeaderBoard_Ranked(t *testing.T) {
+	lb := LeaderBoard{
+		MatchID: "match1",
+		Players: []PlayerInfo{
+			{PlayerID: "1", Score: 5},
+			{PlayerID: "2", Score: 20},
+			{PlayerID: "3", Score: 5},
+			{PlayerID: "4", Score: 1},
+		},
+	}
+
+	ranked := lb.Ranked()
+
+	expected := []PlayerInfo{
+		{PlayerID: "2", Score: 20, Rank: 1},
+		{PlayerID: "1", Score: 5, Rank: 2},
+		{PlayerID: "3", Score: 5, Rank: 2},
+		{PlayerID: "4", Score: 1, Rank: 4},
+	}
+	for i, p := range ranked.Players {
+		if p != expected[i] {
+			t.Errorf("Expected %v at %d, got %v", expected[i], i, p)
+		}
+	}
+
+	if lb.Players[0].PlayerID != "1" || lb.Players[0].Rank != 0 {
+		t.Errorf("Expected the leaderboard not to change")
+	}
+}
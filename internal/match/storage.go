@@ -1,21 +1,46 @@
 package match
 
-import "sync"
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+var (
+	ErrMatchNotFound = errors.New("match is not found")
+	ErrPlayerInMatch = errors.New("player is already in the match")
+)
 
 //go:generate mockgen -destination=./storage_mock.go -package=match github.com/TanyEm/match-maker/v2/internal/match Keeper
 type Keeper interface {
 	AddLeaderBoard(lb *LeaderBoard)
+	AddPlayers(matchID string, players []PlayerInfo) error
+	EndMatch(matchID string, status Status) (*LeaderBoard, error)
 	GetLeaderBoard(matchID string) *LeaderBoard
+	GetMatch(matchID string) (Details, error)
+	GlobalLeaderBoard(filter BoardFilter) ([]PlayerTotal, int)
+	PlayerMatches(playerID string, filter HistoryFilter) ([]HistoryEntry, int)
+	ReportScores(matchID string, scores []Score, final bool) (*LeaderBoard, error)
+	UpdateRatings(matchID string, ratings map[string]float64) error
 }
 
 type Storage struct {
 	matches map[string]*LeaderBoard
-	mu      sync.Mutex
+	// playerMatches are the IDs of the matches of the players in the order they started by the player IDs
+	playerMatches map[string][]string
+	// totals are the standings of the players across the matches by the player IDs
+	totals map[string]*PlayerTotal
+	// boards are the global leaderboards kept up to date as the scores and the ratings are reported
+	boards map[boardKey]*board
+	mu     sync.Mutex
 }
 
 func NewStorage() *Storage {
 	return &Storage{
-		matches: make(map[string]*LeaderBoard),
+		matches:       make(map[string]*LeaderBoard),
+		playerMatches: make(map[string][]string),
+		totals:        make(map[string]*PlayerTotal),
+		boards:        make(map[boardKey]*board),
 	}
 }
 
@@ -23,9 +48,58 @@ func (s *Storage) AddLeaderBoard(lb *LeaderBoard) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	if _, ok := s.matches[lb.MatchID]; !ok {
+		s.indexPlayers(lb.MatchID, lb.Players)
+	}
 	s.matches[lb.MatchID] = lb
 }
 
+// AddPlayers adds the players who joined the started match, e.g. by a backfill.
+// The players with a team are added to the team layout, the team averages are kept from the start of the match.
+// The stored leaderboard is replaced by an updated copy, so the leaderboards returned before are not changed.
+// A player who is already in the match, e.g. has left it and is backfilled again, is rejected with ErrPlayerInMatch,
+// as the scores of the match are reported by the player IDs. Either all players are added or none of them.
+func (s *Storage) AddPlayers(matchID string, players []PlayerInfo) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	lb, ok := s.matches[matchID]
+	if !ok {
+		return ErrMatchNotFound
+	}
+
+	inMatch := make(map[string]struct{}, len(lb.Players)+len(players))
+	for _, p := range lb.Players {
+		inMatch[p.PlayerID] = struct{}{}
+	}
+
+	for _, p := range players {
+		if _, ok := inMatch[p.PlayerID]; ok {
+			return fmt.Errorf("player %s: %w", p.PlayerID, ErrPlayerInMatch)
+		}
+		inMatch[p.PlayerID] = struct{}{}
+	}
+
+	updated := *lb
+	updated.Players = append(append([]PlayerInfo{}, lb.Players...), players...)
+	updated.Teams = make([]Team, len(lb.Teams))
+	for i, team := range lb.Teams {
+		updated.Teams[i] = team
+		updated.Teams[i].PlayerIDs = append([]string{}, team.PlayerIDs...)
+	}
+
+	for _, p := range players {
+		if p.Team > 0 && p.Team <= len(updated.Teams) {
+			updated.Teams[p.Team-1].PlayerIDs = append(updated.Teams[p.Team-1].PlayerIDs, p.PlayerID)
+		}
+	}
+
+	s.matches[matchID] = &updated
+	s.indexPlayers(matchID, players)
+
+	return nil
+}
+
 func (s *Storage) GetLeaderBoard(matchID string) *LeaderBoard {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -51,6 +51,35 @@ func (mr *MockKeeperMockRecorder) AddLeaderBoard(lb any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddLeaderBoard", reflect.TypeOf((*MockKeeper)(nil).AddLeaderBoard), lb)
 }
 
+// AddPlayers mocks base method.
+func (m *MockKeeper) AddPlayers(matchID string, players []PlayerInfo) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddPlayers", matchID, players)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddPlayers indicates an expected call of AddPlayers.
+func (mr *MockKeeperMockRecorder) AddPlayers(matchID, players any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddPlayers", reflect.TypeOf((*MockKeeper)(nil).AddPlayers), matchID, players)
+}
+
+// EndMatch mocks base method.
+func (m *MockKeeper) EndMatch(matchID string, status Status) (*LeaderBoard, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EndMatch", matchID, status)
+	ret0, _ := ret[0].(*LeaderBoard)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EndMatch indicates an expected call of EndMatch.
+func (mr *MockKeeperMockRecorder) EndMatch(matchID, status any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EndMatch", reflect.TypeOf((*MockKeeper)(nil).EndMatch), matchID, status)
+}
+
 // GetLeaderBoard mocks base method.
 func (m *MockKeeper) GetLeaderBoard(matchID string) *LeaderBoard {
 	m.ctrl.T.Helper()
@@ -64,3 +93,77 @@ func (mr *MockKeeperMockRecorder) GetLeaderBoard(matchID any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLeaderBoard", reflect.TypeOf((*MockKeeper)(nil).GetLeaderBoard), matchID)
 }
+
+// GetMatch mocks base method.
+func (m *MockKeeper) GetMatch(matchID string) (Details, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMatch", matchID)
+	ret0, _ := ret[0].(Details)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMatch indicates an expected call of GetMatch.
+func (mr *MockKeeperMockRecorder) GetMatch(matchID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMatch", reflect.TypeOf((*MockKeeper)(nil).GetMatch), matchID)
+}
+
+// GlobalLeaderBoard mocks base method.
+func (m *MockKeeper) GlobalLeaderBoard(filter BoardFilter) ([]PlayerTotal, int) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GlobalLeaderBoard", filter)
+	ret0, _ := ret[0].([]PlayerTotal)
+	ret1, _ := ret[1].(int)
+	return ret0, ret1
+}
+
+// GlobalLeaderBoard indicates an expected call of GlobalLeaderBoard.
+func (mr *MockKeeperMockRecorder) GlobalLeaderBoard(filter any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GlobalLeaderBoard", reflect.TypeOf((*MockKeeper)(nil).GlobalLeaderBoard), filter)
+}
+
+// PlayerMatches mocks base method.
+func (m *MockKeeper) PlayerMatches(playerID string, filter HistoryFilter) ([]HistoryEntry, int) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PlayerMatches", playerID, filter)
+	ret0, _ := ret[0].([]HistoryEntry)
+	ret1, _ := ret[1].(int)
+	return ret0, ret1
+}
+
+// PlayerMatches indicates an expected call of PlayerMatches.
+func (mr *MockKeeperMockRecorder) PlayerMatches(playerID, filter any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PlayerMatches", reflect.TypeOf((*MockKeeper)(nil).PlayerMatches), playerID, filter)
+}
+
+// ReportScores mocks base method.
+func (m *MockKeeper) ReportScores(matchID string, scores []Score, final bool) (*LeaderBoard, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReportScores", matchID, scores, final)
+	ret0, _ := ret[0].(*LeaderBoard)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReportScores indicates an expected call of ReportScores.
+func (mr *MockKeeperMockRecorder) ReportScores(matchID, scores, final any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReportScores", reflect.TypeOf((*MockKeeper)(nil).ReportScores), matchID, scores, final)
+}
+
+// UpdateRatings mocks base method.
+func (m *MockKeeper) UpdateRatings(matchID string, ratings map[string]float64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateRatings", matchID, ratings)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateRatings indicates an expected call of UpdateRatings.
+func (mr *MockKeeperMockRecorder) UpdateRatings(matchID, ratings any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateRatings", reflect.TypeOf((*MockKeeper)(nil).UpdateRatings), matchID, ratings)
+}
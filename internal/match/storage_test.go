@@ -1,6 +1,7 @@
 package match
 
 import (
+	"errors"
 	"sync"
 	"testing"
 )
@@ -48,3 +49,51 @@ func TestConcurrency(t *testing.T) {
 		t.Errorf("Expected to retrieve the correct leaderboard after concurrent writes")
 	}
 }
+
+func TestAddPlayers(t *testing.T) {
+	storage := NewStorage()
+	lb := &LeaderBoard{
+		MatchID: "match1",
+		Players: []PlayerInfo{{PlayerID: "1", Team: 1}, {PlayerID: "2", Team: 2}},
+		Teams:   []Team{{Team: 1, PlayerIDs: []string{"1"}}, {Team: 2, PlayerIDs: []string{"2"}}},
+	}
+	storage.AddLeaderBoard(lb)
+
+	if err := storage.AddPlayers("match2", []PlayerInfo{{PlayerID: "3"}}); err != ErrMatchNotFound {
+		t.Errorf("Expected ErrMatchNotFound, got %v", err)
+	}
+
+	if err := storage.AddPlayers("match1", []PlayerInfo{{PlayerID: "3", Team: 2}}); err != nil {
+		t.Fatal(err)
+	}
+
+	updated := storage.GetLeaderBoard("match1")
+	if len(updated.Players) != 3 || updated.Players[2].PlayerID != "3" {
+		t.Errorf("Expected the player to be added, got %v", updated.Players)
+	}
+
+	if len(updated.Teams[1].PlayerIDs) != 2 || updated.Teams[1].PlayerIDs[1] != "3" {
+		t.Errorf("Expected the player to be added to team 2, got %v", updated.Teams)
+	}
+
+	if len(lb.Players) != 2 || len(lb.Teams[1].PlayerIDs) != 1 {
+		t.Errorf("Expected the previous leaderboard not to change")
+	}
+
+	// A player is in the match only once, so the scores reported by the player IDs are not ambiguous
+	if err := storage.AddPlayers("match1", []PlayerInfo{{PlayerID: "4"}, {PlayerID: "1"}}); !errors.Is(err, ErrPlayerInMatch) {
+		t.Errorf("Expected ErrPlayerInMatch, got %v", err)
+	}
+
+	if err := storage.AddPlayers("match1", []PlayerInfo{{PlayerID: "4"}, {PlayerID: "4"}}); !errors.Is(err, ErrPlayerInMatch) {
+		t.Errorf("Expected ErrPlayerInMatch for the same player twice, got %v", err)
+	}
+
+	if updated := storage.GetLeaderBoard("match1"); len(updated.Players) != 3 {
+		t.Errorf("Expected no players to be added, got %v", updated.Players)
+	}
+
+	if entries, total := storage.PlayerMatches("1", HistoryFilter{}); total != 1 || len(entries) != 1 {
+		t.Errorf("Expected the match in the history of the player once, got %d", total)
+	}
+}
@@ -0,0 +1,311 @@
+package match
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+
+	"github.com/TanyEm/match-maker/v2/internal/player"
+	"github.com/TanyEm/match-maker/v2/internal/rating"
+)
+
+// ErrTeamsDoNotFit is returned when the parties can not be split into teams of the same size
+var ErrTeamsDoNotFit = errors.New("parties do not fit into the teams")
+
+const (
+	// maxExactSplits is the number of the ways to split the parties and players into the teams
+	// up to which the closest teams are searched for
+	maxExactSplits = 1 << 16
+	// maxSwapRounds limits the number of improvement rounds of the team balancing
+	maxSwapRounds = 100
+	// minImprovement ignores the swaps that make the teams closer only by a rounding error
+	minImprovement = 1e-9
+)
+
+// skill returns the rating of the player or the rating estimated from the level if the player has no rating
+func skill(p player.Player) float64 {
+	if p.Rating != 0 {
+		return p.Rating
+	}
+
+	return rating.FromLevel(p.Level)
+}
+
+// unit is a party or a single player that must be placed into the same team
+type unit struct {
+	players []player.Player
+	skill   float64
+}
+
+// units groups the players by their parties keeping the order of the players
+func units(players []player.Player) []*unit {
+	result := []*unit{}
+	parties := make(map[string]*unit)
+
+	for _, p := range players {
+		if p.PartyID != "" {
+			if u, ok := parties[p.PartyID]; ok {
+				u.players = append(u.players, p)
+				u.skill += skill(p)
+				continue
+			}
+		}
+
+		u := &unit{players: []player.Player{p}, skill: skill(p)}
+		if p.PartyID != "" {
+			parties[p.PartyID] = u
+		}
+		result = append(result, u)
+	}
+
+	return result
+}
+
+// BalanceTeams splits the players into count teams with sizes that differ by at most one player,
+// so that the average skills of the teams are as close as possible. Members of a party always
+// get into the same team, and it returns ErrTeamsDoNotFit if the parties can not be split into
+// teams of these sizes. The biggest parties are placed first. Up to maxExactSplits ways to split
+// the parties and players are searched through for the closest teams. Beyond that the first split
+// that fits is taken, placing every party into the weakest team with room for it, and then
+// parties and players of the same size are swapped between teams while it makes the teams closer.
+func BalanceTeams(players []player.Player, count int) ([][]player.Player, error) {
+	s := newSplitter(players, count, len(players))
+	s.exhaustive = math.Pow(float64(len(s.teams)), float64(len(s.units))) <= maxExactSplits
+	s.place(0)
+
+	if s.best == nil {
+		return nil, fmt.Errorf("%d players in %d teams: %w", len(players), len(s.teams), ErrTeamsDoNotFit)
+	}
+
+	teams := s.best
+	if !s.exhaustive {
+		sizes, totals := make([]int, len(teams)), make([]float64, len(teams))
+		for i, team := range teams {
+			for _, u := range team {
+				sizes[i] += len(u.players)
+				totals[i] += u.skill
+			}
+		}
+
+		for round := 0; round < maxSwapRounds; round++ {
+			if !swapBestPair(teams, sizes, totals) {
+				break
+			}
+		}
+	}
+
+	result := make([][]player.Player, len(teams))
+	for i, team := range teams {
+		result[i] = []player.Player{}
+		for _, u := range team {
+			result[i] = append(result[i], u.players...)
+		}
+	}
+
+	return result, nil
+}
+
+// FitTeams tells if the parties of the players fit into the count teams of a match of size players,
+// so that more players can still join the teams, see BalanceTeams
+func FitTeams(players []player.Player, count, size int) bool {
+	s := newSplitter(players, count, size)
+	s.place(0)
+
+	return s.best != nil
+}
+
+// splitter searches for the split of the parties and players into the teams of the capacities
+type splitter struct {
+	units    []*unit
+	capacity []int
+	teams    [][]*unit
+	sizes    []int
+	totals   []float64
+	// exhaustive tells to search through all the splits for the closest teams, the first split that fits is taken otherwise
+	exhaustive bool
+	// unfit are the rooms left in the teams the remaining units do not fit into, by the index of the next unit
+	unfit      map[string]bool
+	best       [][]*unit
+	bestSpread float64
+}
+
+// newSplitter creates the search for the split of the players into the count teams of a match
+// of size players, the biggest parties first
+func newSplitter(players []player.Player, count, size int) *splitter {
+	if count < 1 {
+		count = 1
+	}
+
+	sorted := units(players)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if len(sorted[i].players) != len(sorted[j].players) {
+			return len(sorted[i].players) > len(sorted[j].players)
+		}
+		return sorted[i].skill > sorted[j].skill
+	})
+
+	// The first size % count teams get one player more
+	capacity := make([]int, count)
+	for i := range capacity {
+		capacity[i] = size / count
+		if i < size%count {
+			capacity[i]++
+		}
+	}
+
+	return &splitter{
+		units:    sorted,
+		capacity: capacity,
+		teams:    make([][]*unit, count),
+		sizes:    make([]int, count),
+		totals:   make([]float64, count),
+		unfit:    make(map[string]bool),
+	}
+}
+
+// place places the units from the index on into the teams with room for them, the weakest team first.
+// It returns true when the search is over.
+func (s *splitter) place(index int) bool {
+	if index == len(s.units) {
+		if sp := spread(s.sizes, s.totals); s.best == nil || sp < s.bestSpread-minImprovement {
+			s.best, s.bestSpread = make([][]*unit, len(s.teams)), sp
+			for i, team := range s.teams {
+				s.best[i] = slices.Clone(team)
+			}
+		}
+
+		return !s.exhaustive
+	}
+
+	// Whether the remaining units fit depends only on the rooms left in the teams
+	key := s.roomKey(index)
+	if s.unfit[key] {
+		return false
+	}
+
+	u := s.units[index]
+	for _, i := range s.weakestFirst(len(u.players)) {
+		s.teams[i] = append(s.teams[i], u)
+		s.sizes[i] += len(u.players)
+		s.totals[i] += u.skill
+
+		done := s.place(index + 1)
+
+		s.teams[i] = s.teams[i][:len(s.teams[i])-1]
+		s.sizes[i] -= len(u.players)
+		s.totals[i] -= u.skill
+
+		if done {
+			return true
+		}
+	}
+
+	if s.best == nil {
+		s.unfit[key] = true
+	}
+
+	return false
+}
+
+// weakestFirst returns the teams with room for the number of players, the weakest team first.
+// Of the empty teams of the same capacity only the first one is returned, as they are interchangeable.
+func (s *splitter) weakestFirst(players int) []int {
+	result := []int{}
+	emptyCapacities := make(map[int]bool)
+	for i := range s.teams {
+		if s.sizes[i]+players > s.capacity[i] {
+			continue
+		}
+
+		if s.sizes[i] == 0 {
+			if emptyCapacities[s.capacity[i]] {
+				continue
+			}
+			emptyCapacities[s.capacity[i]] = true
+		}
+
+		result = append(result, i)
+	}
+
+	sort.SliceStable(result, func(a, b int) bool {
+		return average(s.totals[result[a]], s.sizes[result[a]]) < average(s.totals[result[b]], s.sizes[result[b]])
+	})
+
+	return result
+}
+
+// roomKey returns the key of the rooms left in the teams before the unit of the index is placed
+func (s *splitter) roomKey(index int) string {
+	rooms := make([]int, len(s.teams))
+	for i := range s.teams {
+		rooms[i] = s.capacity[i] - s.sizes[i]
+	}
+	sort.Ints(rooms)
+
+	return fmt.Sprint(index, rooms)
+}
+
+// swapBestPair swaps the pair of the same size parties or players of different teams that reduces
+// the spread of the team averages the most. It returns false if no swap reduces the spread.
+func swapBestPair(teams [][]*unit, sizes []int, totals []float64) bool {
+	best := spread(sizes, totals)
+	bestA, bestI, bestB, bestJ := -1, -1, -1, -1
+
+	for a := range teams {
+		for b := a + 1; b < len(teams); b++ {
+			for i, ua := range teams[a] {
+				for j, ub := range teams[b] {
+					if len(ua.players) != len(ub.players) {
+						continue
+					}
+
+					diff := ub.skill - ua.skill
+					totals[a], totals[b] = totals[a]+diff, totals[b]-diff
+
+					if s := spread(sizes, totals); s < best-minImprovement {
+						best = s
+						bestA, bestI, bestB, bestJ = a, i, b, j
+					}
+
+					totals[a], totals[b] = totals[a]-diff, totals[b]+diff
+				}
+			}
+		}
+	}
+
+	if bestA == -1 {
+		return false
+	}
+
+	diff := teams[bestB][bestJ].skill - teams[bestA][bestI].skill
+	totals[bestA], totals[bestB] = totals[bestA]+diff, totals[bestB]-diff
+	teams[bestA][bestI], teams[bestB][bestJ] = teams[bestB][bestJ], teams[bestA][bestI]
+
+	return true
+}
+
+// spread returns the difference between the strongest and the weakest team averages
+func spread(sizes []int, totals []float64) float64 {
+	lowest, highest := 0.0, 0.0
+	for i := range sizes {
+		avg := average(totals[i], sizes[i])
+		if i == 0 || avg < lowest {
+			lowest = avg
+		}
+		if i == 0 || avg > highest {
+			highest = avg
+		}
+	}
+
+	return highest - lowest
+}
+
+func average(total float64, count int) float64 {
+	if count == 0 {
+		return 0
+	}
+
+	return total / float64(count)
+}
@@ -0,0 +1,192 @@
+package match
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/TanyEm/match-maker/v2/internal/player"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBalanceTeams(t *testing.T) {
+	tests := []struct {
+		name          string
+		players       []player.Player
+		count         int
+		expectedTeams [][]string
+	}{
+		{"2 teams of 2", solos(10, 20, 30, 40), 2, [][]string{{"10", "40"}, {"20", "30"}}},
+		{"2 teams of 5", solos(3, 7, 12, 18, 25, 31, 44, 52, 66, 80), 2, [][]string{{"12", "18", "52", "7", "80"}, {"25", "3", "31", "44", "66"}}},
+		{"3 uneven teams", solos(5, 17, 23, 38, 41, 56, 62), 3, [][]string{{"17", "56"}, {"23", "38", "41"}, {"5", "62"}}},
+		{"single team", solos(10, 20, 30), 1, [][]string{{"10", "20", "30"}}},
+		{"party of 3 and 3 players", parties([]int{1, 1, 1, 3}), 2, [][]string{{"0-0", "1-0", "2-0"}, {"3-0", "3-1", "3-2"}}},
+		{"parties the greedy split does not fit", parties([]int{3, 3, 2, 2, 2}), 2, [][]string{{"0-0", "0-1", "0-2", "1-0", "1-1", "1-2"}, {"2-0", "2-1", "3-0", "3-1", "4-0", "4-1"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			teams, err := BalanceTeams(tt.players, tt.count)
+			assert.NoError(t, err)
+
+			// The teams are compared regardless of their order and the order of their players
+			playerIDs := [][]string{}
+			for _, team := range teams {
+				ids := []string{}
+				for _, p := range team {
+					ids = append(ids, p.PlayerID)
+				}
+				slices.Sort(ids)
+				playerIDs = append(playerIDs, ids)
+			}
+			slices.SortFunc(playerIDs, slices.Compare)
+
+			assert.Equal(t, tt.expectedTeams, playerIDs)
+		})
+	}
+}
+
+func TestBalanceTeams_KeepsPartiesTogether(t *testing.T) {
+	players := []player.Player{
+		{PlayerID: "1", JoinID: "join1", Level: 90, PartyID: "party1"},
+		{PlayerID: "2", JoinID: "join2", Level: 80, PartyID: "party1"},
+		{PlayerID: "3", JoinID: "join3", Level: 10},
+		{PlayerID: "4", JoinID: "join4", Level: 20},
+		{PlayerID: "5", JoinID: "join5", Level: 85},
+		{PlayerID: "6", JoinID: "join6", Level: 15},
+		{PlayerID: "7", JoinID: "join7", Level: 50, PartyID: "party2"},
+		{PlayerID: "8", JoinID: "join8", Level: 40, PartyID: "party2"},
+	}
+
+	teams, err := BalanceTeams(players, 2)
+	assert.NoError(t, err)
+
+	teamOf := map[string]int{}
+	for i, team := range teams {
+		assert.Len(t, team, 4)
+		for _, p := range team {
+			teamOf[p.PlayerID] = i
+		}
+	}
+
+	assert.Equal(t, teamOf["1"], teamOf["2"], "Expected party1 in the same team")
+	assert.Equal(t, teamOf["7"], teamOf["8"], "Expected party2 in the same team")
+	assert.NotEqual(t, teamOf["1"], teamOf["7"], "Expected the parties in different teams to balance the teams")
+}
+
+func TestBalanceTeams_PartiesFitExactly(t *testing.T) {
+	tests := []struct {
+		name          string
+		parties       []int
+		count         int
+		expectedSizes []int
+	}{
+		{"parties of 3, 3, 2, 2 and 2", []int{2, 3, 2, 3, 2}, 2, []int{6, 6}},
+		{"many parties and players", []int{4, 4, 3, 3, 3, 3, 2, 2, 1, 1, 1, 1, 1, 1, 1, 1, 1}, 3, []int{11, 11, 11}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			players := parties(tt.parties)
+
+			teams, err := BalanceTeams(players, tt.count)
+			assert.NoError(t, err)
+
+			sizes := []int{}
+			teamsOf := map[string]map[int]bool{}
+			for i, team := range teams {
+				sizes = append(sizes, len(team))
+				for _, p := range team {
+					if teamsOf[p.PartyID] == nil {
+						teamsOf[p.PartyID] = map[int]bool{}
+					}
+					teamsOf[p.PartyID][i] = true
+				}
+			}
+
+			assert.Equal(t, tt.expectedSizes, sizes)
+			for partyID, in := range teamsOf {
+				assert.Len(t, in, 1, "Expected party %s in one team", partyID)
+			}
+		})
+	}
+}
+
+func TestBalanceTeams_PartiesDoNotFit(t *testing.T) {
+	tests := []struct {
+		name    string
+		parties []int
+		count   int
+	}{
+		{"3 parties of 2 in 2 teams of 3", []int{2, 2, 2}, 2},
+		{"17 parties of 2 in 3 teams", slices.Repeat([]int{2}, 17), 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			players := parties(tt.parties)
+
+			_, err := BalanceTeams(players, tt.count)
+			assert.ErrorIs(t, err, ErrTeamsDoNotFit)
+			assert.False(t, FitTeams(players, tt.count, len(players)))
+		})
+	}
+}
+
+// solos returns the players of the levels without parties, the level is the player ID
+func solos(levels ...int) []player.Player {
+	players := []player.Player{}
+	for _, level := range levels {
+		players = append(players, player.Player{PlayerID: fmt.Sprint(level), JoinID: fmt.Sprint(level), Level: level})
+	}
+
+	return players
+}
+
+// parties returns the players of the parties of the sizes, the levels of the parties go up by 10
+func parties(sizes []int) []player.Player {
+	players := []player.Player{}
+	for i, size := range sizes {
+		for j := 0; j < size; j++ {
+			id := fmt.Sprintf("%d-%d", i, j)
+			players = append(players, player.Player{PlayerID: id, JoinID: id, Level: 10 * (i + 1), PartyID: fmt.Sprint(i)})
+		}
+	}
+
+	return players
+}
+
+func TestFitTeams(t *testing.T) {
+	party := []player.Player{
+		{PlayerID: "1", JoinID: "join1", Level: 10, PartyID: "party"},
+		{PlayerID: "2", JoinID: "join2", Level: 10, PartyID: "party"},
+		{PlayerID: "3", JoinID: "join3", Level: 10, PartyID: "party"},
+	}
+	solo := player.Player{PlayerID: "4", JoinID: "join4", Level: 10}
+
+	// The party of 3 and a player do not fit into 2 teams of 2, but they fit into the teams of 3 of a match of 6
+	assert.False(t, FitTeams(append(slices.Clone(party), solo), 2, 4))
+	assert.True(t, FitTeams(append(slices.Clone(party), solo), 2, 6))
+	assert.False(t, FitTeams(append(slices.Clone(party), solo), 3, 6))
+}
+
+func TestMatch_GetLeaderboardWithTeams(t *testing.T) {
+	m := NewMatch("FIN", 5)
+	m.AddPlayer(player.Player{PlayerID: "1", JoinID: "join1", Level: 4, Country: "FIN"})
+	m.AddPlayer(player.Player{PlayerID: "2", JoinID: "join2", Level: 5, Country: "FIN"})
+	m.AddPlayer(player.Player{PlayerID: "3", JoinID: "join3", Level: 5, Country: "FIN"})
+	m.AddPlayer(player.Player{PlayerID: "4", JoinID: "join4", Level: 6, Country: "FIN"})
+
+	m.AssignTeams(2)
+	leaderBoard := m.GetLeaderboard()
+
+	assert.Len(t, leaderBoard.Teams, 2)
+	for _, team := range leaderBoard.Teams {
+		assert.Len(t, team.PlayerIDs, 2)
+		assert.Equal(t, 5.0, team.AverageLevel)
+	}
+
+	for _, p := range leaderBoard.Players {
+		assert.Contains(t, []int{1, 2}, p.Team)
+	}
+}
@@ -1,5 +1,7 @@
 package player
 
+import "time"
+
 type Player struct {
 	PlayerID      string
 	Level         int
@@ -7,4 +9,13 @@ type Player struct {
 	JoinID        string
 	MatchID       string
 	LeaderBoardID string
+	JoinedAt      time.Time
+	// Deadline is the time the player's match is started or the player is notified there is no match
+	Deadline time.Time
+	// Rating is the player's skill rating, 0 if the player has no rating yet
+	Rating float64
+	// PartyID is shared by the players who queue together, empty for a single player
+	PartyID string
+	// Queue is the name of the queue (game mode) the player joined, empty for the default queue
+	Queue string
 }
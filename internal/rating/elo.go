@@ -0,0 +1,41 @@
+package rating
+
+import "math"
+
+// Elo is the Elo rating system. A match of several players is rated as games
+// of each player against every other player of the match.
+type Elo struct {
+	// K is the largest rating change of a match
+	K float64
+}
+
+func NewElo(k float64) *Elo {
+	return &Elo{K: k}
+}
+
+func (e *Elo) Initial(playerID string, level int) Rating {
+	return Rating{PlayerID: playerID, Rating: FromLevel(level)}
+}
+
+func (e *Elo) Update(ratings []Rating, places []int) []Rating {
+	updated := make([]Rating, len(ratings))
+	opponents := float64(len(ratings) - 1)
+
+	for i, r := range ratings {
+		expected, actual := 0.0, 0.0
+		for j, opponent := range ratings {
+			if i == j {
+				continue
+			}
+
+			expected += 1 / (1 + math.Pow(10, (opponent.Rating-r.Rating)/400))
+			actual += score(places[i], places[j])
+		}
+
+		updated[i] = r
+		updated[i].Rating = r.Rating + e.K/opponents*(actual-expected)
+		updated[i].Matches++
+	}
+
+	return updated
+}
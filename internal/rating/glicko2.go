@@ -0,0 +1,110 @@
+package rating
+
+import "math"
+
+const (
+	// glicko2Scale converts the Glicko rating scale to the Glicko-2 one
+	glicko2Scale = 173.7178
+	// glicko2Epsilon is the convergence tolerance of the volatility iteration
+	glicko2Epsilon = 0.000001
+
+	DefaultDeviation  = 350
+	DefaultVolatility = 0.06
+)
+
+// Glicko2 is the Glicko-2 rating system, see http://www.glicko.net/glicko/glicko2.pdf.
+// Every match is a rating period, and a match of several players is rated as games
+// of each player against every other player of the match.
+type Glicko2 struct {
+	// Tau constrains the change in volatility over time, reasonable values are between 0.3 and 1.2
+	Tau float64
+}
+
+func NewGlicko2(tau float64) *Glicko2 {
+	return &Glicko2{Tau: tau}
+}
+
+func (g *Glicko2) Initial(playerID string, level int) Rating {
+	return Rating{
+		PlayerID:   playerID,
+		Rating:     FromLevel(level),
+		Deviation:  DefaultDeviation,
+		Volatility: DefaultVolatility,
+	}
+}
+
+func (g *Glicko2) Update(ratings []Rating, places []int) []Rating {
+	updated := make([]Rating, len(ratings))
+
+	for i, r := range ratings {
+		mu := (r.Rating - DefaultRating) / glicko2Scale
+		phi := r.Deviation / glicko2Scale
+
+		// Estimated variance and improvement of the rating from the games against the opponents
+		variance, improvement := 0.0, 0.0
+		for j, opponent := range ratings {
+			if i == j {
+				continue
+			}
+
+			muJ := (opponent.Rating - DefaultRating) / glicko2Scale
+			gPhiJ := 1 / math.Sqrt(1+3*math.Pow(opponent.Deviation/glicko2Scale, 2)/(math.Pi*math.Pi))
+			expected := 1 / (1 + math.Exp(-gPhiJ*(mu-muJ)))
+
+			variance += gPhiJ * gPhiJ * expected * (1 - expected)
+			improvement += gPhiJ * (score(places[i], places[j]) - expected)
+		}
+		variance = 1 / variance
+		delta := variance * improvement
+
+		sigma := g.volatility(phi, r.Volatility, variance, delta)
+
+		phiStar := math.Sqrt(phi*phi + sigma*sigma)
+		newPhi := 1 / math.Sqrt(1/(phiStar*phiStar)+1/variance)
+		newMu := mu + newPhi*newPhi*improvement
+
+		updated[i] = r
+		updated[i].Rating = newMu*glicko2Scale + DefaultRating
+		updated[i].Deviation = newPhi * glicko2Scale
+		updated[i].Volatility = sigma
+		updated[i].Matches++
+	}
+
+	return updated
+}
+
+// volatility finds the new volatility with the Illinois algorithm
+func (g *Glicko2) volatility(phi, sigma, variance, delta float64) float64 {
+	a := math.Log(sigma * sigma)
+	f := func(x float64) float64 {
+		ex := math.Exp(x)
+		d := phi*phi + variance + ex
+		return ex*(delta*delta-d)/(2*d*d) - (x-a)/(g.Tau*g.Tau)
+	}
+
+	upper := a
+	var lower float64
+	if delta*delta > phi*phi+variance {
+		lower = math.Log(delta*delta - phi*phi - variance)
+	} else {
+		k := 1.0
+		for f(a-k*g.Tau) < 0 {
+			k++
+		}
+		lower = a - k*g.Tau
+	}
+
+	fUpper, fLower := f(upper), f(lower)
+	for math.Abs(lower-upper) > glicko2Epsilon {
+		c := upper + (upper-lower)*fUpper/(fLower-fUpper)
+		fC := f(c)
+		if fC*fLower <= 0 {
+			upper, fUpper = lower, fLower
+		} else {
+			fUpper /= 2
+		}
+		lower, fLower = c, fC
+	}
+
+	return math.Exp(upper / 2)
+}
@@ -0,0 +1,122 @@
+package rating
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// DefaultRating is the rating of an average player
+const DefaultRating = 1500
+
+var (
+	ErrAlreadyReported  = errors.New("result of the match is already reported")
+	ErrNotEnoughPlayers = errors.New("result of the match must have at least 2 players")
+)
+
+type Rating struct {
+	PlayerID   string  `json:"player_id"`
+	Rating     float64 `json:"rating"`
+	Deviation  float64 `json:"deviation"`
+	Volatility float64 `json:"volatility"`
+	Matches    int     `json:"matches"`
+}
+
+// Standing is the place of a player in a finished match, the winner has place 1.
+// Players with the same place are tied.
+type Standing struct {
+	PlayerID string
+	Level    int
+	Place    int
+}
+
+// System calculates the ratings of players
+type System interface {
+	// Initial returns the rating of a player who has not played yet
+	Initial(playerID string, level int) Rating
+	// Update returns the new ratings of the players from their places in a match
+	Update(ratings []Rating, places []int) []Rating
+}
+
+// FromLevel converts the 1-99 level to a rating. It is a coarse estimation for the players
+// who have no rating yet, level 50 is the default rating and each level is worth 10 points.
+func FromLevel(level int) float64 {
+	return DefaultRating + float64(level-50)*10
+}
+
+//go:generate mockgen -destination=./rating_mock.go -package=rating github.com/TanyEm/match-maker/v2/internal/rating Keeper
+type Keeper interface {
+	GetRating(playerID string) (Rating, bool)
+	ReportResult(matchID string, standings []Standing) ([]Rating, error)
+}
+
+type Storage struct {
+	system   System
+	ratings  map[string]Rating
+	reported map[string]struct{}
+	mu       sync.Mutex
+}
+
+func NewStorage(system System) *Storage {
+	return &Storage{
+		system:   system,
+		ratings:  make(map[string]Rating),
+		reported: make(map[string]struct{}),
+	}
+}
+
+func (s *Storage) GetRating(playerID string) (Rating, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	r, ok := s.ratings[playerID]
+	return r, ok
+}
+
+// ReportResult updates the ratings of the players of the match from their standings.
+// A result of a match can be reported only once.
+func (s *Storage) ReportResult(matchID string, standings []Standing) ([]Rating, error) {
+	if len(standings) < 2 {
+		return nil, ErrNotEnoughPlayers
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.reported[matchID]; ok {
+		return nil, fmt.Errorf("match %s: %w", matchID, ErrAlreadyReported)
+	}
+
+	ratings := make([]Rating, 0, len(standings))
+	places := make([]int, 0, len(standings))
+	for _, standing := range standings {
+		r, ok := s.ratings[standing.PlayerID]
+		if !ok {
+			r = s.system.Initial(standing.PlayerID, standing.Level)
+		}
+
+		ratings = append(ratings, r)
+		places = append(places, standing.Place)
+	}
+
+	updated := s.system.Update(ratings, places)
+	for _, r := range updated {
+		s.ratings[r.PlayerID] = r
+	}
+	s.reported[matchID] = struct{}{}
+
+	return updated, nil
+}
+
+// score returns the score of the player with place a against the player with place b:
+// 1 for a win, 0.5 for a tie and 0 for a loss
+func score(a, b int) float64 {
+	switch {
+	case a < b:
+		return 1
+	case a == b:
+		return 0.5
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,82 @@
+package rating
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFromLevel(t *testing.T) {
+	assert.Equal(t, 1010.0, FromLevel(1))
+	assert.Equal(t, 1500.0, FromLevel(50))
+	assert.Equal(t, 1990.0, FromLevel(99))
+}
+
+func TestElo_Update(t *testing.T) {
+	elo := NewElo(32)
+
+	updated := elo.Update(
+		[]Rating{{PlayerID: "1", Rating: 1500}, {PlayerID: "2", Rating: 1500}},
+		[]int{1, 2},
+	)
+
+	assert.InDelta(t, 1516, updated[0].Rating, 0.001)
+	assert.InDelta(t, 1484, updated[1].Rating, 0.001)
+	assert.Equal(t, 1, updated[0].Matches)
+
+	tied := elo.Update(
+		[]Rating{{PlayerID: "1", Rating: 1500}, {PlayerID: "2", Rating: 1500}},
+		[]int{1, 1},
+	)
+
+	assert.InDelta(t, 1500, tied[0].Rating, 0.001)
+	assert.InDelta(t, 1500, tied[1].Rating, 0.001)
+}
+
+func TestGlicko2_Update(t *testing.T) {
+	// The example from http://www.glicko.net/glicko/glicko2.pdf: the player beats the 1400 player
+	// and loses to the 1550 and 1700 players
+	g := NewGlicko2(0.5)
+
+	updated := g.Update(
+		[]Rating{
+			{PlayerID: "1", Rating: 1500, Deviation: 200, Volatility: 0.06},
+			{PlayerID: "2", Rating: 1400, Deviation: 30, Volatility: 0.06},
+			{PlayerID: "3", Rating: 1550, Deviation: 100, Volatility: 0.06},
+			{PlayerID: "4", Rating: 1700, Deviation: 300, Volatility: 0.06},
+		},
+		[]int{3, 4, 1, 1},
+	)
+
+	assert.InDelta(t, 1464.06, updated[0].Rating, 0.01)
+	assert.InDelta(t, 151.52, updated[0].Deviation, 0.01)
+	assert.InDelta(t, 0.05999, updated[0].Volatility, 0.00001)
+}
+
+func TestStorage_ReportResult(t *testing.T) {
+	s := NewStorage(NewElo(32))
+
+	_, ok := s.GetRating("1")
+	assert.False(t, ok)
+
+	updated, err := s.ReportResult("match1", []Standing{
+		{PlayerID: "1", Level: 50, Place: 1},
+		{PlayerID: "2", Level: 50, Place: 2},
+	})
+	assert.NoError(t, err)
+	assert.Len(t, updated, 2)
+
+	r, ok := s.GetRating("1")
+	assert.True(t, ok)
+	assert.InDelta(t, 1516, r.Rating, 0.001)
+
+	_, err = s.ReportResult("match1", []Standing{
+		{PlayerID: "1", Level: 50, Place: 1},
+		{PlayerID: "2", Level: 50, Place: 2},
+	})
+	assert.True(t, errors.Is(err, ErrAlreadyReported))
+
+	_, err = s.ReportResult("match2", []Standing{{PlayerID: "1", Level: 50, Place: 1}})
+	assert.True(t, errors.Is(err, ErrNotEnoughPlayers))
+}
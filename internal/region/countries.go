@@ -0,0 +1,79 @@
+package region
+
+// subRegions is a list of ISO 3166-1 alpha-3 country codes grouped by the UN M49 sub-regions
+var subRegions = map[string][]string{
+	"Northern Africa": {"DZA", "EGY", "LBY", "MAR", "SDN", "TUN", "ESH"},
+	"Eastern Africa": {
+		"BDI", "COM", "DJI", "ERI", "ETH", "KEN", "MDG", "MWI", "MUS", "MYT", "MOZ", "REU",
+		"RWA", "SYC", "SOM", "SSD", "UGA", "TZA", "ZMB", "ZWE", "IOT", "ATF",
+	},
+	"Middle Africa":   {"AGO", "CMR", "CAF", "TCD", "COG", "COD", "GNQ", "GAB", "STP"},
+	"Southern Africa": {"BWA", "SWZ", "LSO", "NAM", "ZAF"},
+	"Western Africa": {
+		"BEN", "BFA", "CPV", "CIV", "GMB", "GHA", "GIN", "GNB", "LBR", "MLI", "MRT", "NER",
+		"NGA", "SHN", "SEN", "SLE", "TGO",
+	},
+	"Caribbean": {
+		"AIA", "ATG", "ABW", "BHS", "BRB", "BES", "VGB", "CYM", "CUB", "CUW", "DMA", "DOM",
+		"GRD", "GLP", "HTI", "JAM", "MTQ", "MSR", "PRI", "BLM", "KNA", "LCA", "MAF", "VCT",
+		"SXM", "TTO", "TCA", "VIR",
+	},
+	"Central America": {"BLZ", "CRI", "SLV", "GTM", "HND", "MEX", "NIC", "PAN"},
+	"South America": {
+		"ARG", "BOL", "BVT", "BRA", "CHL", "COL", "ECU", "FLK", "GUF", "GUY", "PRY", "PER",
+		"SGS", "SUR", "URY", "VEN",
+	},
+	"Northern America": {"BMU", "CAN", "GRL", "SPM", "USA"},
+	"Central Asia":     {"KAZ", "KGZ", "TJK", "TKM", "UZB"},
+	"Eastern Asia":     {"CHN", "HKG", "MAC", "PRK", "JPN", "MNG", "KOR", "TWN"},
+	"South-eastern Asia": {
+		"BRN", "KHM", "IDN", "LAO", "MYS", "MMR", "PHL", "SGP", "THA", "TLS", "VNM",
+	},
+	"Southern Asia": {"AFG", "BGD", "BTN", "IND", "IRN", "MDV", "NPL", "PAK", "LKA"},
+	"Western Asia": {
+		"ARM", "AZE", "BHR", "CYP", "GEO", "IRQ", "ISR", "JOR", "KWT", "LBN", "OMN", "QAT",
+		"SAU", "PSE", "SYR", "TUR", "ARE", "YEM",
+	},
+	"Eastern Europe": {"BLR", "BGR", "CZE", "HUN", "POL", "MDA", "ROU", "RUS", "SVK", "UKR"},
+	"Northern Europe": {
+		"ALA", "DNK", "EST", "FRO", "FIN", "GGY", "ISL", "IRL", "IMN", "JEY", "LVA", "LTU",
+		"NOR", "SJM", "SWE", "GBR",
+	},
+	"Southern Europe": {
+		"ALB", "AND", "BIH", "HRV", "GIB", "GRC", "VAT", "ITA", "MLT", "MNE", "MKD", "PRT",
+		"SMR", "SRB", "SVN", "ESP",
+	},
+	"Western Europe":            {"AUT", "BEL", "FRA", "DEU", "LIE", "LUX", "MCO", "NLD", "CHE"},
+	"Australia and New Zealand": {"AUS", "CXR", "CCK", "HMD", "NZL", "NFK"},
+	"Melanesia":                 {"FJI", "NCL", "PNG", "SLB", "VUT"},
+	"Micronesia":                {"GUM", "KIR", "MHL", "FSM", "NRU", "MNP", "PLW", "UMI"},
+	"Polynesia":                 {"ASM", "COK", "PYF", "NIU", "PCN", "WSM", "TKL", "TON", "TUV", "WLF"},
+	"Antarctica":                {"ATA"},
+}
+
+// continents maps the UN M49 sub-regions to their regions
+var continents = map[string]string{
+	"Northern Africa":           "Africa",
+	"Eastern Africa":            "Africa",
+	"Middle Africa":             "Africa",
+	"Southern Africa":           "Africa",
+	"Western Africa":            "Africa",
+	"Caribbean":                 "Americas",
+	"Central America":           "Americas",
+	"South America":             "Americas",
+	"Northern America":          "Americas",
+	"Central Asia":              "Asia",
+	"Eastern Asia":              "Asia",
+	"South-eastern Asia":        "Asia",
+	"Southern Asia":             "Asia",
+	"Western Asia":              "Asia",
+	"Eastern Europe":            "Europe",
+	"Northern Europe":           "Europe",
+	"Southern Europe":           "Europe",
+	"Western Europe":            "Europe",
+	"Australia and New Zealand": "Oceania",
+	"Melanesia":                 "Oceania",
+	"Micronesia":                "Oceania",
+	"Polynesia":                 "Oceania",
+	"Antarctica":                "Antarctica",
+}
@@ -0,0 +1,142 @@
+package region
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Region is the place of a country in the UN M49 geographic regions
+type Region struct {
+	Continent string
+	SubRegion string
+}
+
+// Proximity tells how close two countries are, from the same country to anywhere in the world
+type Proximity int
+
+const (
+	SameCountry Proximity = iota
+	Neighbour
+	SameSubRegion
+	SameContinent
+	Global
+)
+
+var proximityNames = []string{"country", "neighbour", "subregion", "continent", "global"}
+
+func (p Proximity) String() string {
+	if p < SameCountry || p > Global {
+		return fmt.Sprintf("Proximity(%d)", int(p))
+	}
+
+	return proximityNames[p]
+}
+
+// UnmarshalText parses one of "country", "neighbour", "subregion", "continent" or "global"
+func (p *Proximity) UnmarshalText(text []byte) error {
+	for i, name := range proximityNames {
+		if string(text) == name {
+			*p = Proximity(i)
+			return nil
+		}
+	}
+
+	return fmt.Errorf("unknown proximity %q, expected one of %s", text, strings.Join(proximityNames, ", "))
+}
+
+// Neighbours is a configurable set of countries that are considered the nearest to a country
+type Neighbours map[string][]string
+
+// UnmarshalText parses the neighbours in the "FIN:SWE|NOR|EST,USA:CAN|MEX" format.
+// The neighbourhood is symmetric, so "FIN:SWE" makes FIN a neighbour of SWE as well.
+func (n *Neighbours) UnmarshalText(text []byte) error {
+	neighbours := Neighbours{}
+
+	for _, entry := range strings.Split(string(text), ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		country, list, found := strings.Cut(entry, ":")
+		if !found || country == "" || list == "" {
+			return fmt.Errorf("neighbours entry %q is not in the <country>:<country>|<country> format", entry)
+		}
+
+		if _, ok := Of(country); !ok {
+			return fmt.Errorf("neighbours entry %q has unknown country %q", entry, country)
+		}
+
+		for _, neighbour := range strings.Split(list, "|") {
+			if _, ok := Of(neighbour); !ok {
+				return fmt.Errorf("neighbours entry %q has unknown country %q", entry, neighbour)
+			}
+
+			neighbours[country] = append(neighbours[country], neighbour)
+			neighbours[neighbour] = append(neighbours[neighbour], country)
+		}
+	}
+
+	*n = neighbours
+
+	return nil
+}
+
+var countryRegions = func() map[string]Region {
+	regions := make(map[string]Region)
+	for subRegion, countries := range subRegions {
+		for _, country := range countries {
+			regions[country] = Region{Continent: continents[subRegion], SubRegion: subRegion}
+		}
+	}
+
+	return regions
+}()
+
+// Of returns the region of the ISO 3166-1 alpha-3 country code
+func Of(country string) (Region, bool) {
+	r, ok := countryRegions[country]
+	return r, ok
+}
+
+// Map resolves the proximity of countries using the UN M49 regions and the configured neighbours
+type Map struct {
+	neighbours Neighbours
+}
+
+func NewMap(neighbours Neighbours) *Map {
+	if neighbours == nil {
+		neighbours = Neighbours{}
+	}
+
+	return &Map{neighbours: neighbours}
+}
+
+// Proximity returns the closest proximity both countries share
+func (m *Map) Proximity(a, b string) Proximity {
+	if a == b {
+		return SameCountry
+	}
+
+	for _, neighbour := range m.neighbours[a] {
+		if neighbour == b {
+			return Neighbour
+		}
+	}
+
+	regionA, okA := Of(a)
+	regionB, okB := Of(b)
+	if !okA || !okB {
+		return Global
+	}
+
+	if regionA.SubRegion == regionB.SubRegion {
+		return SameSubRegion
+	}
+
+	if regionA.Continent == regionB.Continent {
+		return SameContinent
+	}
+
+	return Global
+}
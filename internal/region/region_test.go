@@ -0,0 +1,62 @@
+package region
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOf(t *testing.T) {
+	r, ok := Of("FIN")
+	assert.True(t, ok)
+	assert.Equal(t, Region{Continent: "Europe", SubRegion: "Northern Europe"}, r)
+
+	_, ok = Of("XXX")
+	assert.False(t, ok)
+}
+
+func TestMap_Proximity(t *testing.T) {
+	m := NewMap(Neighbours{"FIN": {"EST"}, "EST": {"FIN"}})
+
+	tests := []struct {
+		name     string
+		a        string
+		b        string
+		expected Proximity
+	}{
+		{"same country", "FIN", "FIN", SameCountry},
+		{"configured neighbour", "FIN", "EST", Neighbour},
+		{"same sub-region", "FIN", "SWE", SameSubRegion},
+		{"same continent", "FIN", "DEU", SameContinent},
+		{"different continents", "FIN", "USA", Global},
+		{"unknown country", "FIN", "XXX", Global},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, m.Proximity(tt.a, tt.b))
+		})
+	}
+}
+
+func TestProximity_UnmarshalText(t *testing.T) {
+	var p Proximity
+
+	assert.NoError(t, p.UnmarshalText([]byte("continent")))
+	assert.Equal(t, SameContinent, p)
+	assert.Equal(t, "continent", p.String())
+
+	assert.Error(t, p.UnmarshalText([]byte("planet")))
+}
+
+func TestNeighbours_UnmarshalText(t *testing.T) {
+	var n Neighbours
+
+	assert.NoError(t, n.UnmarshalText([]byte("FIN:SWE|EST,USA:CAN")))
+	assert.ElementsMatch(t, []string{"SWE", "EST"}, n["FIN"])
+	assert.Equal(t, []string{"FIN"}, n["EST"])
+	assert.Equal(t, []string{"USA"}, n["CAN"])
+
+	assert.Error(t, n.UnmarshalText([]byte("FIN")))
+	assert.Error(t, n.UnmarshalText([]byte("FIN:XXX")))
+}
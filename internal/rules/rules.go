@@ -0,0 +1,149 @@
+package rules
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/TanyEm/match-maker/v2/internal/lobby"
+	"github.com/TanyEm/match-maker/v2/internal/region"
+	"gopkg.in/yaml.v3"
+)
+
+// Rules describe how the players of a queue are matched. They are read from a YAML
+// or JSON file, so the matchmaking can be tuned without a code change, e.g.
+//
+//	attribute: level
+//	bucket_width: 1
+//	floor: 1
+//	constraints:
+//	  band: 1
+//	preferences:
+//	  order: closest
+//	  region_fallback: neighbour
+//	widening: ["10s:3", "30s:any"]
+type Rules struct {
+	// Attribute is the property of the players they are matched by, "level" or "rating"
+	Attribute lobby.Attribute `yaml:"attribute"`
+	// BucketWidth is the range of the attribute values of a bucket, the players of a bucket are the closest ones
+	BucketWidth float64 `yaml:"bucket_width"`
+	// Floor is the lowest value of the attribute. The players at the floor are bucketed
+	// above it, so they compete with as many buckets as other players. No floor if it is nil.
+	Floor       *float64    `yaml:"floor"`
+	Constraints Constraints `yaml:"constraints"`
+	Preferences Preferences `yaml:"preferences"`
+	// Widening is the curve the band of a waiting player expands with, the steps are in the "<duration>:<band>" format
+	Widening []lobby.WideningStep `yaml:"widening"`
+}
+
+// Constraints are the rules a match never breaks
+type Constraints struct {
+	// Band is the largest bucket difference between the players of a match until it is widened
+	Band int `yaml:"band"`
+}
+
+// Preferences are the rules a match keeps while there are enough players
+type Preferences struct {
+	// Order is the order the buckets of a player are tried in, "ascending" or "closest"
+	Order lobby.CandidateOrder `yaml:"order"`
+	// RegionFallback is how far from their country the players of too thin matches are merged,
+	// the players are matched within their country otherwise
+	RegionFallback region.Proximity `yaml:"region_fallback"`
+}
+
+// Default returns the rules of lobby.NewDefaultStrategy: the levels are matched with +1/-1 level
+// within the same country, and a player with level 1 competes with levels 1, 2 and 3
+func Default() Rules {
+	floor := 1.0
+
+	return Rules{
+		Attribute:   lobby.AttributeLevel,
+		BucketWidth: 1,
+		Floor:       &floor,
+		Constraints: Constraints{Band: 1},
+		Preferences: Preferences{Order: lobby.OrderAscending, RegionFallback: region.SameCountry},
+	}
+}
+
+// Load reads the rules from the file, the settings missing in the file are taken from Default
+func Load(path string) (Rules, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Rules{}, fmt.Errorf("failed to read rules file: %w", err)
+	}
+
+	r, err := Parse(data)
+	if err != nil {
+		return Rules{}, fmt.Errorf("rules file %s: %w", path, err)
+	}
+
+	return r, nil
+}
+
+// Parse parses and validates the rules in YAML or JSON. The unknown settings are rejected,
+// so a typo does not silently fall back to the default.
+func Parse(data []byte) (Rules, error) {
+	r := Default()
+
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&r); err != nil && !errors.Is(err, io.EOF) {
+		return Rules{}, err
+	}
+
+	if err := r.Validate(); err != nil {
+		return Rules{}, err
+	}
+
+	return r, nil
+}
+
+// Validate checks the rules and tells which setting is wrong
+func (r Rules) Validate() error {
+	if r.Attribute != lobby.AttributeLevel && r.Attribute != lobby.AttributeRating {
+		return fmt.Errorf("attribute: unknown attribute %q, expected %q or %q", r.Attribute, lobby.AttributeLevel, lobby.AttributeRating)
+	}
+
+	if r.BucketWidth <= 0 {
+		return fmt.Errorf("bucket_width: must be positive, got %v", r.BucketWidth)
+	}
+
+	if r.Constraints.Band < 0 {
+		return fmt.Errorf("constraints.band: must not be negative, got %d", r.Constraints.Band)
+	}
+
+	if r.Preferences.Order != lobby.OrderAscending && r.Preferences.Order != lobby.OrderClosest {
+		return fmt.Errorf("preferences.order: unknown order %q, expected %q or %q", r.Preferences.Order, lobby.OrderAscending, lobby.OrderClosest)
+	}
+
+	if err := lobby.ValidateWidening(r.Widening); err != nil {
+		return fmt.Errorf("widening: %w", err)
+	}
+
+	for i, step := range r.Widening {
+		if step.Band != lobby.AnyLevel && step.Band < r.Constraints.Band {
+			return fmt.Errorf("widening: step %d: band %d is narrower than constraints.band %d", i, step.Band, r.Constraints.Band)
+		}
+	}
+
+	return nil
+}
+
+// Strategy returns the matchmaking strategy of the rules
+func (r Rules) Strategy() lobby.MatchmakingStrategy {
+	return &lobby.BucketStrategy{
+		Attribute: r.Attribute,
+		Width:     r.BucketWidth,
+		Floor:     r.Floor,
+		Band:      r.Constraints.Band,
+		Order:     r.Preferences.Order,
+	}
+}
+
+// Apply sets the level widening and the region fallback of the lobby config from the rules
+func (r Rules) Apply(cfg *lobby.Config) {
+	cfg.LevelWidening = r.Widening
+	cfg.RegionFallback = r.Preferences.RegionFallback
+}
@@ -0,0 +1,97 @@
+package rules
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/TanyEm/match-maker/v2/internal/lobby"
+	"github.com/TanyEm/match-maker/v2/internal/region"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	yamlRules := `
+attribute: level
+bucket_width: 10
+constraints:
+  band: 1
+preferences:
+  order: closest
+  region_fallback: neighbour
+widening: ["10s:3", "30s:any"]
+`
+	r, err := Parse([]byte(yamlRules))
+	assert.NoError(t, err)
+	assert.Equal(t, lobby.AttributeLevel, r.Attribute)
+	assert.Equal(t, 10.0, r.BucketWidth)
+	assert.Equal(t, 1.0, *r.Floor, "Expected the floor of the default rules")
+	assert.Equal(t, 1, r.Constraints.Band)
+	assert.Equal(t, lobby.OrderClosest, r.Preferences.Order)
+	assert.Equal(t, region.Neighbour, r.Preferences.RegionFallback)
+	assert.Equal(t, []lobby.WideningStep{{After: 10 * time.Second, Band: 3}, {After: 30 * time.Second, Band: lobby.AnyLevel}}, r.Widening)
+
+	jsonRules := `{"attribute": "rating", "bucket_width": 100, "floor": null, "constraints": {"band": 2}}`
+	r, err = Parse([]byte(jsonRules))
+	assert.NoError(t, err)
+	assert.Equal(t, lobby.AttributeRating, r.Attribute)
+	assert.Nil(t, r.Floor)
+	assert.Equal(t, 2, r.Constraints.Band)
+	assert.Equal(t, lobby.OrderAscending, r.Preferences.Order)
+
+	r, err = Parse([]byte(""))
+	assert.NoError(t, err)
+	assert.Equal(t, Default(), r)
+}
+
+func TestParse_Errors(t *testing.T) {
+	tests := []struct {
+		name          string
+		rules         string
+		expectedError string
+	}{
+		{"unknown setting", "bucket_widht: 10", "yaml: unmarshal errors:\n  line 1: field bucket_widht not found in type rules.Rules"},
+		{"invalid yaml", "constraints: [", "yaml: line 1: did not find expected node content"},
+		{"unknown attribute", "attribute: age", `attribute: unknown attribute "age", expected "level" or "rating"`},
+		{"zero bucket width", "bucket_width: 0", "bucket_width: must be positive, got 0"},
+		{"negative band", "constraints: {band: -1}", "constraints.band: must not be negative, got -1"},
+		{"unknown order", "preferences: {order: random}", `preferences.order: unknown order "random", expected "ascending" or "closest"`},
+		{"unknown region", "preferences: {region_fallback: planet}", `unknown proximity "planet", expected one of country, neighbour, subregion, continent, global`},
+		{"invalid widening step", `widening: ["10s"]`, `widening step "10s" is not in the <duration>:<band> format`},
+		{"unordered widening", `widening: ["30s:3", "10s:5"]`, "widening: step 1: duration 10s must be greater than 30s of the previous step"},
+		{"widening narrower than the band", `{constraints: {band: 3}, widening: ["10s:2"]}`, "widening: step 0: band 2 is narrower than constraints.band 3"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse([]byte(tt.rules))
+			assert.EqualError(t, err, tt.expectedError)
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rules.yaml")
+	assert.NoError(t, os.WriteFile(path, []byte("bucket_width: 0"), 0o600))
+
+	_, err := Load(path)
+	assert.EqualError(t, err, "rules file "+path+": bucket_width: must be positive, got 0")
+
+	_, err = Load(filepath.Join(t.TempDir(), "missing.yaml"))
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestRules_Strategy(t *testing.T) {
+	// The default rules match the players as the default strategy
+	assert.Equal(t, lobby.NewDefaultStrategy(), Default().Strategy())
+
+	r := Default()
+	r.Widening = []lobby.WideningStep{{After: 10 * time.Second, Band: 3}}
+	r.Preferences.RegionFallback = region.SameContinent
+
+	cfg := lobby.Config{}
+	r.Apply(&cfg)
+	assert.Equal(t, r.Widening, cfg.LevelWidening)
+	assert.Equal(t, region.SameContinent, cfg.RegionFallback)
+}
@@ -26,7 +26,9 @@ func (m PlayerMatcher) Matches(x interface{}) bool {
 
 	return m.expected.PlayerID == actual.PlayerID &&
 		m.expected.Level == actual.Level &&
-		m.expected.Country == actual.Country
+		m.expected.Country == actual.Country &&
+		m.expected.Rating == actual.Rating &&
+		m.expected.Queue == actual.Queue
 }
 
 // String returns a string representation of the matcher